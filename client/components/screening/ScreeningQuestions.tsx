@@ -39,7 +39,7 @@ export function ScreeningQuestions({ questions, form, step }: ScreeningQuestions
                     {index + 1}. {q.label}
                   </FormLabel>
                   <FormControl>
-                    {q.type === "text" || q.type === "date" ? (
+                    {q.type === "text" || q.type === "number" || q.type === "date" ? (
                       <Input
                         type={q.type}
                         {...field}
@@ -1,7 +1,7 @@
 export interface Question {
   id: string
   label: string
-  type: "text" | "date" | "select" | "checkbox" | "checkbox_textarea"
+  type: "text" | "number" | "date" | "select" | "checkbox" | "checkbox_textarea"
   options?: string[]
 }
 
@@ -0,0 +1,30 @@
+// Command jwtkey membuat kunci penandatangan JWT baru untuk RS256/EdDSA.
+//
+//	go run ./cmd/jwtkey -dir keys/jwt -alg EdDSA
+//
+// Rotasi: buat kunci baru dan salin ke semua instance, lalu set
+// JWT_ACTIVE_KEY ke kid baru dan restart. Kunci lama tetap di folder agar token
+// yang sudah terbit tetap valid; hapus setelah lewat JWT_EXPIRE.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"v2/internal/jwtauth"
+)
+
+func main() {
+	dir := flag.String("dir", os.Getenv("JWT_KEYS_DIR"), "folder kunci (default: $JWT_KEYS_DIR)")
+	alg := flag.String("alg", jwtauth.EdDSA, "algoritma: RS256 atau EdDSA")
+	flag.Parse()
+
+	if *dir == "" {
+		log.Fatal("-dir or JWT_KEYS_DIR env required")
+	}
+	id, err := jwtauth.GenerateKey(*dir, *alg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("New %s key: %s", *alg, id)
+}
@@ -0,0 +1,72 @@
+// Command migrate menerapkan file di folder migrations yang belum tercatat di
+// tabel schema_migrations.
+//
+//	go run ./cmd/migrate              # terapkan migrasi yang tertunda
+//	go run ./cmd/migrate -status      # tampilkan migrasi yang tertunda saja
+//	go run ./cmd/migrate -baseline    # tandai semua migrasi sudah diterapkan
+//
+// -baseline hanya untuk database yang sebelumnya dimigrasi manual sampai file
+// terakhir; migrasi yang ditandai tidak dijalankan.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+	"v2/internal/config"
+	"v2/internal/migrate"
+	"v2/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	status := flag.Bool("status", false, "tampilkan migrasi yang tertunda lalu keluar")
+	baseline := flag.Bool("baseline", false, "catat semua migrasi sebagai sudah diterapkan tanpa menjalankannya")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML (default: $CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.LoadDatabase(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	db, err := pgxpool.New(ctx, cfg.Database.DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	m := migrate.New(db, migrations.FS)
+	switch {
+	case *status:
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(pending) == 0 {
+			log.Print("No pending migrations")
+		}
+		for _, v := range pending {
+			log.Printf("pending: %s", v)
+		}
+	case *baseline:
+		done, err := m.Baseline(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Marked %d migrations as applied", len(done))
+	default:
+		done, err := m.Up(ctx)
+		for _, v := range done {
+			log.Printf("applied: %s", v)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Applied %d migrations", len(done))
+	}
+}
@@ -0,0 +1,340 @@
+// Command reencrypt mengenkripsi data pribadi pasien yang masih plaintext atau
+// masih memakai kunci lama, sekaligus mengisi ulang blind index. Nama dan NIK
+// pada sertifikat serta secret TOTP akun ikut dienkripsi ulang. Server tidak
+// mau start selama masih ada baris yang belum diproses.
+//
+//	go run ./cmd/reencrypt -init            # buat file kunci baru
+//	go run ./cmd/reencrypt                  # enkripsi data lama setelah migrasi 015 dan 020
+//	go run ./cmd/reencrypt -rotate          # tambah kunci baru lalu enkripsi ulang
+//
+// DSN dan file kunci dibaca dari konfigurasi yang sama dengan server
+// (-config atau $CONFIG_FILE, ditimpa env); -keys menimpa storage.field_key_file.
+//
+// Aman dijalankan berulang; baris yang sudah sesuai kunci aktif dilewati.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"v2/internal/config"
+	"v2/internal/fieldcrypt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML (default: $CONFIG_FILE)")
+	keyPath := flag.String("keys", "", "path file kunci (default: storage.field_key_file dari konfigurasi)")
+	initKeys := flag.Bool("init", false, "buat file kunci baru lalu keluar")
+	rotate := flag.Bool("rotate", false, "tambah kunci baru sebagai kunci aktif sebelum enkripsi ulang")
+	batch := flag.Int("batch", 500, "jumlah baris per batch")
+	flag.Parse()
+
+	// Error validasi database baru fatal setelah -init, yang tidak butuh database
+	cfg, cfgErr := config.LoadDatabase(*configPath)
+	if cfg == nil {
+		log.Fatal(cfgErr)
+	}
+	if *keyPath == "" {
+		*keyPath = cfg.Storage.FieldKeyFile
+	}
+	if *keyPath == "" {
+		log.Fatal("-keys or storage.field_key_file (FIELD_KEY_FILE) required")
+	}
+	if *initKeys {
+		if err := fieldcrypt.GenerateKeyFile(*keyPath); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Key file created: %s", *keyPath)
+		return
+	}
+	if *rotate {
+		id, err := fieldcrypt.RotateKeyFile(*keyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("New active key: %s", id)
+	}
+	keys, err := fieldcrypt.LoadKeyFile(*keyPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := fieldcrypt.NewCipher(keys)
+
+	if cfgErr != nil {
+		log.Fatal(cfgErr)
+	}
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, cfg.Database.DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	failed := 0
+	n, f, err := reencryptPatients(ctx, db, c, *batch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed += f
+	log.Printf("patients: %d updated, %d failed", n, f)
+	for _, table := range []string{"screening_answers", "screening_queues"} {
+		n, f, err := reencryptPatientInfo(ctx, db, c, table, *batch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		failed += f
+		log.Printf("%s: %d updated, %d failed", table, n, f)
+	}
+	n, f, err = reencryptCertificates(ctx, db, c, *batch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed += f
+	log.Printf("certificates: %d updated, %d failed", n, f)
+	n, f, err = reencryptTOTP(ctx, db, c, *batch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed += f
+	log.Printf("users totp_secret: %d updated, %d failed", n, f)
+	if failed > 0 {
+		log.Fatalf("%d rows failed; fix them and run again", failed)
+	}
+	log.Printf("Done. Old keys can be removed from %s once every server uses key %s", *keyPath, keys.ActiveKeyID())
+}
+
+// reencryptPatients memproses pasien berurutan per id. Baris yang gagal
+// (misalnya NIK ganda pada data lama yang bentrok di idx_patients_nik_bidx)
+// dicatat dan dilewati.
+func reencryptPatients(ctx context.Context, db *pgxpool.Pool, c *fieldcrypt.Cipher, batch int) (updated, failed int, err error) {
+	last := uuid.Nil
+	for {
+		rows, err := db.Query(ctx, `SELECT id, COALESCE(nik, ''), COALESCE(phone, ''), COALESCE(email, ''), COALESCE(address, ''),
+			COALESCE(nik_bidx, ''), COALESCE(phone_bidx, ''), COALESCE(email_bidx, ''),
+			COALESCE(nik_last4_bidx, ''), COALESCE(phone_last4_bidx, '')
+			FROM patients WHERE id > $1 ORDER BY id LIMIT $2`, last, batch)
+		if err != nil {
+			return updated, failed, err
+		}
+		type patient struct {
+			id                         uuid.UUID
+			nik, phone, email, addr    string
+			nikIdx, phoneIdx, emailIdx string
+			nikSuffix, phoneSuffix     string
+		}
+		var list []patient
+		for rows.Next() {
+			var p patient
+			if err := rows.Scan(&p.id, &p.nik, &p.phone, &p.email, &p.addr, &p.nikIdx, &p.phoneIdx, &p.emailIdx, &p.nikSuffix, &p.phoneSuffix); err != nil {
+				rows.Close()
+				return updated, failed, err
+			}
+			list = append(list, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, failed, err
+		}
+		if len(list) == 0 {
+			return updated, failed, nil
+		}
+		for _, p := range list {
+			last = p.id
+			stale := c.NeedsRotation(p.nik) || c.NeedsRotation(p.phone) || c.NeedsRotation(p.email) || c.NeedsRotation(p.addr)
+			plain := []string{p.nik, p.phone, p.email, p.addr}
+			for i := range plain {
+				if plain[i], err = c.Decrypt(plain[i]); err != nil {
+					return updated, failed, err
+				}
+			}
+			nikIdx, phoneIdx, emailIdx := c.NIKIndex(plain[0]), c.PhoneIndex(plain[1]), c.EmailIndex(plain[2])
+			nikSuffix, phoneSuffix := c.NIKSuffixIndex(plain[0]), c.PhoneSuffixIndex(plain[1])
+			if !stale && nikIdx == p.nikIdx && phoneIdx == p.phoneIdx && emailIdx == p.emailIdx &&
+				nikSuffix == p.nikSuffix && phoneSuffix == p.phoneSuffix {
+				continue
+			}
+			sealed := make([]string, len(plain))
+			for i := range plain {
+				if sealed[i], err = c.Encrypt(plain[i]); err != nil {
+					return updated, failed, err
+				}
+			}
+			_, err := db.Exec(ctx, `UPDATE patients SET nik=NULLIF($1, ''), phone=NULLIF($2, ''), email=NULLIF($3, ''), address=NULLIF($4, ''),
+				nik_bidx=NULLIF($5, ''), phone_bidx=NULLIF($6, ''), email_bidx=NULLIF($7, ''),
+				nik_last4_bidx=NULLIF($8, ''), phone_last4_bidx=NULLIF($9, '') WHERE id=$10`,
+				sealed[0], sealed[1], sealed[2], sealed[3], nikIdx, phoneIdx, emailIdx, nikSuffix, phoneSuffix, p.id)
+			if err != nil {
+				log.Printf("patient %s: %v", p.id, err)
+				failed++
+				continue
+			}
+			updated++
+		}
+	}
+}
+
+// reencryptPatientInfo memproses kolom patient_info pada tabel screening.
+func reencryptPatientInfo(ctx context.Context, db *pgxpool.Pool, c *fieldcrypt.Cipher, table string, batch int) (updated, failed int, err error) {
+	last := uuid.Nil
+	for {
+		rows, err := db.Query(ctx, `SELECT id, patient_info, COALESCE(nik_bidx, '') FROM `+table+` WHERE id > $1 ORDER BY id LIMIT $2`, last, batch)
+		if err != nil {
+			return updated, failed, err
+		}
+		type item struct {
+			id     uuid.UUID
+			data   []byte
+			nikIdx string
+		}
+		var list []item
+		for rows.Next() {
+			var it item
+			if err := rows.Scan(&it.id, &it.data, &it.nikIdx); err != nil {
+				rows.Close()
+				return updated, failed, err
+			}
+			list = append(list, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, failed, err
+		}
+		if len(list) == 0 {
+			return updated, failed, nil
+		}
+		for _, it := range list {
+			last = it.id
+			var info map[string]interface{}
+			if err := c.DecryptJSON(it.data, &info); err != nil {
+				log.Printf("%s %s: %v", table, it.id, err)
+				failed++
+				continue
+			}
+			nik, _ := info["nik"].(string)
+			nikIdx := c.NIKIndex(nik)
+			if !c.NeedsRotationJSON(it.data) && nikIdx == it.nikIdx {
+				continue
+			}
+			data, err := c.EncryptJSON(info)
+			if err != nil {
+				return updated, failed, err
+			}
+			if _, err := db.Exec(ctx, `UPDATE `+table+` SET patient_info=$1, nik_bidx=$2 WHERE id=$3`, data, nikIdx, it.id); err != nil {
+				log.Printf("%s %s: %v", table, it.id, err)
+				failed++
+				continue
+			}
+			updated++
+		}
+	}
+}
+
+// reencryptCertificates memproses nama dan NIK pasien pada sertifikat.
+func reencryptCertificates(ctx context.Context, db *pgxpool.Pool, c *fieldcrypt.Cipher, batch int) (updated, failed int, err error) {
+	last := uuid.Nil
+	for {
+		rows, err := db.Query(ctx, `SELECT id, patient_name, COALESCE(patient_nik, '') FROM certificates WHERE id > $1 ORDER BY id LIMIT $2`, last, batch)
+		if err != nil {
+			return updated, failed, err
+		}
+		type item struct {
+			id        uuid.UUID
+			name, nik string
+		}
+		var list []item
+		for rows.Next() {
+			var it item
+			if err := rows.Scan(&it.id, &it.name, &it.nik); err != nil {
+				rows.Close()
+				return updated, failed, err
+			}
+			list = append(list, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, failed, err
+		}
+		if len(list) == 0 {
+			return updated, failed, nil
+		}
+		for _, it := range list {
+			last = it.id
+			if !c.NeedsRotation(it.name) && !c.NeedsRotation(it.nik) {
+				continue
+			}
+			sealed := []string{it.name, it.nik}
+			for i := range sealed {
+				plain, err := c.Decrypt(sealed[i])
+				if err != nil {
+					return updated, failed, err
+				}
+				if sealed[i], err = c.Encrypt(plain); err != nil {
+					return updated, failed, err
+				}
+			}
+			if _, err := db.Exec(ctx, `UPDATE certificates SET patient_name=$1, patient_nik=NULLIF($2, '') WHERE id=$3`, sealed[0], sealed[1], it.id); err != nil {
+				log.Printf("certificate %s: %v", it.id, err)
+				failed++
+				continue
+			}
+			updated++
+		}
+	}
+}
+
+// reencryptTOTP memproses secret TOTP di tabel users.
+func reencryptTOTP(ctx context.Context, db *pgxpool.Pool, c *fieldcrypt.Cipher, batch int) (updated, failed int, err error) {
+	last := uuid.Nil
+	for {
+		rows, err := db.Query(ctx, `SELECT id, totp_secret FROM users WHERE id > $1 AND totp_secret IS NOT NULL ORDER BY id LIMIT $2`, last, batch)
+		if err != nil {
+			return updated, failed, err
+		}
+		type item struct {
+			id     uuid.UUID
+			secret string
+		}
+		var list []item
+		for rows.Next() {
+			var it item
+			if err := rows.Scan(&it.id, &it.secret); err != nil {
+				rows.Close()
+				return updated, failed, err
+			}
+			list = append(list, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, failed, err
+		}
+		if len(list) == 0 {
+			return updated, failed, nil
+		}
+		for _, it := range list {
+			last = it.id
+			if !c.NeedsRotation(it.secret) {
+				continue
+			}
+			plain, err := c.Decrypt(it.secret)
+			if err != nil {
+				log.Printf("user %s: %v", it.id, err)
+				failed++
+				continue
+			}
+			sealed, err := c.Encrypt(plain)
+			if err != nil {
+				return updated, failed, err
+			}
+			if _, err := db.Exec(ctx, `UPDATE users SET totp_secret=$1 WHERE id=$2`, sealed, it.id); err != nil {
+				log.Printf("user %s: %v", it.id, err)
+				failed++
+				continue
+			}
+			updated++
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"v2/internal/config"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Daftar kode bawaan untuk kasus yang umum di klinik pendakian. Gunakan
+// -file untuk memuat daftar ICD-10 lengkap dengan format kolom yang sama
+// (code,name_en,name_id).
+//
+//go:embed icd10.csv
+var bundledCSV string
+
+func main() {
+	file := flag.String("file", "", "path CSV ICD-10 (default: data bawaan)")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML (default: $CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.LoadDatabase(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var src io.Reader = strings.NewReader(bundledCSV)
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		src = f
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	db, err := pgxpool.New(ctx, cfg.Database.DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	reader := csv.NewReader(src)
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Fatal(err)
+	}
+	count := 0
+	for i, rec := range records {
+		if i == 0 && strings.EqualFold(rec[0], "code") {
+			continue // header
+		}
+		if len(rec) < 3 {
+			log.Fatalf("baris %d: expected 3 columns, got %d", i+1, len(rec))
+		}
+		_, err := db.Exec(ctx, `INSERT INTO icd10_codes (code, name_en, name_id) VALUES ($1, $2, $3) ON CONFLICT (code) DO UPDATE SET name_en=EXCLUDED.name_en, name_id=EXCLUDED.name_id`,
+			strings.ToUpper(strings.TrimSpace(rec[0])), strings.TrimSpace(rec[1]), strings.TrimSpace(rec[2]))
+		if err != nil {
+			log.Fatal(err)
+		}
+		count++
+	}
+	log.Printf("Seeder icd10_codes selesai! (%d kode)", count)
+}
@@ -2,50 +2,103 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"log"
 	"os"
 	"time"
+	"v2/internal/config"
+	"v2/internal/domain/screening"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type DisplayCondition struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Operator   string    `json:"operator"`
+	Value      string    `json:"value,omitempty"`
+	Values     []string  `json:"values,omitempty"`
+}
+
+type RiskRule struct {
+	ID        uuid.UUID
+	Flag      string
+	Level     string
+	Condition DisplayCondition
+}
+
 type ScreeningQuestion struct {
-	ID      uuid.UUID
-	Label   string
-	Type    string
-	Options []string
+	ID         uuid.UUID
+	Label      string
+	Type       string
+	Options    []string
+	Required   bool
+	Conditions []DisplayCondition
 }
 
 func main() {
-	dsn := os.Getenv("POSTGRES_DSN")
-	if dsn == "" {
-		log.Fatal("POSTGRES_DSN env required")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML (default: $CONFIG_FILE)")
+	flag.Parse()
+
+	cfg, err := config.LoadDatabase(*configPath)
+	if err != nil {
+		log.Fatal(err)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	db, err := pgxpool.New(ctx, dsn)
+	db, err := pgxpool.New(ctx, cfg.Database.DSN)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer db.Close()
 
+	// ID tetap agar seeder aman dijalankan ulang (ON CONFLICT (id) DO NOTHING)
+	climbsID := uuid.MustParse("f8daaf0d-050b-4bff-bea5-b8aa0c8ea860")
+	historyID := uuid.MustParse("1a69c67e-8416-4934-9afd-8223b410c678")
+	fitnessID := uuid.MustParse("b0fa399c-cf17-46fc-b6f9-c443cf99ff3f")
+	medicationID := uuid.MustParse("0f258375-d1c2-4b07-a2c5-be62474d63e0")
+	allergyID := uuid.MustParse("f862c30a-4006-4e49-ba41-0a3f8e7e3945")
 	questions := []ScreeningQuestion{
-		{ID: uuid.New(), Label: "Tanggal Rencana Pendakian", Type: "date"},
-		{ID: uuid.New(), Label: "Jumlah Pendakian Sebelumnya (di atas 2.000 mdpl)", Type: "text"},
-		{ID: uuid.New(), Label: "Apakah Anda memiliki riwayat penyakit berikut ini?", Type: "checkbox", Options: []string{"Penyakit jantung", "Asma", "Hipertensi (tekanan darah tinggi)", "Hipotensi (tekanan darah rendah)", "Diabetes", "Masalah paru-paru lainnya", "Cedera sendi/lutut/pergelangan kaki", "Tidak ada dari yang disebutkan"}},
-		{ID: uuid.New(), Label: "Kapan terakhir kali Anda melakukan pemeriksaan kesehatan umum?", Type: "select", Options: []string{"Kurang dari 6 bulan yang lalu", "6 bulan - 1 tahun yang lalu", "Lebih dari 1 tahun yang lalu", "Belum pernah melakukan"}},
-		{ID: uuid.New(), Label: "Apakah Anda memiliki masalah dengan:", Type: "checkbox", Options: []string{"Pernapasan saat berolahraga berat", "Daya tahan tubuh saat melakukan aktivitas fisik", "Tidak ada masalah di atas"}},
-		{ID: uuid.New(), Label: "Apakah Anda sedang dalam pengobatan rutin atau menggunakan obat tertentu? Jika ya, sebutkan:", Type: "checkbox_textarea", Options: []string{"Ya", "Tidak"}},
-		{ID: uuid.New(), Label: "Bagaimana Anda menilai kondisi fisik Anda saat ini untuk pendakian (misal: kekuatan otot, keseimbangan, stamina)?", Type: "select", Options: []string{"Sangat baik", "Baik", "Cukup", "Buruk"}},
-		{ID: uuid.New(), Label: "Apakah Anda memiliki alergi (terhadap makanan, obat, atau lainnya)? jika Ya, sebutkan:", Type: "checkbox_textarea", Options: []string{"Ya", "Tidak"}},
+		{ID: uuid.MustParse("f962dd4b-321b-49f4-9239-724b37052a6a"), Label: "Tanggal Rencana Pendakian", Type: "date", Required: true},
+		{ID: climbsID, Label: "Jumlah Pendakian Sebelumnya (di atas 2.000 mdpl)", Type: screening.QuestionNumber, Required: true},
+		{ID: historyID, Label: "Apakah Anda memiliki riwayat penyakit berikut ini?", Type: "checkbox", Options: []string{"Penyakit jantung", "Asma", "Hipertensi (tekanan darah tinggi)", "Hipotensi (tekanan darah rendah)", "Diabetes", "Masalah paru-paru lainnya", "Cedera sendi/lutut/pergelangan kaki", "Tidak ada dari yang disebutkan"}, Required: true},
+		{ID: uuid.MustParse("f086cb67-c5c4-49e9-8af7-e6bffb1012a8"), Label: "Kapan terakhir kali Anda melakukan pemeriksaan kesehatan umum?", Type: "select", Options: []string{"Kurang dari 6 bulan yang lalu", "6 bulan - 1 tahun yang lalu", "Lebih dari 1 tahun yang lalu", "Belum pernah melakukan"}, Required: true},
+		{ID: uuid.MustParse("ecd19506-8527-4f5c-b9b7-1fea97a429d2"), Label: "Apakah Anda memiliki masalah dengan:", Type: "checkbox", Options: []string{"Pernapasan saat berolahraga berat", "Daya tahan tubuh saat melakukan aktivitas fisik", "Tidak ada masalah di atas"}, Required: true},
+		{ID: medicationID, Label: "Apakah Anda sedang dalam pengobatan rutin atau menggunakan obat tertentu?", Type: "checkbox", Options: []string{"Ya", "Tidak"}, Required: true},
+		{ID: uuid.MustParse("80ad17a2-c2a7-40a2-8a0a-9e1efa92d00b"), Label: "Sebutkan obat yang sedang Anda gunakan:", Type: "text", Required: true, Conditions: []DisplayCondition{{QuestionID: medicationID, Operator: "includes", Value: "Ya"}}},
+		{ID: fitnessID, Label: "Bagaimana Anda menilai kondisi fisik Anda saat ini untuk pendakian (misal: kekuatan otot, keseimbangan, stamina)?", Type: "select", Options: []string{"Sangat baik", "Baik", "Cukup", "Buruk"}, Required: true},
+		{ID: allergyID, Label: "Apakah Anda memiliki alergi (terhadap makanan, obat, atau lainnya)?", Type: "checkbox", Options: []string{"Ya", "Tidak"}, Required: true},
+		{ID: uuid.MustParse("88ce4928-2162-4593-b3fa-3a50023345b9"), Label: "Sebutkan alergi Anda:", Type: "text", Required: true, Conditions: []DisplayCondition{{QuestionID: allergyID, Operator: "includes", Value: "Ya"}}},
 	}
 
-	for _, q := range questions {
-		_, err := db.Exec(ctx, `INSERT INTO screening_questions (id, label, type, options) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO NOTHING`, q.ID, q.Label, q.Type, q.Options)
+	for i, q := range questions {
+		conditions := q.Conditions
+		if conditions == nil {
+			conditions = []DisplayCondition{}
+		}
+		conditionsData, _ := json.Marshal(conditions)
+		_, err := db.Exec(ctx, `INSERT INTO screening_questions (id, label, type, options, position, required, display_conditions) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO NOTHING`, q.ID, q.Label, q.Type, q.Options, i+1, q.Required, conditionsData)
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 	log.Println("Seeder screening_questions selesai!")
+
+	// Aturan skoring risiko bawaan
+	rules := []RiskRule{
+		{ID: uuid.MustParse("0115dab8-dda5-48cf-9762-0012c3770505"), Flag: "penyakit_jantung", Level: "high", Condition: DisplayCondition{QuestionID: historyID, Operator: "includes", Value: "Penyakit jantung"}},
+		{ID: uuid.MustParse("1149e7b4-61c5-40cd-baa7-8ede2541d57a"), Flag: "asma", Level: "medium", Condition: DisplayCondition{QuestionID: historyID, Operator: "includes", Value: "Asma"}},
+		{ID: uuid.MustParse("f4189b6d-b446-42d2-a4fa-b9d3ce902721"), Flag: "hipertensi", Level: "medium", Condition: DisplayCondition{QuestionID: historyID, Operator: "includes", Value: "Hipertensi (tekanan darah tinggi)"}},
+		{ID: uuid.MustParse("475c6fe2-41c2-481d-a4b5-7d7314a54883"), Flag: "pendaki_pemula", Level: "medium", Condition: DisplayCondition{QuestionID: climbsID, Operator: "lt", Value: "1"}},
+		{ID: uuid.MustParse("ce0d998c-73a4-4407-a10f-f588cfa32d6e"), Flag: "kebugaran_cukup", Level: "medium", Condition: DisplayCondition{QuestionID: fitnessID, Operator: "equals", Value: "Cukup"}},
+		{ID: uuid.MustParse("4fb1b4d8-f505-4e55-9347-d5e2aba6d66e"), Flag: "kebugaran_buruk", Level: "high", Condition: DisplayCondition{QuestionID: fitnessID, Operator: "equals", Value: "Buruk"}},
+	}
+	for _, rule := range rules {
+		_, err := db.Exec(ctx, `INSERT INTO screening_risk_rules (id, question_id, operator, value, "values", flag, level) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO NOTHING`, rule.ID, rule.Condition.QuestionID, rule.Condition.Operator, rule.Condition.Value, rule.Condition.Values, rule.Flag, rule.Level)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	log.Println("Seeder screening_risk_rules selesai!")
 }
@@ -1,16 +1,75 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 	"v2/internal/config"
 	"v2/internal/delivery/http"
+	auditHandlerPkg "v2/internal/delivery/http/audit"
+	authHandlerPkg "v2/internal/delivery/http/auth"
+	certificateHandlerPkg "v2/internal/delivery/http/certificate"
+	consultationHandlerPkg "v2/internal/delivery/http/consultation"
+	duplicateHandlerPkg "v2/internal/delivery/http/duplicate"
+	healthHandlerPkg "v2/internal/delivery/http/health"
+	icd10HandlerPkg "v2/internal/delivery/http/icd10"
+	medicalRecordHandlerPkg "v2/internal/delivery/http/medicalrecord"
+	medicineHandlerPkg "v2/internal/delivery/http/medicine"
+	physicalExamHandlerPkg "v2/internal/delivery/http/physicalexam"
+	portalHandlerPkg "v2/internal/delivery/http/portal"
+	privacyHandlerPkg "v2/internal/delivery/http/privacy"
+	patientHandlerPkg "v2/internal/delivery/http/roles"
 	screeningHandlerPkg "v2/internal/delivery/http/screening"
+	timelineHandlerPkg "v2/internal/delivery/http/timeline"
+	"v2/internal/fieldcrypt"
+	"v2/internal/health"
+	"v2/internal/jwtauth"
+	"v2/internal/logging"
+	"v2/internal/mailer"
+	"v2/internal/metrics"
+	"v2/internal/middleware"
+	"v2/internal/migrate"
+	"v2/internal/ratelimit"
 	"v2/internal/repository"
+	auditRepoPkg "v2/internal/repository/audit"
+	authRepoPkg "v2/internal/repository/auth"
+	certificateRepoPkg "v2/internal/repository/certificate"
+	consultationRepoPkg "v2/internal/repository/consultation"
+	duplicateRepoPkg "v2/internal/repository/duplicate"
+	icd10RepoPkg "v2/internal/repository/icd10"
+	medicalRecordRepoPkg "v2/internal/repository/medicalrecord"
+	medicineRepoPkg "v2/internal/repository/medicine"
+	physicalExamRepoPkg "v2/internal/repository/physicalexam"
+	privacyRepoPkg "v2/internal/repository/privacy"
 	patientRepoPkg "v2/internal/repository/roles"
 	"v2/internal/repository/screening"
+	timelineRepoPkg "v2/internal/repository/timeline"
+	"v2/internal/tracing"
 	"v2/internal/usecase"
+	auditUsecasePkg "v2/internal/usecase/audit"
+	authUsecasePkg "v2/internal/usecase/auth"
+	certificateUsecasePkg "v2/internal/usecase/certificate"
+	consultationUsecasePkg "v2/internal/usecase/consultation"
+	duplicateUsecasePkg "v2/internal/usecase/duplicate"
+	icd10UsecasePkg "v2/internal/usecase/icd10"
+	medicalRecordUsecasePkg "v2/internal/usecase/medicalrecord"
+	medicineUsecasePkg "v2/internal/usecase/medicine"
+	physicalExamUsecasePkg "v2/internal/usecase/physicalexam"
+	portalUsecasePkg "v2/internal/usecase/portal"
+	privacyUsecasePkg "v2/internal/usecase/privacy"
+	patientUsecasePkg "v2/internal/usecase/roles"
 	screeningUsecasePkg "v2/internal/usecase/screening"
+	timelineUsecasePkg "v2/internal/usecase/timeline"
 	"v2/internal/utils"
+	"v2/migrations"
 
 	_ "v2/docs" // ganti dengan module path Anda jika berbeda
 
@@ -19,56 +78,280 @@ import (
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
+// Pemakaian:
+//
+//	server [-config file.yaml]               # jalankan server
+//	server [-config file.yaml] config print  # tampilkan konfigurasi efektif (secret disamarkan)
 func main() {
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML (default: $CONFIG_FILE)")
+	flag.Parse()
+
 	// 1. Load Config
-	cfg := config.LoadConfig()
+	cfg, err := config.Load(*configPath)
+	if args := flag.Args(); len(args) > 0 {
+		if len(args) != 2 || args[0] != "config" || args[1] != "print" {
+			log.Fatalf("unknown command %q, expected \"config print\"", strings.Join(args, " "))
+		}
+		printConfig(cfg, err)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(os.Stdout, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Juga mengarahkan package log standar ke logger ini
+	slog.SetDefault(logger)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("failed to configure tracing", err)
+	}
 
 	// 2. Connect to PostgreSQL
-	pgPool, err := utils.NewPostgresDB()
+	pgPool, err := utils.NewPostgresDB(cfg.Database)
 	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		fatal("failed to connect to PostgreSQL", err)
 	}
 	defer pgPool.Close()
 
+	// Kunci enkripsi NIK, telepon, email dan alamat pasien
+	fieldKeys, err := fieldcrypt.LoadKeyFile(cfg.Storage.FieldKeyFile)
+	if err != nil {
+		fatal("failed to load field encryption keys", err)
+	}
+	fieldCipher := fieldcrypt.NewCipher(fieldKeys)
+	// Data plaintext atau tanpa blind index tidak akan ditemukan lewat pencarian;
+	// cmd/reencrypt harus dijalankan dulu setelah migrasi enkripsi.
+	if n, err := health.PendingEncryption(context.Background(), pgPool); err != nil {
+		slog.Warn("failed to check field encryption backfill", "err", err)
+	} else if n > 0 {
+		slog.Error("field encryption backfill incomplete, run cmd/reencrypt", "pending", n)
+		os.Exit(1)
+	}
+
+	// JWT login; server tidak boleh berjalan tanpa secret/kunci
+	tokens, err := jwtauth.New(jwtauth.Settings{
+		Algorithm:   cfg.JWT.Algorithm,
+		Secret:      cfg.JWT.Secret,
+		KeysDir:     cfg.JWT.KeysDir,
+		ActiveKeyID: cfg.JWT.ActiveKey,
+		Issuer:      cfg.JWT.Issuer,
+		TTL:         cfg.JWT.Expire,
+	})
+	if err != nil {
+		fatal("failed to configure JWT", err)
+	}
+
 	// 3. Dependency Injection
+	auditRepo := auditRepoPkg.NewAuditPostgresRepository(pgPool)
+	auditUsecase := auditUsecasePkg.NewAuditUsecase(auditRepo)
+	auditHandler := auditHandlerPkg.NewAuditHandler(auditUsecase)
 	userRepo := repository.NewUserPostgresRepository(pgPool)
-	patientRepo := patientRepoPkg.NewPatientPostgresRepository(pgPool)
-	userUsecase := usecase.NewUserUsecase(userRepo, patientRepo)
+	patientRepo := patientRepoPkg.NewPatientPostgresRepository(pgPool, fieldCipher)
+	doctorRepo := repository.NewDoctorPostgresRepository(pgPool)
+	paramedicRepo := repository.NewParamedicPostgresRepository(pgPool)
+	adminRepo := repository.NewAdminPostgresRepository(pgPool)
+	cashierRepo := repository.NewCashierPostgresRepository(pgPool)
+	nikPolicy := patientUsecasePkg.NIKReject
+	if cfg.Patient.NIKMismatchPolicy == string(patientUsecasePkg.NIKWarn) {
+		nikPolicy = patientUsecasePkg.NIKWarn
+	}
+	duplicateRepo := duplicateRepoPkg.NewDuplicatePostgresRepository(pgPool, fieldCipher)
+	userUsecase := usecase.NewUserUsecase(userRepo, patientRepo, doctorRepo, paramedicRepo, adminRepo, cashierRepo, duplicateRepo, nikPolicy)
 	userHandler := http.NewUserHandler(userUsecase, userRepo)
 
+	// Login: batas percobaan per IP dan per akun, penguncian dan login_events.
+	// Limiter Postgres dipakai bersama oleh semua instance.
+	authSettings := authUsecasePkg.DefaultSettings()
+	authSettings.MFAIssuer = cfg.Clinic.Name
+	authSettings.MFARequiredRoles = cfg.Auth.MFARequiredRoles
+	var ipLimiter, accountLimiter ratelimit.Limiter
+	if cfg.Auth.LoginLimiter == "memory" {
+		ipLimiter = ratelimit.NewMemoryLimiter(authSettings.IPRule)
+		accountLimiter = ratelimit.NewMemoryLimiter(authSettings.AccountRule)
+	} else {
+		ipLimiter = ratelimit.NewPostgresLimiter(pgPool, "login_ip", authSettings.IPRule)
+		accountLimiter = ratelimit.NewPostgresLimiter(pgPool, "login_account", authSettings.AccountRule)
+	}
+	loginEventRepo := authRepoPkg.NewLoginEventPostgresRepository(pgPool)
+	mfaRepo := authRepoPkg.NewMFAPostgresRepository(pgPool, fieldCipher)
+	authUsecase := authUsecasePkg.NewAuthUsecase(userRepo, loginEventRepo, mfaRepo, ipLimiter, accountLimiter, auditUsecase, tokens, authSettings)
+	authHandler := authHandlerPkg.NewAuthHandler(authUsecase)
+
 	// Screening
 	questionRepo := screening.NewQuestionPostgresRepository(pgPool)
-	answerRepo := screening.NewAnswerPostgresRepository(pgPool)
-	queueRepo := screening.NewQueuePostgresRepository(pgPool)
-	screeningUsecase := screeningUsecasePkg.NewScreeningUsecase(questionRepo, answerRepo, queueRepo, patientRepo, userRepo)
+	answerRepo := screening.NewAnswerPostgresRepository(pgPool, fieldCipher)
+	queueRepo := screening.NewQueuePostgresRepository(pgPool, fieldCipher)
+	riskRuleRepo := screening.NewRiskRulePostgresRepository(pgPool)
+	screeningUsecase := screeningUsecasePkg.NewScreeningUsecase(questionRepo, answerRepo, queueRepo, riskRuleRepo, patientRepo, userRepo, auditUsecase, mailer.New(cfg.Email))
 	screeningHandler := screeningHandlerPkg.NewScreeningHandler(screeningUsecase)
 
-	// TODO: Ganti semua repository dan usecase lain ke versi Postgres jika sudah ada
-	// Sementara, screening, medical record, dsb masih pakai Mongo jika belum dimigrasi
+	// Patient
+	patientUsecase := patientUsecasePkg.NewPatientUsecase(patientRepo, duplicateRepo, nikPolicy, auditUsecase)
+	patientHandler := patientHandlerPkg.NewPatientHandler(patientUsecase, cfg.Storage.UploadDir)
+	duplicateUsecase := duplicateUsecasePkg.NewDuplicateUsecase(duplicateRepo, patientRepo, auditUsecase)
+	duplicateHandler := duplicateHandlerPkg.NewDuplicateHandler(duplicateUsecase)
+
+	// Medical Record
+	medicalRecordRepo := medicalRecordRepoPkg.NewMedicalRecordPostgresRepository(pgPool)
+	counterRepo := medicalRecordRepoPkg.NewCounterPostgresRepository(pgPool)
+	medicalRecordUsecase := medicalRecordUsecasePkg.NewMedicalRecordUsecase(medicalRecordRepo, counterRepo, auditUsecase)
+	medicalRecordHandler := medicalRecordHandlerPkg.NewMedicalRecordHandler(medicalRecordUsecase)
+
+	// Physical Examination
+	physicalExamRepo := physicalExamRepoPkg.NewPhysicalExaminationPostgresRepository(pgPool)
+	consultationRepo := consultationRepoPkg.NewConsultationPostgresRepository(pgPool)
+	vitalRanges := physicalExamUsecasePkg.DefaultVitalRanges()
+	if cfg.Patient.VitalRangesFile != "" {
+		if vitalRanges, err = physicalExamUsecasePkg.LoadVitalRanges(cfg.Patient.VitalRangesFile); err != nil {
+			fatal("failed to load vital ranges", err)
+		}
+	}
+	physicalExamUsecase := physicalExamUsecasePkg.NewPhysicalExaminationUsecase(physicalExamRepo, consultationRepo, vitalRanges, auditUsecase)
+	physicalExamHandler := physicalExamHandlerPkg.NewPhysicalExaminationHandler(physicalExamUsecase)
+
+	// ICD-10
+	icd10Repo := icd10RepoPkg.NewICD10PostgresRepository(pgPool)
+	icd10Usecase := icd10UsecasePkg.NewICD10Usecase(icd10Repo)
+	icd10Handler := icd10HandlerPkg.NewICD10Handler(icd10Usecase)
+
+	// Consultation
+	consultationUsecase := consultationUsecasePkg.NewConsultationUsecase(consultationRepo, physicalExamRepo, doctorRepo, icd10Repo, auditUsecase)
+	consultationHandler := consultationHandlerPkg.NewConsultationHandler(consultationUsecase)
+
+	// Certificate
+	certificateRepo := certificateRepoPkg.NewCertificatePostgresRepository(pgPool, fieldCipher)
+	certificateUsecase := certificateUsecasePkg.NewCertificateUsecase(certificateRepo, physicalExamRepo, consultationRepo, patientRepo, counterRepo, doctorRepo, paramedicRepo, medicalRecordUsecase, auditUsecase, certificateUsecasePkg.Settings{
+		ClinicName:    cfg.Clinic.Name,
+		VerifyBaseURL: cfg.Clinic.PublicBaseURL + "/verify",
+		Validity:      cfg.Certificate.Validity,
+		SigningKey:    []byte(cfg.Certificate.SigningKey),
+	})
+	certificateHandler := certificateHandlerPkg.NewCertificateHandler(certificateUsecase)
+
+	// Timeline pasien
+	timelineRepo := timelineRepoPkg.NewTimelinePostgresRepository(pgPool)
+	timelineUsecase := timelineUsecasePkg.NewTimelineUsecase(timelineRepo, patientRepo)
+	timelineHandler := timelineHandlerPkg.NewTimelineHandler(timelineUsecase)
+
+	// Obat
+	medicineRepo := medicineRepoPkg.NewMedicinePostgresRepository(pgPool)
+	medicineUsecase := medicineUsecasePkg.NewMedicineUsecase(medicineRepo)
+	medicineHandler := medicineHandlerPkg.NewMedicineHandler(medicineUsecase)
+
+	// Portal pasien
+	portalUsecase := portalUsecasePkg.NewPortalUsecase(patientRepo, answerRepo, queueRepo, physicalExamRepo, consultationRepo, certificateRepo, timelineUsecase, auditUsecase)
+	portalHandler := portalHandlerPkg.NewPortalHandler(portalUsecase)
+
+	// Ekspor & penghapusan data pasien (UU PDP)
+	privacyRepo := privacyRepoPkg.NewPrivacyPostgresRepository(pgPool, fieldCipher)
+	privacyUsecase := privacyUsecasePkg.NewPrivacyUsecase(privacyRepo, patientRepo, medicalRecordRepo, physicalExamRepo, consultationRepo, answerRepo, certificateRepo, certificateUsecase, duplicateRepo, auditUsecase, cfg.Storage.ExportDir)
+	if err := privacyUsecase.RecoverInterrupted(context.Background()); err != nil {
+		slog.Error("failed to recover privacy requests", "err", err)
+	}
+	privacyHandler := privacyHandlerPkg.NewPrivacyHandler(privacyUsecase)
+
+	// Readiness: database, migrasi dan folder penyimpanan
+	healthChecker := health.NewChecker(3*time.Second,
+		health.Database(pgPool),
+		health.Migrations(migrate.New(pgPool, migrations.FS)),
+		health.FieldEncryption(pgPool),
+		health.Directory("upload_dir", cfg.Storage.UploadDir),
+		health.Directory("export_dir", cfg.Storage.ExportDir),
+	)
+	healthHandler := healthHandlerPkg.NewHealthHandler(healthChecker)
 
 	// 4. Setup Fiber & Register Routes
-	app := fiber.New()
-	app.Use(cors.New())
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		BodyLimit:    cfg.Server.BodyLimitMB * 1024 * 1024,
+		// IP client (rate limit login, login event, audit) diambil dari
+		// ProxyHeader hanya jika koneksi berasal dari proxy tepercaya
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+		// Banner tidak berformat log; info port dicatat lewat slog
+		DisableStartupMessage: true,
+	})
+	// Tracing dipasang sebelum AccessLog agar trace_id ikut tercatat
+	app.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog())
+	if cfg.Metrics.Enabled {
+		metrics.Registry.MustRegister(metrics.NewPoolCollector(pgPool), metrics.NewClinicCollector(queueRepo, medicineRepo))
+		app.Use(middleware.Metrics())
+		http.RegisterMetricsRoutes(app, cfg.Metrics.Token)
+	}
+	app.Use(cors.New(cors.Config{
+		AllowOrigins: strings.Join(cfg.Server.CORSOrigins, ","),
+	}))
 
 	// Health check endpoint
 	app.Get("/ping", func(c *fiber.Ctx) error {
-		log.Println("Ping endpoint hit")
 		return c.SendString("pong")
 	})
 
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
+	http.RegisterPublicRoutes(app, certificateHandler, tokens, healthHandler)
 
-	api := app.Group("/api/v1")
-	http.RegisterRoutes(api, userHandler, screeningHandler, nil, nil, nil, nil) // TODO: inject handler lain jika sudah migrasi
+	api := app.Group("/api/v1", middleware.AuditRequest())
+	http.RegisterRoutes(api, userHandler, authHandler, screeningHandler, medicalRecordHandler, patientHandler, physicalExamHandler, medicineHandler, certificateHandler, consultationHandler, icd10Handler, timelineHandler, portalHandler, duplicateHandler, privacyHandler, auditHandler, auditUsecase, tokens)
 
 	// 5. Start Server
-	port := cfg.Port
-	if port == "" {
-		port = "8080"
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server running", "port", cfg.Server.Port)
+		serverErr <- app.Listen(":" + cfg.Server.Port)
+	}()
+	select {
+	case err := <-serverErr:
+		fatal("server stopped", err)
+	case <-ctx.Done():
+	}
+	// Sinyal kedua langsung menghentikan proses
+	stop()
+
+	// 6. Graceful shutdown: tolak koneksi baru, tunggu request dan job ekspor
+	// yang sedang berjalan, baru tutup pool database (lewat defer)
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", cfg.Server.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		slog.Error("server shutdown", "err", err)
 	}
+	if err := privacyUsecase.Wait(shutdownCtx); err != nil {
+		slog.Warn("privacy export still running at shutdown", "err", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("tracing shutdown", "err", err)
+	}
+	slog.Info("server stopped")
+}
 
-	log.Printf("Server running on port %s", port)
-	if err := app.Listen(":" + port); err != nil {
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// printConfig menulis konfigurasi efektif ke stdout. Hasil validasi ditulis
+// ke stderr agar output tetap bisa dipakai sebagai file config.
+func printConfig(cfg *config.Config, loadErr error) {
+	var invalid *config.ValidationError
+	if loadErr != nil && !errors.As(loadErr, &invalid) {
+		log.Fatal(loadErr)
+	}
+	out, err := cfg.YAML()
+	if err != nil {
 		log.Fatal(err)
 	}
+	os.Stdout.Write(out)
+	if invalid != nil {
+		fmt.Fprintln(os.Stderr, invalid)
+		os.Exit(1)
+	}
 }
@@ -1,19 +1,181 @@
+// Package config memuat konfigurasi server dari file YAML (opsional) lalu
+// menimpanya dengan environment variable. Setiap field punya tag env dengan
+// nama yang sama seperti sebelum file config ada, sehingga deployment lama
+// yang hanya memakai env tetap berjalan.
 package config
 
 import (
-	"os"
+	"time"
 )
 
 type Config struct {
-	Port      string
-	JWTSecret string
-	JWTExpire string // durasi, misal "1h"
+	Server      ServerConfig      `yaml:"server"`
+	Log         LogConfig         `yaml:"log"`
+	Database    DatabaseConfig    `yaml:"database"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Storage     StorageConfig     `yaml:"storage"`
+	Email       EmailConfig       `yaml:"email"`
+	Clinic      ClinicConfig      `yaml:"clinic"`
+	Certificate CertificateConfig `yaml:"certificate"`
+	Patient     PatientConfig     `yaml:"patient"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
 }
 
-func LoadConfig() *Config {
+type ServerConfig struct {
+	Port         string        `yaml:"port" env:"PORT"`
+	CORSOrigins  []string      `yaml:"cors_origins" env:"CORS_ORIGINS"` // "*" untuk semua origin
+	ReadTimeout  time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	BodyLimitMB  int           `yaml:"body_limit_mb" env:"SERVER_BODY_LIMIT_MB"`
+	// Batas waktu menunggu request yang sedang berjalan saat SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// Header berisi IP client dari reverse proxy, mis. X-Real-IP. Hanya
+	// dipercaya jika request datang dari TrustedProxies (IP atau CIDR); kosong
+	// berarti server diakses langsung dan IP koneksi yang dipakai.
+	ProxyHeader    string   `yaml:"proxy_header" env:"PROXY_HEADER"`
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn atau error
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json atau text
+}
+
+type DatabaseConfig struct {
+	DSN             string        `yaml:"dsn" env:"POSTGRES_DSN" secret:"dsn"`
+	MaxConns        int32         `yaml:"max_conns" env:"DB_MAX_CONNS"`
+	MinConns        int32         `yaml:"min_conns" env:"DB_MIN_CONNS"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME"`
+	// statement_timeout Postgres untuk setiap koneksi; 0 berarti tanpa batas
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"DB_STATEMENT_TIMEOUT"`
+	// Query lebih lama dari ini dicatat sebagai warning; 0 untuk mematikan
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
+}
+
+type JWTConfig struct {
+	Algorithm string        `yaml:"algorithm" env:"JWT_ALGORITHM"` // HS256, RS256 atau EdDSA
+	Secret    string        `yaml:"secret" env:"JWT_SECRET" secret:"true"`
+	Expire    time.Duration `yaml:"expire" env:"JWT_EXPIRE"`
+	KeysDir   string        `yaml:"keys_dir" env:"JWT_KEYS_DIR"`
+	ActiveKey string        `yaml:"active_key" env:"JWT_ACTIVE_KEY"`
+	Issuer    string        `yaml:"issuer" env:"JWT_ISSUER"`
+}
+
+type AuthConfig struct {
+	LoginLimiter string `yaml:"login_limiter" env:"LOGIN_LIMITER"` // postgres atau memory
+	// Role wajib 2FA; "none" di env untuk mengosongkan
+	MFARequiredRoles []string `yaml:"mfa_required_roles" env:"MFA_REQUIRED_ROLES"`
+}
+
+type StorageConfig struct {
+	UploadDir    string `yaml:"upload_dir" env:"UPLOAD_DIR"` // foto KTP
+	ExportDir    string `yaml:"export_dir" env:"EXPORT_DIR"` // ZIP ekspor data pasien
+	FieldKeyFile string `yaml:"field_key_file" env:"FIELD_KEY_FILE"`
+}
+
+// EmailConfig kosong (tanpa smtp_host) berarti email tidak dikirim dan hanya
+// dicatat di log tanpa isi pesan.
+type EmailConfig struct {
+	SMTPHost string `yaml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort int    `yaml:"smtp_port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"EMAIL_FROM"`
+}
+
+type ClinicConfig struct {
+	Name          string `yaml:"name" env:"CLINIC_NAME"`
+	PublicBaseURL string `yaml:"public_base_url" env:"PUBLIC_BASE_URL"` // dipakai untuk link verifikasi sertifikat
+}
+
+type CertificateConfig struct {
+	Validity   time.Duration `yaml:"validity" env:"CERTIFICATE_VALIDITY"`
+	SigningKey string        `yaml:"signing_key" env:"CERTIFICATE_SIGNING_KEY" secret:"true"` // kunci HMAC token verifikasi
+}
+
+type PatientConfig struct {
+	NIKMismatchPolicy string `yaml:"nik_mismatch_policy" env:"NIK_MISMATCH_POLICY"` // reject atau warn
+	VitalRangesFile   string `yaml:"vital_ranges_file" env:"VITAL_RANGES_FILE"`     // rentang klinis tanda vital (opsional)
+}
+
+// MetricsConfig mengatur endpoint /metrics. Token kosong berarti /metrics
+// terbuka; batasi aksesnya di reverse proxy.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Token   string `yaml:"token" env:"METRICS_TOKEN" secret:"true"` // Bearer token untuk Prometheus
+}
+
+// TracingConfig mengatur OpenTelemetry. Endpoint berupa URL dasar collector;
+// path /v1/traces ditambahkan otomatis seperti OTEL_EXPORTER_OTLP_ENDPOINT.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`            // none, stdout atau otlp
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // URL OTLP/HTTP, mis. http://localhost:4318
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"` // 0..1, trace dari upstream mengikuti keputusan induknya
+}
+
+// Default adalah nilai yang dipakai jika tidak diisi di file maupun env.
+func Default() *Config {
 	return &Config{
-		Port:      os.Getenv("PORT"),
-		JWTSecret: os.Getenv("JWT_SECRET"),
-		JWTExpire: os.Getenv("JWT_EXPIRE"),
+		Server: ServerConfig{
+			Port:            "8080",
+			CORSOrigins:     []string{"*"},
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			BodyLimitMB:     10,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Database: DatabaseConfig{
+			MaxConns:           10,
+			MinConns:           0,
+			ConnectTimeout:     5 * time.Second,
+			MaxConnLifetime:    time.Hour,
+			MaxConnIdleTime:    30 * time.Minute,
+			StatementTimeout:   30 * time.Second,
+			SlowQueryThreshold: 500 * time.Millisecond,
+		},
+		JWT: JWTConfig{
+			Algorithm: "HS256",
+			Expire:    time.Hour,
+		},
+		Auth: AuthConfig{
+			LoginLimiter:     "postgres",
+			MFARequiredRoles: []string{"admin", "dokter"},
+		},
+		Storage: StorageConfig{
+			UploadDir: "public/uploads",
+			ExportDir: "exports",
+		},
+		Email: EmailConfig{
+			SMTPPort: 587,
+		},
+		Clinic: ClinicConfig{
+			Name: "Klinik",
+		},
+		Certificate: CertificateConfig{
+			Validity: 7 * 24 * time.Hour,
+		},
+		Patient: PatientConfig{
+			NIKMismatchPolicy: "reject",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "http://localhost:4318",
+			ServiceName: "klinik-api",
+			SampleRatio: 1,
+		},
 	}
 }
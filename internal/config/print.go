@@ -0,0 +1,58 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Sama dengan penanda yang dipakai url.URL.Redacted.
+const redacted = "xxxxx"
+
+// Redacted mengembalikan salinan konfigurasi dengan field bertag secret
+// disamarkan. secret:"dsn" hanya menyamarkan password di dalam DSN.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	redact(reflect.ValueOf(&cp).Elem())
+	return &cp
+}
+
+func redact(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			redact(value)
+			continue
+		}
+		if value.Kind() != reflect.String || value.String() == "" {
+			continue
+		}
+		switch field.Tag.Get("secret") {
+		case "true":
+			value.SetString(redacted)
+		case "dsn":
+			value.SetString(redactDSN(value.String()))
+		}
+	}
+}
+
+// redactDSN menyamarkan password pada DSN bentuk URL. DSN bentuk key=value
+// disamarkan seluruhnya karena password bisa ada di mana saja.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", redacted)
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
+
+// YAML menulis konfigurasi yang sudah disamarkan, untuk perintah config print.
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c.Redacted())
+}
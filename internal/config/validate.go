@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidationError memuat semua masalah konfigurasi agar bisa diperbaiki
+// sekaligus, bukan satu per satu setiap kali server gagal start.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+// check mencatat masalah jika ok false. key adalah path YAML, env nama
+// environment variable-nya.
+func (v *validator) check(ok bool, key, env, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf("%s (%s): %s", key, env, fmt.Sprintf(format, args...)))
+	}
+}
+
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	v.check(err == nil && port > 0 && port < 65536, "server.port", "PORT", "must be a port number, got %q", c.Server.Port)
+	v.check(len(c.Server.CORSOrigins) > 0, "server.cors_origins", "CORS_ORIGINS", "at least one origin or \"*\" is required")
+	for _, o := range c.Server.CORSOrigins {
+		u, err := url.Parse(o)
+		v.check(o == "*" || (err == nil && u.Scheme != "" && u.Host != ""), "server.cors_origins", "CORS_ORIGINS", "%q must be \"*\" or an origin such as https://klinik.example", o)
+	}
+	v.check(c.Server.ReadTimeout > 0, "server.read_timeout", "SERVER_READ_TIMEOUT", "must be positive")
+	v.check(c.Server.WriteTimeout > 0, "server.write_timeout", "SERVER_WRITE_TIMEOUT", "must be positive")
+	v.check(c.Server.IdleTimeout > 0, "server.idle_timeout", "SERVER_IDLE_TIMEOUT", "must be positive")
+	v.check(c.Server.BodyLimitMB > 0, "server.body_limit_mb", "SERVER_BODY_LIMIT_MB", "must be positive")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", "must be positive")
+	v.check(c.Server.ProxyHeader == "" || len(c.Server.TrustedProxies) > 0, "server.trusted_proxies", "TRUSTED_PROXIES", "is required when server.proxy_header is set")
+	for _, p := range c.Server.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(p)
+		v.check(net.ParseIP(p) != nil || cidrErr == nil, "server.trusted_proxies", "TRUSTED_PROXIES", "%q must be an IP address or CIDR", p)
+	}
+
+	v.check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "log.level", "LOG_LEVEL", "must be debug, info, warn or error, got %q", c.Log.Level)
+	v.check(c.Log.Format == "json" || c.Log.Format == "text", "log.format", "LOG_FORMAT", "must be json or text, got %q", c.Log.Format)
+
+	c.validateDatabase(v)
+
+	switch c.JWT.Algorithm {
+	case "HS256":
+		v.check(c.JWT.Secret != "", "jwt.secret", "JWT_SECRET", "is required for HS256")
+	case "RS256", "EdDSA":
+		v.check(c.JWT.KeysDir != "", "jwt.keys_dir", "JWT_KEYS_DIR", "is required for %s", c.JWT.Algorithm)
+	default:
+		v.check(false, "jwt.algorithm", "JWT_ALGORITHM", "must be HS256, RS256 or EdDSA, got %q", c.JWT.Algorithm)
+	}
+	v.check(c.JWT.Expire > 0, "jwt.expire", "JWT_EXPIRE", "must be positive")
+
+	v.check(slices.Contains([]string{"postgres", "memory"}, c.Auth.LoginLimiter), "auth.login_limiter", "LOGIN_LIMITER", "must be postgres or memory, got %q", c.Auth.LoginLimiter)
+
+	v.check(c.Storage.FieldKeyFile != "", "storage.field_key_file", "FIELD_KEY_FILE", "is required")
+	v.check(c.Storage.UploadDir != "", "storage.upload_dir", "UPLOAD_DIR", "is required")
+	v.check(c.Storage.ExportDir != "", "storage.export_dir", "EXPORT_DIR", "is required")
+
+	if c.Email.SMTPHost != "" {
+		v.check(c.Email.SMTPPort > 0 && c.Email.SMTPPort < 65536, "email.smtp_port", "SMTP_PORT", "must be a port number")
+		v.check(c.Email.From != "", "email.from", "EMAIL_FROM", "is required when email.smtp_host is set")
+	}
+
+	v.check(c.Clinic.Name != "", "clinic.name", "CLINIC_NAME", "is required")
+	if c.Clinic.PublicBaseURL != "" {
+		u, err := url.Parse(c.Clinic.PublicBaseURL)
+		v.check(err == nil && u.Scheme != "" && u.Host != "", "clinic.public_base_url", "PUBLIC_BASE_URL", "must be an absolute URL")
+	}
+
+	v.check(c.Certificate.SigningKey != "", "certificate.signing_key", "CERTIFICATE_SIGNING_KEY", "is required")
+	v.check(c.Certificate.Validity > 0, "certificate.validity", "CERTIFICATE_VALIDITY", "must be positive")
+
+	v.check(c.Patient.NIKMismatchPolicy == "reject" || c.Patient.NIKMismatchPolicy == "warn", "patient.nik_mismatch_policy", "NIK_MISMATCH_POLICY", "must be reject or warn, got %q", c.Patient.NIKMismatchPolicy)
+
+	v.check(slices.Contains([]string{"none", "stdout", "otlp"}, c.Tracing.Exporter), "tracing.exporter", "TRACING_EXPORTER", "must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	if c.Tracing.Exporter == "otlp" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "tracing.endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT", "must be an http(s) URL, got %q", c.Tracing.Endpoint)
+	}
+	v.check(c.Tracing.ServiceName != "", "tracing.service_name", "OTEL_SERVICE_NAME", "is required")
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "TRACING_SAMPLE_RATIO", "must be between 0 and 1")
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (c *Config) validateDatabase(v *validator) {
+	v.check(c.Database.DSN != "", "database.dsn", "POSTGRES_DSN", "is required")
+	v.check(c.Database.MaxConns > 0, "database.max_conns", "DB_MAX_CONNS", "must be positive")
+	v.check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns, "database.min_conns", "DB_MIN_CONNS", "must be between 0 and database.max_conns")
+	v.check(c.Database.ConnectTimeout > 0, "database.connect_timeout", "DB_CONNECT_TIMEOUT", "must be positive")
+	v.check(c.Database.MaxConnLifetime > 0, "database.max_conn_lifetime", "DB_MAX_CONN_LIFETIME", "must be positive")
+	v.check(c.Database.MaxConnIdleTime > 0, "database.max_conn_idle_time", "DB_MAX_CONN_IDLE_TIME", "must be positive")
+	v.check(c.Database.StatementTimeout >= 0, "database.statement_timeout", "DB_STATEMENT_TIMEOUT", "must not be negative")
+	v.check(c.Database.SlowQueryThreshold >= 0, "database.slow_query_threshold", "DB_SLOW_QUERY_THRESHOLD", "must not be negative")
+}
@@ -0,0 +1,43 @@
+// Package dbcaller mencari method repository yang menjalankan query dari
+// stack, dipakai tracer pgx untuk metrik dan tracing tanpa mengubah setiap
+// repository.
+package dbcaller
+
+import (
+	"runtime"
+	"strings"
+)
+
+const repositoryPackage = "v2/internal/repository"
+
+// Repository mengembalikan nama repository dan method pemanggil pertama di
+// package repository. Query dari luar repository (migrasi, health check,
+// limiter) diberi nilai "other".
+func Repository() (repository, method string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, repositoryPackage+"/") || strings.HasPrefix(frame.Function, repositoryPackage+".") {
+			return split(frame.Function)
+		}
+		if !more {
+			return "other", "other"
+		}
+	}
+}
+
+// split mengubah "v2/internal/repository/roles.(*PatientPostgresRepository).FindByID.func1"
+// menjadi ("PatientPostgresRepository", "FindByID"). Fungsi tanpa receiver
+// memakai nama package sebagai repository.
+func split(fn string) (string, string) {
+	fn = fn[strings.LastIndex(fn, "/")+1:]
+	pkg, rest, _ := strings.Cut(fn, ".")
+	parts := strings.Split(rest, ".")
+	// Receiver pointer tertulis "(*T)", receiver value "T"; closure "funcN"
+	if len(parts) > 1 && (strings.HasPrefix(parts[0], "(") || !strings.HasPrefix(parts[1], "func")) {
+		return strings.Trim(parts[0], "(*)"), parts[1]
+	}
+	return pkg, parts[0]
+}
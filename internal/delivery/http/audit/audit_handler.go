@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"math"
+	"strconv"
+	"time"
+	"v2/internal/domain/audit"
+	usecase "v2/internal/usecase/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuditHandler struct {
+	Usecase usecase.AuditUsecase
+}
+
+func NewAuditHandler(u usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{Usecase: u}
+}
+
+// List menerima filter actor_id, role, action, entity, entity_id, from dan to
+// (YYYY-MM-DD; tanggal to ikut dihitung). Hasil diurutkan dari yang terbaru.
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	filter := audit.Filter{
+		ActorID:  c.Query("actor_id"),
+		Role:     c.Query("role"),
+		Action:   c.Query("action"),
+		Entity:   c.Query("entity"),
+		EntityID: c.Query("entity_id"),
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.Query("limit", "50"))
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 500 {
+		filter.Limit = 50
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from must be YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "to must be YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	entries, total, err := h.Usecase.FindPaginated(c.Context(), filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
+	return c.JSON(fiber.Map{
+		"data": entries,
+		"meta": fiber.Map{
+			"page":        filter.Page,
+			"limit":       filter.Limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
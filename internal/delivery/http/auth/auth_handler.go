@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+	"v2/internal/domain/auth"
+	usecase "v2/internal/usecase/auth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler struct {
+	Usecase usecase.AuthUsecase
+}
+
+func NewAuthHandler(u usecase.AuthUsecase) *AuthHandler {
+	return &AuthHandler{Usecase: u}
+}
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Login membalas 429 jika IP atau akun melewati batas percobaan dan 423 jika
+// akun sedang dikunci; keduanya menyertakan header Retry-After. Akun dengan
+// 2FA menerima mfa_token untuk POST /login/mfa, bukan JWT.
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.Login(c.Context(), req.Email, req.Password)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// Unlock membuka kunci akun :id.
+func (h *AuthHandler) Unlock(c *fiber.Ctx) error {
+	if err := h.Usecase.Unlock(c.Context(), c.Params("id")); err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "account unlocked"})
+}
+
+// ListEvents menerima filter user_id, email, ip, success, reason, from dan to
+// (YYYY-MM-DD; tanggal to ikut dihitung).
+func (h *AuthHandler) ListEvents(c *fiber.Ctx) error {
+	filter := auth.EventFilter{
+		UserID:  c.Query("user_id"),
+		Email:   c.Query("email"),
+		IP:      c.Query("ip"),
+		Success: c.Query("success"),
+		Reason:  c.Query("reason"),
+	}
+	if filter.Success != "" && filter.Success != "true" && filter.Success != "false" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "success must be true or false"})
+	}
+	filter.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	filter.Limit, _ = strconv.Atoi(c.Query("limit", "50"))
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 500 {
+		filter.Limit = 50
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from must be YYYY-MM-DD"})
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "to must be YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	events, total, err := h.Usecase.FindEvents(c.Context(), filter)
+	if err != nil {
+		return authError(c, err)
+	}
+	if events == nil {
+		events = []auth.LoginEvent{}
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
+	return c.JSON(fiber.Map{
+		"data": events,
+		"meta": fiber.Map{
+			"page":        filter.Page,
+			"limit":       filter.Limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func authError(c *fiber.Ctx, err error) error {
+	var retry *usecase.RetryError
+	if errors.As(err, &retry) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retry.RetryAfter.Seconds()))))
+	}
+	switch {
+	case errors.Is(err, usecase.ErrInvalidCredentials):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAccountLocked):
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidChallenge), errors.Is(err, usecase.ErrInvalidMFACode):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrCodeRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrMFAAlreadyEnabled), errors.Is(err, usecase.ErrMFANotEnabled), errors.Is(err, usecase.ErrMFANotPending):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrMFARequired):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
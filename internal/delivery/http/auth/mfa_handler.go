@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type mfaRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+func userID(c *fiber.Ctx) string {
+	id, _ := c.Locals("user_id").(string)
+	return id
+}
+
+// VerifyMFA menyelesaikan login dengan mfa_token dari /login dan code TOTP
+// atau recovery_code.
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.VerifyMFA(c.Context(), req.MFAToken, req.Code, req.RecoveryCode)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// StartChallengeEnrollment dipakai akun yang wajib 2FA tetapi belum
+// mendaftar. qr_code berisi PNG base64 dari uri.
+func (h *AuthHandler) StartChallengeEnrollment(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.StartChallengeEnrollment(c.Context(), req.MFAToken)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// ConfirmChallengeEnrollment mengaktifkan 2FA lalu menerbitkan JWT beserta
+// recovery code.
+func (h *AuthHandler) ConfirmChallengeEnrollment(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.ConfirmChallengeEnrollment(c.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *AuthHandler) MFAStatus(c *fiber.Ctx) error {
+	result, err := h.Usecase.MFAStatus(c.Context(), userID(c))
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// StartEnrollment membuat secret baru untuk akun yang login.
+func (h *AuthHandler) StartEnrollment(c *fiber.Ctx) error {
+	result, err := h.Usecase.StartEnrollment(c.Context(), userID(c))
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// ConfirmEnrollment mengaktifkan 2FA. Recovery code hanya ditampilkan sekali.
+func (h *AuthHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	codes, err := h.Usecase.ConfirmEnrollment(c.Context(), userID(c), req.Code)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// RegenerateRecoveryCodes mengganti semua recovery code lama.
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	codes, err := h.Usecase.RegenerateRecoveryCodes(c.Context(), userID(c), req.Code)
+	if err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+// DisableMFA ditolak untuk role yang wajib 2FA.
+func (h *AuthHandler) DisableMFA(c *fiber.Ctx) error {
+	var req mfaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if err := h.Usecase.DisableMFA(c.Context(), userID(c), req.Code); err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "two-factor authentication disabled"})
+}
+
+// ResetMFA menghapus 2FA akun :id; akun wajib mendaftar ulang saat login.
+func (h *AuthHandler) ResetMFA(c *fiber.Ctx) error {
+	if err := h.Usecase.ResetMFA(c.Context(), c.Params("id")); err != nil {
+		return authError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "two-factor authentication reset"})
+}
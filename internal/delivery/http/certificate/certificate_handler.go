@@ -0,0 +1,117 @@
+package certificate
+
+import (
+	"errors"
+	usecase "v2/internal/usecase/certificate"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CertificateHandler struct {
+	Usecase usecase.CertificateUsecase
+}
+
+func NewCertificateHandler(u usecase.CertificateUsecase) *CertificateHandler {
+	return &CertificateHandler{Usecase: u}
+}
+
+func (h *CertificateHandler) Issue(c *fiber.Ctx) error {
+	var req struct {
+		PhysicalExaminationID string `json:"physical_examination_id"`
+		Decision              string `json:"decision"`
+		Notes                 string `json:"notes"`
+		ClimbDate             string `json:"climb_date"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	userID, _ := c.Locals("user_id").(string)
+	cert, err := h.Usecase.Issue(c.Context(), usecase.IssueCertificateInput{
+		PhysicalExaminationID: req.PhysicalExaminationID,
+		Decision:              req.Decision,
+		Notes:                 req.Notes,
+		ClimbDate:             req.ClimbDate,
+		IssuedBy:              userID,
+	})
+	if err != nil {
+		return certificateError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(cert)
+}
+
+func (h *CertificateHandler) GetByNumber(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	cert, err := h.Usecase.FindByNumber(c.Context(), c.Params("number"), userID, role)
+	if err != nil {
+		return certificateError(c, err)
+	}
+	return c.JSON(cert)
+}
+
+func (h *CertificateHandler) GetByPatientID(c *fiber.Ctx) error {
+	patientID := c.Query("patient_id")
+	if patientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "patient_id required"})
+	}
+	certs, err := h.Usecase.FindByPatientID(c.Context(), patientID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(certs)
+}
+
+func (h *CertificateHandler) DownloadPDF(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	data, cert, err := h.Usecase.RenderPDF(c.Context(), c.Params("number"), userID, role)
+	if err != nil {
+		return certificateError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+cert.CertificateNumber+`.pdf"`)
+	return c.Send(data)
+}
+
+// Verify adalah endpoint publik untuk petugas pos pendakian (tanpa login).
+func (h *CertificateHandler) Verify(c *fiber.Ctx) error {
+	result, err := h.Usecase.Verify(c.Context(), c.Params("certificateNumber"), c.Query("t"))
+	if err != nil {
+		return certificateError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *CertificateHandler) Revoke(c *fiber.Ctx) error {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reason required"})
+	}
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.Usecase.Revoke(c.Context(), c.Params("number"), userID, req.Reason); err != nil {
+		return certificateError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "certificate revoked"})
+}
+
+func certificateError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidDecision), errors.Is(err, usecase.ErrInvalidToken):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrExaminationNotFound), errors.Is(err, usecase.ErrCertificateNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotAssessed):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAlreadyRevoked), errors.Is(err, usecase.ErrConsultationOpen),
+		errors.Is(err, usecase.ErrDecisionMismatch), errors.Is(err, usecase.ErrCertificateExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrCertificateForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,149 @@
+package consultation
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"v2/internal/domain/consultation"
+	usecase "v2/internal/usecase/consultation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConsultationHandler struct {
+	Usecase usecase.ConsultationUsecase
+}
+
+func NewConsultationHandler(u usecase.ConsultationUsecase) *ConsultationHandler {
+	return &ConsultationHandler{Usecase: u}
+}
+
+func actorFrom(c *fiber.Ctx) usecase.Actor {
+	userID, _ := c.Locals("user_id").(string)
+	role, _ := c.Locals("role").(string)
+	return usecase.Actor{UserID: userID, Role: role}
+}
+
+func (h *ConsultationHandler) Request(c *fiber.Ctx) error {
+	var req struct {
+		PhysicalExaminationID string `json:"physical_examination_id"`
+		Reason                string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.Request(c.Context(), req.PhysicalExaminationID, req.Reason, actorFrom(c))
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (h *ConsultationHandler) Assign(c *fiber.Ctx) error {
+	var req struct {
+		DoctorID string `json:"doctor_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.Assign(c.Context(), c.Params("id"), req.DoctorID)
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *ConsultationHandler) GetByID(c *fiber.Ctx) error {
+	result, err := h.Usecase.FindByID(c.Context(), c.Params("id"), actorFrom(c))
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *ConsultationHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	items, total, err := h.Usecase.FindPaginated(c.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(paginated(items, total, page, limit))
+}
+
+// Worklist adalah daftar konsultasi milik dokter yang sedang login.
+func (h *ConsultationHandler) Worklist(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	items, total, err := h.Usecase.Worklist(c.Context(), actorFrom(c), c.Query("status"), page, limit)
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(paginated(items, total, page, limit))
+}
+
+func (h *ConsultationHandler) UpdateNotes(c *fiber.Ctx) error {
+	var req struct {
+		Notes     consultation.SOAPNotes `json:"notes"`
+		Diagnosis string                 `json:"diagnosis"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.UpdateNotes(c.Context(), c.Params("id"), req.Notes, req.Diagnosis, actorFrom(c))
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *ConsultationHandler) UpdateStatus(c *fiber.Ctx) error {
+	var req usecase.StatusInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.UpdateStatus(c.Context(), c.Params("id"), req, actorFrom(c))
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *ConsultationHandler) SetDiagnoses(c *fiber.Ctx) error {
+	var req usecase.DiagnosisInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.SetDiagnoses(c.Context(), c.Params("id"), req, actorFrom(c))
+	if err != nil {
+		return consultationError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func paginated(items []consultation.Consultation, total int64, page, limit int) fiber.Map {
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	return fiber.Map{
+		"data": items,
+		"meta": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	}
+}
+
+func consultationError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrConsultationNotFound), errors.Is(err, usecase.ErrExaminationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrDoctorNotFound), errors.Is(err, usecase.ErrInvalidDecision), errors.Is(err, usecase.ErrInvalidDiagnosis):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotAssigned):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidTransition), errors.Is(err, usecase.ErrNotEditable):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
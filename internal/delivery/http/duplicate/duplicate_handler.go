@@ -0,0 +1,94 @@
+package duplicate
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	usecase "v2/internal/usecase/duplicate"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DuplicateHandler struct {
+	Usecase usecase.DuplicateUsecase
+}
+
+func NewDuplicateHandler(u usecase.DuplicateUsecase) *DuplicateHandler {
+	return &DuplicateHandler{Usecase: u}
+}
+
+func userID(c *fiber.Ctx) string {
+	id, _ := c.Locals("user_id").(string)
+	return id
+}
+
+func (h *DuplicateHandler) Scan(c *fiber.Ctx) error {
+	found, err := h.Usecase.Scan(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"new_candidates": found})
+}
+
+// List adalah antrean review; default hanya kandidat pending.
+func (h *DuplicateHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	items, total, err := h.Usecase.FindPaginated(c.Context(), c.Query("status", "pending"), page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	return c.JSON(fiber.Map{
+		"data": items,
+		"meta": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func (h *DuplicateHandler) Dismiss(c *fiber.Ctx) error {
+	result, err := h.Usecase.Dismiss(c.Context(), c.Params("id"), userID(c))
+	if err != nil {
+		return duplicateError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *DuplicateHandler) Merge(c *fiber.Ctx) error {
+	var req struct {
+		SurvivorID string `json:"survivor_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.Merge(c.Context(), c.Params("id"), req.SurvivorID, userID(c))
+	if err != nil {
+		return duplicateError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// GetMerges adalah jejak audit merge ke pasien :id.
+func (h *DuplicateHandler) GetMerges(c *fiber.Ctx) error {
+	result, err := h.Usecase.FindMerges(c.Context(), c.Params("id"))
+	if err != nil {
+		return duplicateError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func duplicateError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrCandidateNotFound), errors.Is(err, usecase.ErrPatientNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidSurvivor):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotPending), errors.Is(err, usecase.ErrAlreadyMerged), errors.Is(err, usecase.ErrBothLinked):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
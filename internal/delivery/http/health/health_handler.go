@@ -0,0 +1,32 @@
+package health
+
+import (
+	"v2/internal/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler struct {
+	Checker *health.Checker
+}
+
+func NewHealthHandler(checker *health.Checker) *HealthHandler {
+	return &HealthHandler{Checker: checker}
+}
+
+// Liveness hanya menandakan proses masih melayani request; tidak mengecek
+// dependensi agar restart tidak dipicu saat database sempat tidak tersedia.
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readiness mengembalikan 503 jika ada pengecekan yang gagal.
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	ready, checks := h.Checker.Ready(c.UserContext())
+	status, code := "ok", fiber.StatusOK
+	if !ready {
+		status, code = "unavailable", fiber.StatusServiceUnavailable
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(code).JSON(fiber.Map{"status": status, "checks": checks})
+}
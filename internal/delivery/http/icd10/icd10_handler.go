@@ -0,0 +1,56 @@
+package icd10
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	usecase "v2/internal/usecase/icd10"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ICD10Handler struct {
+	Usecase usecase.ICD10Usecase
+}
+
+func NewICD10Handler(u usecase.ICD10Usecase) *ICD10Handler {
+	return &ICD10Handler{Usecase: u}
+}
+
+func (h *ICD10Handler) Search(c *fiber.Ctx) error {
+	q := c.Query("q")
+	if q == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "q required"})
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	codes, err := h.Usecase.Search(c.Context(), q, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(codes)
+}
+
+// DiagnosisReport menerima from dan to (YYYY-MM-DD); tanggal to ikut dihitung.
+func (h *ICD10Handler) DiagnosisReport(c *fiber.Ctx) error {
+	from, err := time.ParseInLocation("2006-01-02", c.Query("from"), time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from must be YYYY-MM-DD"})
+	}
+	to, err := time.ParseInLocation("2006-01-02", c.Query("to"), time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "to must be YYYY-MM-DD"})
+	}
+	to = to.AddDate(0, 0, 1)
+	counts, err := h.Usecase.DiagnosisReport(c.Context(), from, to)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRange) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"from": c.Query("from"),
+		"to":   c.Query("to"),
+		"data": counts,
+	})
+}
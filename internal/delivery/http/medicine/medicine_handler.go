@@ -1,6 +1,7 @@
 package medicine
 
 import (
+	"errors"
 	"v2/internal/domain/medicine"
 	usecase "v2/internal/usecase/medicine"
 
@@ -36,6 +37,9 @@ func (h *MedicineHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.Update(c.Context(), id, update); err != nil {
+		if errors.Is(err, usecase.ErrMedicineNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "medicine updated"})
@@ -69,3 +73,24 @@ func (h *MedicineHandler) FindAll(c *fiber.Ctx) error {
 		},
 	})
 }
+
+func (h *MedicineHandler) Delete(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.Usecase.Delete(c.Context(), c.Params("id"), userID); err != nil {
+		if errors.Is(err, usecase.ErrMedicineNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "medicine deleted"})
+}
+
+func (h *MedicineHandler) Restore(c *fiber.Ctx) error {
+	if err := h.Usecase.Restore(c.Context(), c.Params("id")); err != nil {
+		if errors.Is(err, usecase.ErrMedicineNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "medicine restored"})
+}
@@ -1,6 +1,7 @@
 package physicalexam
 
 import (
+	"errors"
 	"v2/internal/domain/physicalexam"
 	usecase "v2/internal/usecase/physicalexam"
 
@@ -21,6 +22,9 @@ func (h *PhysicalExaminationHandler) Create(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.Create(c.Context(), exam); err != nil {
+		if errors.Is(err, usecase.ErrInvalidVitals) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.Status(fiber.StatusCreated).JSON(exam)
@@ -38,28 +42,6 @@ func (h *PhysicalExaminationHandler) GetByPatientID(c *fiber.Ctx) error {
 	return c.JSON(exams)
 }
 
-func (h *PhysicalExaminationHandler) GetDoctorConsultations(c *fiber.Ctx) error {
-	exams, err := h.Usecase.FindDoctorConsultations(c.Context())
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(exams)
-}
-
-func (h *PhysicalExaminationHandler) UpdateConsultationStatus(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var req struct {
-		Status string `json:"status"`
-	}
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
-	}
-	if err := h.Usecase.UpdateConsultationStatus(c.Context(), id, req.Status); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
-	}
-	return c.JSON(fiber.Map{"message": "consultation status updated"})
-}
-
 func (h *PhysicalExaminationHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var update map[string]interface{}
@@ -67,7 +49,35 @@ func (h *PhysicalExaminationHandler) Update(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.Update(c.Context(), id, update); err != nil {
+		if errors.Is(err, usecase.ErrInvalidVitals) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrExaminationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "physical examination updated"})
 }
+
+func (h *PhysicalExaminationHandler) Delete(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.Usecase.Delete(c.Context(), c.Params("id"), userID); err != nil {
+		if errors.Is(err, usecase.ErrExaminationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "physical examination deleted"})
+}
+
+func (h *PhysicalExaminationHandler) Restore(c *fiber.Ctx) error {
+	exam, err := h.Usecase.Restore(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrExaminationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(exam)
+}
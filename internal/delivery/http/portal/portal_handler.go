@@ -0,0 +1,129 @@
+package portal
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	usecase "v2/internal/usecase/portal"
+	timelineusecase "v2/internal/usecase/timeline"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PortalHandler melayani endpoint /me/* untuk role pasien. user_id selalu
+// diambil dari JWT, bukan dari parameter request.
+type PortalHandler struct {
+	Usecase usecase.PortalUsecase
+}
+
+func NewPortalHandler(u usecase.PortalUsecase) *PortalHandler {
+	return &PortalHandler{Usecase: u}
+}
+
+func userID(c *fiber.Ctx) string {
+	id, _ := c.Locals("user_id").(string)
+	return id
+}
+
+func (h *PortalHandler) GetProfile(c *fiber.Ctx) error {
+	result, err := h.Usecase.Profile(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *PortalHandler) UpdateProfile(c *fiber.Ctx) error {
+	var req usecase.ProfileInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.UpdateProfile(c.Context(), userID(c), req)
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *PortalHandler) GetScreenings(c *fiber.Ctx) error {
+	result, err := h.Usecase.Screenings(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *PortalHandler) GetExaminations(c *fiber.Ctx) error {
+	result, err := h.Usecase.Examinations(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *PortalHandler) GetConsultations(c *fiber.Ctx) error {
+	result, err := h.Usecase.Consultations(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func (h *PortalHandler) GetCertificates(c *fiber.Ctx) error {
+	result, err := h.Usecase.Certificates(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// GetTimeline menerima query yang sama dengan timeline pasien untuk staf.
+func (h *PortalHandler) GetTimeline(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	var types []string
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	events, total, err := h.Usecase.Timeline(c.Context(), userID(c), types, page, limit)
+	if err != nil {
+		return portalError(c, err)
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	return c.JSON(fiber.Map{
+		"data": events,
+		"meta": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func (h *PortalHandler) GetQueue(c *fiber.Ctx) error {
+	result, err := h.Usecase.QueuePosition(c.Context(), userID(c))
+	if err != nil {
+		return portalError(c, err)
+	}
+	return c.JSON(result)
+}
+
+func portalError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrPatientNotLinked), errors.Is(err, usecase.ErrNotInQueue):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, timelineusecase.ErrUnknownType):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,117 @@
+package privacy
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"v2/internal/domain/privacy"
+	usecase "v2/internal/usecase/privacy"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PrivacyHandler struct {
+	Usecase usecase.PrivacyUsecase
+}
+
+func NewPrivacyHandler(u usecase.PrivacyUsecase) *PrivacyHandler {
+	return &PrivacyHandler{Usecase: u}
+}
+
+func userID(c *fiber.Ctx) string {
+	id, _ := c.Locals("user_id").(string)
+	return id
+}
+
+// Export memulai job ekspor data pasien :id. Status dipantau lewat
+// GET /privacy-requests/:id.
+func (h *PrivacyHandler) Export(c *fiber.Ctx) error {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	result, err := h.Usecase.RequestExport(c.Context(), c.Params("id"), userID(c), req.Reason)
+	if err != nil {
+		return privacyError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(result)
+}
+
+// Erase menganonimkan pasien :id. confirm wajib true karena tidak bisa dibatalkan.
+func (h *PrivacyHandler) Erase(c *fiber.Ctx) error {
+	var req struct {
+		Reason  string `json:"reason"`
+		Confirm bool   `json:"confirm"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if !req.Confirm {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "erasure cannot be undone; set confirm to true"})
+	}
+	result, err := h.Usecase.Erase(c.Context(), c.Params("id"), userID(c), req.Reason)
+	if err != nil {
+		return privacyError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// List menerima filter patient_id, type dan status.
+func (h *PrivacyHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	items, total, err := h.Usecase.FindPaginated(c.Context(), c.Query("patient_id"), c.Query("type"), c.Query("status"), page, limit)
+	if err != nil {
+		return privacyError(c, err)
+	}
+	if items == nil {
+		items = []privacy.Request{}
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	return c.JSON(fiber.Map{
+		"data": items,
+		"meta": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func (h *PrivacyHandler) GetByID(c *fiber.Ctx) error {
+	result, err := h.Usecase.FindByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return privacyError(c, err)
+	}
+	return c.JSON(result)
+}
+
+// Download mengirim ZIP hasil ekspor.
+func (h *PrivacyHandler) Download(c *fiber.Ctx) error {
+	path, req, err := h.Usecase.ExportFile(c.Context(), c.Params("id"))
+	if err != nil {
+		return privacyError(c, err)
+	}
+	return c.Download(path, "patient-"+req.PatientID.String()+".zip")
+}
+
+func privacyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrPatientNotFound), errors.Is(err, usecase.ErrNotErasable), errors.Is(err, usecase.ErrRequestNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrReasonRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrExportNotReady):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
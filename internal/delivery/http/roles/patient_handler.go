@@ -1,9 +1,11 @@
 package roles
 
 import (
+	"errors"
 	"path/filepath"
 	"time"
 	"v2/internal/domain/roles"
+	"v2/internal/nik"
 	usecase "v2/internal/usecase/roles"
 
 	"math"
@@ -13,11 +15,12 @@ import (
 )
 
 type PatientHandler struct {
-	Usecase usecase.PatientUsecase
+	Usecase   usecase.PatientUsecase
+	UploadDir string // folder foto KTP
 }
 
-func NewPatientHandler(u usecase.PatientUsecase) *PatientHandler {
-	return &PatientHandler{Usecase: u}
+func NewPatientHandler(u usecase.PatientUsecase, uploadDir string) *PatientHandler {
+	return &PatientHandler{Usecase: u, UploadDir: uploadDir}
 }
 
 func (h *PatientHandler) CreateOrUpdatePatient(c *fiber.Ctx) error {
@@ -33,7 +36,7 @@ func (h *PatientHandler) CreateOrUpdatePatient(c *fiber.Ctx) error {
 		var paths []string
 		for _, file := range files {
 			filename := time.Now().Format("20060102150405") + "_" + file.Filename
-			path := filepath.Join("public", "uploads", filename)
+			path := filepath.Join(h.UploadDir, filename)
 			if err := c.SaveFile(file, path); err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to save file"})
 			}
@@ -44,6 +47,12 @@ func (h *PatientHandler) CreateOrUpdatePatient(c *fiber.Ctx) error {
 
 	updated, err := h.Usecase.CreateOrUpdatePatient(c.Context(), patient)
 	if err != nil {
+		if errors.Is(err, nik.ErrInvalid) || errors.Is(err, usecase.ErrNIKMismatch) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrPatientDeleted) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.Status(fiber.StatusCreated).JSON(updated)
@@ -67,3 +76,90 @@ func (h *PatientHandler) GetAll(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// Search menerima q, gender, age_min, age_max, blood_type, village, district,
+// registered_from dan registered_to (YYYY-MM-DD; tanggal to ikut dihitung).
+func (h *PatientHandler) Search(c *fiber.Ctx) error {
+	search := roles.PatientSearch{
+		Query:     c.Query("q"),
+		Gender:    c.Query("gender"),
+		BloodType: c.Query("blood_type"),
+		Village:   c.Query("village"),
+		District:  c.Query("district"),
+	}
+	search.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	search.Limit, _ = strconv.Atoi(c.Query("limit", "20"))
+	if search.Page < 1 {
+		search.Page = 1
+	}
+	if search.Limit < 1 || search.Limit > 100 {
+		search.Limit = 20
+	}
+	for key, dst := range map[string]**int{"age_min": &search.AgeMin, "age_max": &search.AgeMax} {
+		if v := c.Query(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": key + " must be a non-negative integer"})
+			}
+			*dst = &n
+		}
+	}
+	if v := c.Query("registered_from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "registered_from must be YYYY-MM-DD"})
+		}
+		search.RegisteredFrom = &from
+	}
+	if v := c.Query("registered_to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "registered_to must be YYYY-MM-DD"})
+		}
+		to = to.AddDate(0, 0, 1)
+		search.RegisteredTo = &to
+	}
+
+	results, total, err := h.Usecase.Search(c.Context(), search)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSearch) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if results == nil {
+		results = []roles.PatientSearchResult{}
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(search.Limit)))
+	return c.JSON(fiber.Map{
+		"data": results,
+		"meta": fiber.Map{
+			"page":        search.Page,
+			"limit":       search.Limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
+
+func (h *PatientHandler) Delete(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.Usecase.Delete(c.Context(), c.Params("id"), userID); err != nil {
+		if errors.Is(err, usecase.ErrPatientNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "patient deleted"})
+}
+
+func (h *PatientHandler) Restore(c *fiber.Ctx) error {
+	patient, err := h.Usecase.Restore(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrPatientNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(patient)
+}
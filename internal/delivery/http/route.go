@@ -1,47 +1,183 @@
 package http
 
 import (
+	"crypto/subtle"
+	"time"
+	auditHandlerPkg "v2/internal/delivery/http/audit"
+	authHandlerPkg "v2/internal/delivery/http/auth"
+	certificateHandlerPkg "v2/internal/delivery/http/certificate"
+	consultationHandlerPkg "v2/internal/delivery/http/consultation"
+	duplicateHandlerPkg "v2/internal/delivery/http/duplicate"
+	healthHandlerPkg "v2/internal/delivery/http/health"
+	icd10HandlerPkg "v2/internal/delivery/http/icd10"
 	medicalRecordHandlerPkg "v2/internal/delivery/http/medicalrecord"
 	medicineHandlerPkg "v2/internal/delivery/http/medicine"
 	physicalExamHandlerPkg "v2/internal/delivery/http/physicalexam"
+	portalHandlerPkg "v2/internal/delivery/http/portal"
+	privacyHandlerPkg "v2/internal/delivery/http/privacy"
 	patientHandlerPkg "v2/internal/delivery/http/roles"
 	screeningHandlerPkg "v2/internal/delivery/http/screening"
+	timelineHandlerPkg "v2/internal/delivery/http/timeline"
+	"v2/internal/domain/audit"
+	"v2/internal/jwtauth"
+	"v2/internal/metrics"
 	"v2/internal/middleware"
+	auditUsecasePkg "v2/internal/usecase/audit"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
-func RegisterRoutes(router fiber.Router, userHandler *UserHandler, screeningHandler *screeningHandlerPkg.ScreeningHandler, medicalRecordHandler *medicalRecordHandlerPkg.MedicalRecordHandler, patientHandler *patientHandlerPkg.PatientHandler, physicalExamHandler *physicalExamHandlerPkg.PhysicalExaminationHandler, medicineHandler *medicineHandlerPkg.MedicineHandler) {
+// RegisterPublicRoutes mendaftarkan endpoint tanpa prefix /api/v1: verifikasi
+// dari QR code, JWKS dan health check untuk orchestrator.
+func RegisterPublicRoutes(router fiber.Router, certificateHandler *certificateHandlerPkg.CertificateHandler, tokens *jwtauth.Manager, healthHandler *healthHandlerPkg.HealthHandler) {
+	router.Get("/healthz", healthHandler.Liveness)
+	router.Get("/readyz", healthHandler.Readiness)
+
+	verifyLimiter := limiter.New(limiter.Config{
+		Max:        30,
+		Expiration: time.Minute,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "too many requests"})
+		},
+	})
+	router.Get("/verify/:certificateNumber", verifyLimiter, certificateHandler.Verify)
+
+	// Kunci publik JWT untuk layanan lain yang memverifikasi token kita
+	router.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(tokens.JWKS())
+	})
+}
+
+// RegisterMetricsRoutes mendaftarkan /metrics untuk Prometheus. Jika token
+// diisi, request wajib membawa header Authorization: Bearer <token>.
+func RegisterMetricsRoutes(router fiber.Router, token string) {
+	handler := adaptor.HTTPHandler(metrics.Handler())
+	router.Get("/metrics", func(c *fiber.Ctx) error {
+		if token != "" && subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		}
+		return handler(c)
+	})
+}
+
+func RegisterRoutes(router fiber.Router, userHandler *UserHandler, authHandler *authHandlerPkg.AuthHandler, screeningHandler *screeningHandlerPkg.ScreeningHandler, medicalRecordHandler *medicalRecordHandlerPkg.MedicalRecordHandler, patientHandler *patientHandlerPkg.PatientHandler, physicalExamHandler *physicalExamHandlerPkg.PhysicalExaminationHandler, medicineHandler *medicineHandlerPkg.MedicineHandler, certificateHandler *certificateHandlerPkg.CertificateHandler, consultationHandler *consultationHandlerPkg.ConsultationHandler, icd10Handler *icd10HandlerPkg.ICD10Handler, timelineHandler *timelineHandlerPkg.TimelineHandler, portalHandler *portalHandlerPkg.PortalHandler, duplicateHandler *duplicateHandlerPkg.DuplicateHandler, privacyHandler *privacyHandlerPkg.PrivacyHandler, auditHandler *auditHandlerPkg.AuditHandler, auditRecorder auditUsecasePkg.Recorder, tokens *jwtauth.Manager) {
+	// read mencatat akses baca data pasien ke audit log
+	read := func(entity string) fiber.Handler {
+		return middleware.AuditRead(auditRecorder, entity)
+	}
+
 	router.Post("/register", userHandler.Register)
-	router.Post("/login", userHandler.Login)
-	router.Get("/me", middleware.AuthMiddleware(), userHandler.Me)
+	router.Post("/login", authHandler.Login)
+	router.Post("/login/mfa", authHandler.VerifyMFA)
+	router.Post("/login/mfa/enroll", authHandler.StartChallengeEnrollment)
+	router.Post("/login/mfa/enroll/confirm", authHandler.ConfirmChallengeEnrollment)
+	router.Get("/me", middleware.AuthMiddleware(tokens), userHandler.Me)
+	router.Post("/users/:id/unlock", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), authHandler.Unlock)
+	router.Post("/users/:id/mfa/reset", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), authHandler.ResetMFA)
+
+	// 2FA akun yang login
+	router.Get("/mfa", middleware.AuthMiddleware(tokens), authHandler.MFAStatus)
+	router.Post("/mfa/enroll", middleware.AuthMiddleware(tokens), authHandler.StartEnrollment)
+	router.Post("/mfa/confirm", middleware.AuthMiddleware(tokens), authHandler.ConfirmEnrollment)
+	router.Post("/mfa/recovery-codes", middleware.AuthMiddleware(tokens), authHandler.RegenerateRecoveryCodes)
+	router.Delete("/mfa", middleware.AuthMiddleware(tokens), authHandler.DisableMFA)
+	router.Get("/login-events", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), authHandler.ListEvents)
+
+	// Portal pasien: data selalu dibatasi ke pasien milik akun login. Middleware
+	// dipasang per route, bukan lewat router.Group("/me"), karena Fiber
+	// mencocokkan prefix grup sebagai string sehingga ikut mengenai /medicines
+	// dan /medical-record.
+	pasien := func(handlers ...fiber.Handler) []fiber.Handler {
+		return append([]fiber.Handler{middleware.AuthMiddleware(tokens), middleware.RoleOnly("pasien")}, handlers...)
+	}
+	router.Get("/me/profile", pasien(read(audit.EntityPatient), portalHandler.GetProfile)...)
+	router.Patch("/me/profile", pasien(portalHandler.UpdateProfile)...)
+	router.Get("/me/screenings", pasien(read(audit.EntityScreeningAnswer), portalHandler.GetScreenings)...)
+	router.Get("/me/examinations", pasien(read(audit.EntityPhysicalExamination), portalHandler.GetExaminations)...)
+	router.Get("/me/consultations", pasien(read(audit.EntityConsultation), portalHandler.GetConsultations)...)
+	router.Get("/me/certificates", pasien(read(audit.EntityCertificate), portalHandler.GetCertificates)...)
+	router.Get("/me/timeline", pasien(read(audit.EntityTimeline), portalHandler.GetTimeline)...)
+	router.Get("/me/queue", pasien(portalHandler.GetQueue)...)
 
 	// Patient
-	router.Post("/patients", patientHandler.CreateOrUpdatePatient)
+	router.Post("/patients", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "kasir"), patientHandler.CreateOrUpdatePatient)
+	router.Get("/patients/search", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter", "paramedis", "kasir"), read(audit.EntityPatient), patientHandler.Search)
+	router.Post("/patients/duplicates/scan", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), duplicateHandler.Scan)
+	router.Get("/patients/duplicates", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), read(audit.EntityPatient), duplicateHandler.List)
+	router.Post("/patients/duplicates/:id/dismiss", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), duplicateHandler.Dismiss)
+	router.Post("/patients/duplicates/:id/merge", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), duplicateHandler.Merge)
+	router.Delete("/patients/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), patientHandler.Delete)
+	router.Post("/patients/:id/restore", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), patientHandler.Restore)
+	router.Get("/patients/:id/merges", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), read(audit.EntityPatient), duplicateHandler.GetMerges)
+	router.Get("/patients/:id/timeline", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter", "paramedis"), read(audit.EntityTimeline), timelineHandler.GetByPatientID)
 
-	// Screening routes (no auth)
+	// Hak subjek data (UU PDP)
+	router.Post("/patients/:id/export", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), privacyHandler.Export)
+	router.Post("/patients/:id/erasure", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), privacyHandler.Erase)
+	router.Get("/privacy-requests", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), privacyHandler.List)
+	router.Get("/privacy-requests/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), privacyHandler.GetByID)
+	router.Get("/privacy-requests/:id/download", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), privacyHandler.Download)
+
+	// Screening. Pertanyaan dan /screening/with-patient tetap tanpa login untuk
+	// kiosk screening; audit-nya tercatat sebagai anonymous beserta IP.
 	router.Get("/screening/questions", screeningHandler.GetQuestions)
-	router.Post("/screening/questions", middleware.AuthMiddleware(), middleware.AdminOnly(), screeningHandler.CreateQuestion)
-	router.Patch("/screening/questions/:id", middleware.AuthMiddleware(), middleware.AdminOnly(), screeningHandler.UpdateQuestion)
-	router.Post("/screening/answers", screeningHandler.SubmitAnswer)
-	router.Post("/screening/queue", screeningHandler.EnqueueScreening)
+	router.Post("/screening/questions", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.CreateQuestion)
+	router.Patch("/screening/questions/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.UpdateQuestion)
+	router.Put("/screening/questions/:id/conditions", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.UpdateQuestionConditions)
+	router.Delete("/screening/questions/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.DeleteQuestion)
+	router.Post("/screening/questions/:id/restore", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.RestoreQuestion)
+	router.Get("/screening/risk-rules", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.ListRiskRules)
+	router.Post("/screening/risk-rules", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.CreateRiskRule)
+	router.Put("/screening/risk-rules/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.UpdateRiskRule)
+	router.Delete("/screening/risk-rules/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), screeningHandler.DeleteRiskRule)
+	router.Post("/screening/answers", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "kasir", "paramedis"), screeningHandler.SubmitAnswer)
+	router.Post("/screening/queue", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "kasir", "paramedis"), screeningHandler.EnqueueScreening)
 	router.Post("/screening/with-patient", screeningHandler.ScreeningWithPatient)
-	router.Get("/screening/queue", screeningHandler.ListQueue)
+	router.Get("/screening/queue", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis", "dokter"), read(audit.EntityScreeningAnswer), screeningHandler.ListQueue)
 
 	// Medical Record
-	router.Post("/medical-record", medicalRecordHandler.CreateMedicalRecord)
+	router.Post("/medical-record", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "kasir", "paramedis"), medicalRecordHandler.CreateMedicalRecord)
 
 	// Physical Examination
-	router.Post("/physical-examinations", physicalExamHandler.Create)
-	router.Get("/physical-examinations/by-patient", physicalExamHandler.GetByPatientID)
-	router.Get("/doctor/consultations", physicalExamHandler.GetDoctorConsultations)
-	router.Patch("/physical-examinations/:id/consultation-status", physicalExamHandler.UpdateConsultationStatus)
-	router.Patch("/physical-examinations/:id", physicalExamHandler.Update)
-	router.Patch("/screening/answers/:id", screeningHandler.UpdateScreeningAnswer)
-	router.Get("/doctor/patients", patientHandler.GetAll)
+	router.Post("/physical-examinations", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis"), physicalExamHandler.Create)
+	router.Get("/physical-examinations/by-patient", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter", "paramedis"), read(audit.EntityPhysicalExamination), physicalExamHandler.GetByPatientID)
+	router.Patch("/physical-examinations/:id", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis", "dokter"), physicalExamHandler.Update)
+	router.Delete("/physical-examinations/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), physicalExamHandler.Delete)
+	router.Post("/physical-examinations/:id/restore", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), physicalExamHandler.Restore)
+	router.Patch("/screening/answers/:id", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis"), screeningHandler.UpdateScreeningAnswer)
+	router.Get("/doctor/patients", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter"), read(audit.EntityPatient), patientHandler.GetAll)
+
+	// Consultation
+	router.Post("/consultations", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis"), consultationHandler.Request)
+	router.Get("/consultations", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis"), read(audit.EntityConsultation), consultationHandler.List)
+	router.Get("/consultations/:id", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis", "dokter"), read(audit.EntityConsultation), consultationHandler.GetByID)
+	router.Patch("/consultations/:id/assign", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "paramedis"), consultationHandler.Assign)
+	router.Patch("/consultations/:id/status", middleware.AuthMiddleware(tokens), middleware.RoleOnly("dokter"), consultationHandler.UpdateStatus)
+	router.Put("/consultations/:id/notes", middleware.AuthMiddleware(tokens), middleware.RoleOnly("dokter"), consultationHandler.UpdateNotes)
+	router.Put("/consultations/:id/diagnoses", middleware.AuthMiddleware(tokens), middleware.RoleOnly("dokter"), consultationHandler.SetDiagnoses)
+	router.Get("/doctor/consultations", middleware.AuthMiddleware(tokens), middleware.RoleOnly("dokter"), read(audit.EntityConsultation), consultationHandler.Worklist)
+
+	// ICD-10
+	router.Get("/icd10", middleware.AuthMiddleware(tokens), icd10Handler.Search)
+	router.Get("/reports/diagnoses", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter"), icd10Handler.DiagnosisReport)
+
+	// Certificate
+	router.Post("/certificates", middleware.AuthMiddleware(tokens), middleware.RoleOnly("dokter", "paramedis"), certificateHandler.Issue)
+	router.Get("/certificates", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter", "paramedis", "kasir"), read(audit.EntityCertificate), certificateHandler.GetByPatientID)
+	router.Get("/certificates/:number", middleware.AuthMiddleware(tokens), read(audit.EntityCertificate), certificateHandler.GetByNumber)
+	router.Get("/certificates/:number/pdf", middleware.AuthMiddleware(tokens), read(audit.EntityCertificate), certificateHandler.DownloadPDF)
+	router.Post("/certificates/:number/revoke", middleware.AuthMiddleware(tokens), middleware.RoleOnly("admin", "dokter"), certificateHandler.Revoke)
+
+	// Audit log
+	router.Get("/audit-logs", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), auditHandler.List)
 
 	// Medicine
-	router.Post("/medicines", middleware.AuthMiddleware(), middleware.AdminOnly(), medicineHandler.Create)
-	router.Patch("/medicines/:id", middleware.AuthMiddleware(), middleware.AdminOnly(), medicineHandler.Update)
+	router.Post("/medicines", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), medicineHandler.Create)
+	router.Patch("/medicines/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), medicineHandler.Update)
+	router.Delete("/medicines/:id", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), medicineHandler.Delete)
+	router.Post("/medicines/:id/restore", middleware.AuthMiddleware(tokens), middleware.AdminOnly(), medicineHandler.Restore)
 	router.Get("/medicines", medicineHandler.FindAll)
 }
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	auditHandlerPkg "v2/internal/delivery/http/audit"
+	authHandlerPkg "v2/internal/delivery/http/auth"
+	certificateHandlerPkg "v2/internal/delivery/http/certificate"
+	consultationHandlerPkg "v2/internal/delivery/http/consultation"
+	duplicateHandlerPkg "v2/internal/delivery/http/duplicate"
+	icd10HandlerPkg "v2/internal/delivery/http/icd10"
+	medicalRecordHandlerPkg "v2/internal/delivery/http/medicalrecord"
+	medicineHandlerPkg "v2/internal/delivery/http/medicine"
+	physicalExamHandlerPkg "v2/internal/delivery/http/physicalexam"
+	portalHandlerPkg "v2/internal/delivery/http/portal"
+	privacyHandlerPkg "v2/internal/delivery/http/privacy"
+	patientHandlerPkg "v2/internal/delivery/http/roles"
+	screeningHandlerPkg "v2/internal/delivery/http/screening"
+	timelineHandlerPkg "v2/internal/delivery/http/timeline"
+	"v2/internal/domain/medicalrecord"
+	"v2/internal/domain/medicine"
+	"v2/internal/domain/roles"
+	"v2/internal/jwtauth"
+	medicalrecordusecase "v2/internal/usecase/medicalrecord"
+	medicineusecase "v2/internal/usecase/medicine"
+	portalusecase "v2/internal/usecase/portal"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Usecase palsu hanya mengimplementasikan method yang dipanggil route di
+// bawah; method lain lewat interface yang di-embed (panic jika terpanggil).
+
+type fakeMedicines struct {
+	medicineusecase.MedicineUsecase
+}
+
+func (fakeMedicines) Create(context.Context, *medicine.Medicine) error { return nil }
+
+func (fakeMedicines) FindAllPaginated(context.Context, int, int) ([]medicine.Medicine, int64, error) {
+	return nil, 0, nil
+}
+
+type fakeMedicalRecords struct {
+	medicalrecordusecase.MedicalRecordUsecase
+}
+
+func (fakeMedicalRecords) CreateMedicalRecord(context.Context, string) (*medicalrecord.MedicalRecord, error) {
+	return &medicalrecord.MedicalRecord{}, nil
+}
+
+type fakePortal struct{ portalusecase.PortalUsecase }
+
+func (fakePortal) Profile(context.Context, string) (*roles.Patient, error) {
+	return &roles.Patient{}, nil
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) Record(context.Context, string, string, string, interface{}, interface{}) {}
+
+func newTestApp(t *testing.T) (*fiber.App, *jwtauth.Manager) {
+	t.Helper()
+	tokens, err := jwtauth.New(jwtauth.Settings{Secret: "test-secret", TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := fiber.New()
+	RegisterRoutes(app.Group("/api/v1"),
+		&UserHandler{}, &authHandlerPkg.AuthHandler{}, &screeningHandlerPkg.ScreeningHandler{},
+		medicalRecordHandlerPkg.NewMedicalRecordHandler(fakeMedicalRecords{}), &patientHandlerPkg.PatientHandler{},
+		&physicalExamHandlerPkg.PhysicalExaminationHandler{}, medicineHandlerPkg.NewMedicineHandler(fakeMedicines{}),
+		&certificateHandlerPkg.CertificateHandler{}, &consultationHandlerPkg.ConsultationHandler{}, &icd10HandlerPkg.ICD10Handler{},
+		&timelineHandlerPkg.TimelineHandler{}, portalHandlerPkg.NewPortalHandler(fakePortal{}), &duplicateHandlerPkg.DuplicateHandler{},
+		&privacyHandlerPkg.PrivacyHandler{}, &auditHandlerPkg.AuditHandler{}, nopRecorder{}, tokens)
+	return app, tokens
+}
+
+func TestRouteAccess(t *testing.T) {
+	app, tokens := newTestApp(t)
+	token := func(role string) string {
+		tok, err := tokens.Generate(uuid.NewString(), role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	admin, pasien := token("admin"), token("pasien")
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{"GET", "/api/v1/medicines", "", fiber.StatusOK},
+		{"GET", "/api/v1/medicines", admin, fiber.StatusOK},
+		{"POST", "/api/v1/medicines", admin, fiber.StatusCreated},
+		{"POST", "/api/v1/medical-record", admin, fiber.StatusCreated},
+		{"GET", "/api/v1/me/profile", pasien, fiber.StatusOK},
+		{"GET", "/api/v1/me/profile", admin, fiber.StatusForbidden},
+		{"GET", "/api/v1/me/profile", "", fiber.StatusUnauthorized},
+		{"POST", "/api/v1/medicines", pasien, fiber.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		if tt.token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+tt.token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
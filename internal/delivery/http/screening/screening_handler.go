@@ -1,6 +1,7 @@
 package screening
 
 import (
+	"errors"
 	"v2/internal/domain/screening"
 	usecase "v2/internal/usecase/screening"
 
@@ -32,6 +33,9 @@ func (h *ScreeningHandler) SubmitAnswer(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.SubmitAnswer(c.Context(), &req); err != nil {
+		if errors.Is(err, usecase.ErrRequiredAnswer) || errors.Is(err, usecase.ErrUnknownQuestion) || errors.Is(err, usecase.ErrInvalidAnswer) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to submit answer"})
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "answer submitted"})
@@ -70,6 +74,9 @@ func (h *ScreeningHandler) UpdateScreeningAnswer(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.UpdateScreeningAnswer(c.Context(), id, update); err != nil {
+		if errors.Is(err, usecase.ErrRequiredAnswer) || errors.Is(err, usecase.ErrUnknownQuestion) || errors.Is(err, usecase.ErrInvalidAnswer) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "screening answer updated"})
@@ -81,6 +88,9 @@ func (h *ScreeningHandler) CreateQuestion(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.CreateQuestion(c.Context(), &q); err != nil {
+		if errors.Is(err, usecase.ErrInvalidCondition) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.Status(fiber.StatusCreated).JSON(q)
@@ -93,13 +103,63 @@ func (h *ScreeningHandler) UpdateQuestion(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
 	}
 	if err := h.Usecase.UpdateQuestion(c.Context(), id, update); err != nil {
+		if errors.Is(err, usecase.ErrInvalidCondition) || errors.Is(err, usecase.ErrInvalidPosition) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrUnknownQuestion) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 	return c.JSON(fiber.Map{"message": "question updated"})
 }
 
+func (h *ScreeningHandler) UpdateQuestionConditions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var req struct {
+		Conditions []screening.DisplayCondition `json:"conditions"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if err := h.Usecase.UpdateQuestionConditions(c.Context(), id, req.Conditions); err != nil {
+		if errors.Is(err, usecase.ErrInvalidCondition) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrUnknownQuestion) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "question conditions updated"})
+}
+
+func (h *ScreeningHandler) DeleteQuestion(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.Usecase.DeleteQuestion(c.Context(), c.Params("id"), userID); err != nil {
+		if errors.Is(err, usecase.ErrUnknownQuestion) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrQuestionInUse) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "question deleted"})
+}
+
+func (h *ScreeningHandler) RestoreQuestion(c *fiber.Ctx) error {
+	if err := h.Usecase.RestoreQuestion(c.Context(), c.Params("id")); err != nil {
+		if errors.Is(err, usecase.ErrUnknownQuestion) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "question restored"})
+}
+
 func (h *ScreeningHandler) ListQueue(c *fiber.Ctx) error {
-	status := c.Query("status", "screening_pending")
+	status := c.Query("status", screening.QueueWaiting)
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 	queues, total, err := h.Usecase.FindQueuePaginatedByStatus(c.Context(), status, page, limit)
@@ -117,3 +177,52 @@ func (h *ScreeningHandler) ListQueue(c *fiber.Ctx) error {
 		},
 	})
 }
+
+func (h *ScreeningHandler) ListRiskRules(c *fiber.Ctx) error {
+	rules, err := h.Usecase.ListRiskRules(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(rules)
+}
+
+func (h *ScreeningHandler) CreateRiskRule(c *fiber.Ctx) error {
+	var rule screening.RiskRule
+	if err := c.BodyParser(&rule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if err := h.Usecase.CreateRiskRule(c.Context(), &rule); err != nil {
+		return riskRuleError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+func (h *ScreeningHandler) UpdateRiskRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var rule screening.RiskRule
+	if err := c.BodyParser(&rule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
+	}
+	if err := h.Usecase.UpdateRiskRule(c.Context(), id, &rule); err != nil {
+		return riskRuleError(c, err)
+	}
+	return c.JSON(rule)
+}
+
+func (h *ScreeningHandler) DeleteRiskRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := h.Usecase.DeleteRiskRule(c.Context(), id); err != nil {
+		return riskRuleError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "risk rule deleted"})
+}
+
+func riskRuleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRiskRule):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrRiskRuleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,57 @@
+package timeline
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	usecase "v2/internal/usecase/timeline"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TimelineHandler struct {
+	Usecase usecase.TimelineUsecase
+}
+
+func NewTimelineHandler(u usecase.TimelineUsecase) *TimelineHandler {
+	return &TimelineHandler{Usecase: u}
+}
+
+// GetByPatientID menerima filter opsional ?types=physical_examination,certificate
+func (h *TimelineHandler) GetByPatientID(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	var types []string
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	events, total, err := h.Usecase.FindByPatientID(c.Context(), c.Params("id"), types, page, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPatientNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, usecase.ErrUnknownType):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	return c.JSON(fiber.Map{
+		"data": events,
+		"meta": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+		},
+	})
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
-	"log"
+	"errors"
 	"strings"
+	"v2/internal/nik"
 	"v2/internal/usecase"
+	patientusecase "v2/internal/usecase/roles"
 
 	"v2/internal/repository"
 
@@ -42,11 +44,9 @@ type RegisterPatientRequest struct {
 	BloodType   string `json:"blood_type"`
 	Height      int    `json:"height"`
 	Weight      int    `json:"weight"`
-	Age         int    `json:"age"`
 }
 
 func (h *UserHandler) Register(c *fiber.Ctx) error {
-	log.Println("Masuk handler /register")
 	var req RegisterPatientRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
@@ -74,7 +74,6 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 		BloodType:   req.BloodType,
 		Height:      req.Height,
 		Weight:      req.Weight,
-		Age:         req.Age,
 	}
 
 	err := h.UserUsecase.RegisterPatient(c.Context(), input)
@@ -82,51 +81,37 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 		if strings.Contains(err.Error(), "email already exists") {
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "email already exists"})
 		}
+		if errors.Is(err, usecase.ErrNIKAlreadyLinked) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, nik.ErrInvalid) || errors.Is(err, patientusecase.ErrNIKMismatch) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to register patient"})
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"message": "patient registered successfully"})
 }
 
-type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
-}
-
-type LoginResponse struct {
-	Token string `json:"token"`
-}
-
-func (h *UserHandler) Login(c *fiber.Ctx) error {
-	log.Println("Masuk handler /login")
-	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "cannot parse request"})
-	}
-	// TODO: Add validation for the request struct
-	token, err := h.UserUsecase.Login(c.Context(), req.Email, req.Password)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid email or password"})
-	}
-	return c.JSON(LoginResponse{Token: token})
-}
-
 // Me godoc
 // @Summary Get current user profile
-// @Description Get data of the currently logged-in user
+// @Description Get data of the currently logged-in user with the role-specific profile
 // @Tags Profile
 // @Accept json
 // @Produce json
-// @Success 200 {object} roles.User
+// @Success 200 {object} roles.UserProfile
 // @Router /api/v1/me [get]
 func (h *UserHandler) Me(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
-	user, err := h.UserRepo.FindByID(c.Context(), userID.(string))
-	if err != nil || user == nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+	profile, err := h.UserUsecase.Me(c.Context(), userID.(string))
+	if err != nil {
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "user not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(user)
+	return c.JSON(profile)
 }
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aksi yang dicatat
+const (
+	ActionRead    = "read"
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionMerge   = "merge"
+	ActionRestore = "restore"
+	ActionExport  = "export"
+	ActionErase   = "erase"
+	ActionUnlock  = "unlock"
+	// Pendaftaran dan penghapusan 2FA akun
+	ActionMFAEnable  = "mfa_enable"
+	ActionMFADisable = "mfa_disable"
+)
+
+// Entitas data pasien yang diaudit
+const (
+	EntityPatient             = "patient"
+	EntityMedicalRecord       = "medical_record"
+	EntityPhysicalExamination = "physical_examination"
+	EntityConsultation        = "consultation"
+	EntityCertificate         = "certificate"
+	EntityScreeningAnswer     = "screening_answer"
+	EntityTimeline            = "timeline"
+	EntityPrivacyRequest      = "privacy_request"
+	EntityUser                = "user"
+)
+
+// RoleAnonymous dicatat sebagai ActorRole untuk request tanpa login (kiosk
+// screening dan registrasi mandiri); IP tetap tersimpan.
+const RoleAnonymous = "anonymous"
+
+// Entry adalah satu baris audit log. Untuk penulisan, Before dan After hanya
+// berisi field yang berubah.
+type Entry struct {
+	ID        uuid.UUID       `json:"id"`
+	ActorID   *uuid.UUID      `json:"actor_id,omitempty"`
+	ActorRole string          `json:"actor_role"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	IP        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+	Method    string          `json:"method"`
+	Path      string          `json:"path"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Request adalah info request HTTP yang disimpan di context oleh middleware audit.
+type Request struct {
+	IP        string
+	UserAgent string
+	Method    string
+	Path      string
+}
+
+// RequestKey adalah key context untuk Request.
+type RequestKey struct{}
+
+// Filter untuk query audit log; field kosong atau nil diabaikan.
+type Filter struct {
+	ActorID  string
+	Role     string
+	Action   string
+	Entity   string
+	EntityID string
+	From     *time.Time
+	To       *time.Time // eksklusif
+	Page     int
+	Limit    int
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hasil percobaan login yang dicatat di login_events
+const (
+	ReasonSuccess         = "success"
+	ReasonUnknownUser     = "unknown_user"
+	ReasonInvalidPassword = "invalid_password"
+	ReasonLocked          = "locked"
+	ReasonIPLimited       = "ip_rate_limited"
+	ReasonAccountLimited  = "account_rate_limited"
+	// Password benar, menunggu kode TOTP atau pendaftaran 2FA
+	ReasonMFARequired   = "mfa_required"
+	ReasonMFAEnrollment = "mfa_enrollment_required"
+	ReasonInvalidMFA    = "invalid_mfa_code"
+	// Login berhasil memakai recovery code
+	ReasonRecoveryCode = "recovery_code"
+)
+
+// Jenis challenge MFA
+const (
+	ChallengeVerify = "verify" // akun sudah memakai 2FA, menunggu kode
+	ChallengeEnroll = "enroll" // role wajib 2FA tetapi akun belum mendaftar
+)
+
+// LoginEvent adalah satu percobaan login. UserID kosong jika email tidak
+// terdaftar.
+type LoginEvent struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Email     string     `json:"email"`
+	Success   bool       `json:"success"`
+	Reason    string     `json:"reason"`
+	IP        string     `json:"ip"`
+	UserAgent string     `json:"user_agent"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// EventFilter untuk query login_events; field kosong atau nil diabaikan.
+// Success berisi "true" atau "false".
+type EventFilter struct {
+	UserID  string
+	Email   string
+	IP      string
+	Success string
+	Reason  string
+	From    *time.Time
+	To      *time.Time // eksklusif
+	Page    int
+	Limit   int
+}
+
+// Challenge adalah langkah kedua login. Token aslinya hanya diberikan ke
+// klien; database menyimpan hash-nya.
+type Challenge struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Kind      string
+	Attempts  int
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// TOTP adalah status 2FA akun. Secret terenkripsi di database; EnabledAt
+// kosong berarti pendaftaran belum dikonfirmasi.
+type TOTP struct {
+	Secret    string
+	EnabledAt *time.Time
+	LastStep  int64
+}
+
+// LoginResult berisi Token jika login selesai, atau MFAToken jika masih
+// perlu kode TOTP (MFARequired) atau pendaftaran 2FA (MFAEnrollmentRequired).
+type LoginResult struct {
+	Token                 string     `json:"token,omitempty"`
+	MFARequired           bool       `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string     `json:"mfa_token,omitempty"`
+	MFAExpiresAt          *time.Time `json:"mfa_expires_at,omitempty"`
+	RecoveryCodes         []string   `json:"recovery_codes,omitempty"`
+}
+
+// Enrollment adalah data untuk mendaftarkan aplikasi authenticator.
+// QRCode berisi PNG dari URI.
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode []byte `json:"qr_code"`
+}
@@ -0,0 +1,76 @@
+package certificate
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status hasil verifikasi sertifikat
+const (
+	StatusValid   = "valid"
+	StatusExpired = "expired"
+	StatusRevoked = "revoked"
+)
+
+// Keputusan kelayakan mendaki
+const (
+	DecisionFit               = "fit"
+	DecisionFitWithConditions = "fit_with_conditions"
+	DecisionUnfit             = "unfit"
+)
+
+// Certificate adalah surat keterangan sehat pendakian. Data pasien dan tanda
+// vital disalin saat penerbitan agar PDF dapat dibuat ulang persis sama.
+type Certificate struct {
+	ID                    uuid.UUID  `json:"id"`
+	CertificateNumber     string     `json:"certificate_number"`
+	PatientID             uuid.UUID  `json:"patient_id"`
+	PhysicalExaminationID uuid.UUID  `json:"physical_examination_id"`
+	PatientName           string     `json:"patient_name"`
+	PatientNIK            string     `json:"patient_nik"`
+	MRNumber              string     `json:"mr_number"`
+	Vitals                Vitals     `json:"vitals"`
+	Decision              string     `json:"decision"`
+	DecidedByRole         string     `json:"decided_by_role"` // dokter, paramedis
+	DecidedByName         string     `json:"decided_by_name"`
+	Notes                 string     `json:"notes,omitempty"`
+	ClimbDate             string     `json:"climb_date,omitempty"`
+	IssuedBy              uuid.UUID  `json:"issued_by"`
+	IssuedAt              time.Time  `json:"issued_at"`
+	ValidUntil            time.Time  `json:"valid_until"`
+	RevokedAt             *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy             *uuid.UUID `json:"revoked_by,omitempty"`
+	RevokeReason          string     `json:"revoke_reason,omitempty"`
+}
+
+// Status menghitung status sertifikat pada waktu tertentu.
+func (c *Certificate) Status(now time.Time) string {
+	if c.RevokedAt != nil {
+		return StatusRevoked
+	}
+	if now.After(c.ValidUntil) {
+		return StatusExpired
+	}
+	return StatusValid
+}
+
+// Verification adalah tampilan publik sertifikat untuk petugas pos pendakian.
+// Hanya berisi data yang tidak sensitif.
+type Verification struct {
+	CertificateNumber string    `json:"certificate_number"`
+	Initials          string    `json:"initials"`
+	Status            string    `json:"status"`
+	ClimbDate         string    `json:"climb_date,omitempty"`
+	ValidUntil        time.Time `json:"valid_until"`
+	SignatureValid    bool      `json:"signature_valid"`
+}
+
+type Vitals struct {
+	BloodPressure    string   `json:"blood_pressure,omitempty"`
+	HeartRate        *int     `json:"heart_rate,omitempty"`
+	OxygenSaturation *int     `json:"oxygen_saturation,omitempty"`
+	RespiratoryRate  *int     `json:"respiratory_rate,omitempty"`
+	BodyTemperature  *float64 `json:"body_temperature,omitempty"`
+	HealthStatus     string   `json:"health_status,omitempty"`
+}
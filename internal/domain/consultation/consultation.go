@@ -0,0 +1,78 @@
+package consultation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status konsultasi dokter
+const (
+	StatusRequested = "requested"
+	StatusAccepted  = "accepted"
+	StatusInSession = "in_session"
+	StatusCompleted = "completed"
+	StatusReferred  = "referred"
+)
+
+// IsClosed bernilai true untuk status akhir; konsultasi yang sudah selesai
+// atau dirujuk tidak bisa dilanjutkan lagi.
+func IsClosed(status string) bool {
+	return status == StatusCompleted || status == StatusReferred
+}
+
+// Consultation adalah konsultasi dokter yang berasal dari pemeriksaan fisik.
+type Consultation struct {
+	ID                    uuid.UUID   `json:"id"`
+	PhysicalExaminationID uuid.UUID   `json:"physical_examination_id"`
+	PatientID             uuid.UUID   `json:"patient_id"`
+	DoctorID              *uuid.UUID  `json:"doctor_id,omitempty"`
+	Status                string      `json:"status"`
+	Reason                string      `json:"reason,omitempty"`
+	Notes                 SOAPNotes   `json:"notes"`
+	Diagnosis             string      `json:"diagnosis,omitempty"`
+	DiagnosisCodes        []Diagnosis `json:"diagnosis_codes,omitempty"`
+	FitnessDecision       string      `json:"fitness_decision,omitempty"` // fit, fit_with_conditions, unfit
+	ReferralNote          string      `json:"referral_note,omitempty"`
+	RequestedBy           *uuid.UUID  `json:"requested_by,omitempty"`
+	AcceptedAt            *time.Time  `json:"accepted_at,omitempty"`
+	StartedAt             *time.Time  `json:"started_at,omitempty"`
+	FinishedAt            *time.Time  `json:"finished_at,omitempty"`
+	CreatedAt             time.Time   `json:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at"`
+}
+
+// SOAPNotes adalah catatan klinis terstruktur (Subjective, Objective,
+// Assessment, Plan).
+type SOAPNotes struct {
+	Subjective string `json:"subjective,omitempty"`
+	Objective  string `json:"objective,omitempty"`
+	Assessment string `json:"assessment,omitempty"`
+	Plan       string `json:"plan,omitempty"`
+}
+
+// Diagnosis adalah kode ICD-10 yang dicatat pada konsultasi. Satu konsultasi
+// memiliki paling banyak satu diagnosis primer.
+type Diagnosis struct {
+	Code    string `json:"code"`
+	NameEN  string `json:"name_en,omitempty"`
+	NameID  string `json:"name_id,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+// transitions berisi perpindahan status yang diizinkan.
+var transitions = map[string][]string{
+	StatusRequested: {StatusAccepted},
+	StatusAccepted:  {StatusInSession},
+	StatusInSession: {StatusCompleted, StatusReferred},
+}
+
+// CanTransition memeriksa apakah status boleh berpindah dari from ke to.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package duplicate
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status kandidat duplikat
+const (
+	StatusPending   = "pending"
+	StatusMerged    = "merged"
+	StatusDismissed = "dismissed"
+)
+
+// Alasan kecocokan kandidat
+const (
+	ReasonName      = "name"
+	ReasonBirthDate = "birth_date"
+	ReasonPhone     = "phone"
+	ReasonEmail     = "email"
+	// NIK yang diklaim saat pendaftaran mandiri sudah dipakai pasien lain
+	ReasonNIK = "nik"
+)
+
+// PatientSummary adalah ringkasan pasien untuk ditampilkan berdampingan saat review.
+type PatientSummary struct {
+	ID        uuid.UUID `json:"id"`
+	NIK       string    `json:"nik"`
+	FullName  string    `json:"full_name"`
+	BirthDate string    `json:"birth_date"`
+	Gender    string    `json:"gender"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Candidate adalah pasangan pasien yang kemungkinan orang yang sama.
+type Candidate struct {
+	ID         uuid.UUID      `json:"id"`
+	Patient    PatientSummary `json:"patient"`
+	Candidate  PatientSummary `json:"candidate"`
+	Score      float64        `json:"score"` // 0..1
+	Reasons    []string       `json:"reasons"`
+	Status     string         `json:"status"`
+	ReviewedBy *uuid.UUID     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// Merge adalah catatan audit penggabungan dua pasien. MergedSnapshot berisi
+// data pasien yang digabung sebelum merge; Moved berisi jumlah baris yang
+// dipindahkan per tabel.
+type Merge struct {
+	ID             uuid.UUID        `json:"id"`
+	SurvivorID     uuid.UUID        `json:"survivor_id"`
+	MergedID       uuid.UUID        `json:"merged_id"`
+	CandidateID    *uuid.UUID       `json:"candidate_id,omitempty"`
+	MergedSnapshot json.RawMessage  `json:"merged_snapshot"`
+	Moved          map[string]int64 `json:"moved"`
+	MergedBy       *uuid.UUID       `json:"merged_by,omitempty"`
+	MergedAt       time.Time        `json:"merged_at"`
+}
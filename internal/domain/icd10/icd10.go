@@ -0,0 +1,17 @@
+package icd10
+
+// Code adalah satu entri referensi ICD-10.
+type Code struct {
+	Code   string `json:"code"`
+	NameEN string `json:"name_en"`
+	NameID string `json:"name_id"`
+}
+
+// DiagnosisCount adalah jumlah konsultasi per kode diagnosis untuk laporan.
+type DiagnosisCount struct {
+	Code    string `json:"code"`
+	NameEN  string `json:"name_en"`
+	NameID  string `json:"name_id"`
+	Primary int64  `json:"primary"`
+	Total   int64  `json:"total"`
+}
@@ -12,6 +12,8 @@ type PhysicalExamination struct {
 	ParamedisID            *uuid.UUID `json:"paramedis_id,omitempty"`
 	DoctorID               *uuid.UUID `json:"doctor_id,omitempty"`
 	BloodPressure          string     `json:"blood_pressure,omitempty"`
+	Systolic               *int       `json:"systolic,omitempty"`
+	Diastolic              *int       `json:"diastolic,omitempty"`
 	HeartRate              *int       `json:"heart_rate,omitempty"`
 	OxygenSaturation       *int       `json:"oxygen_saturation,omitempty"`
 	RespiratoryRate        *int       `json:"respiratory_rate,omitempty"`
@@ -24,6 +26,7 @@ type PhysicalExamination struct {
 	KonsultasiDokter       bool       `json:"konsultasi_dokter,omitempty"`
 	KonsultasiDokterStatus string     `json:"konsultasi_dokter_status,omitempty"`
 	DoctorAdvice           string     `json:"doctor_advice,omitempty"`
+	AbnormalFlags          []string   `json:"abnormal_flags,omitempty"`
 	CreatedAt              time.Time  `json:"created_at"`
 	UpdatedAt              time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,69 @@
+// Package privacy berisi permintaan hak subjek data pasien sesuai UU PDP
+// (UU 27/2022): akses (ekspor seluruh data) dan penghapusan (anonimisasi).
+package privacy
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis permintaan
+const (
+	TypeExport  = "export"
+	TypeErasure = "erasure"
+)
+
+// Status permintaan
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+)
+
+// Request adalah satu permintaan ekspor atau penghapusan data pasien. Report
+// berisi manifest ekspor atau ErasureReport.
+type Request struct {
+	ID          uuid.UUID       `json:"id"`
+	PatientID   uuid.UUID       `json:"patient_id"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Reason      string          `json:"reason,omitempty"`
+	RequestedBy *uuid.UUID      `json:"requested_by,omitempty"`
+	FilePath    string          `json:"-"`
+	Report      json.RawMessage `json:"report,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Manifest adalah daftar isi file ZIP ekspor.
+type Manifest struct {
+	RequestID   uuid.UUID `json:"request_id"`
+	PatientID   uuid.UUID `json:"patient_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+	// Missing berisi file KTP yang tercatat tetapi tidak ditemukan di server.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// ErasureReport mencatat apa yang dihapus dan apa yang sengaja disimpan.
+type ErasureReport struct {
+	// PatientIDs berisi pasien yang dihapus beserta pasien yang pernah digabung ke dalamnya.
+	PatientIDs    []uuid.UUID      `json:"patient_ids"`
+	FieldsRemoved []string         `json:"fields_removed"`
+	FieldsKept    []string         `json:"fields_kept"`
+	Anonymized    map[string]int64 `json:"anonymized"` // jumlah baris per tabel
+	FilesDeleted  []string         `json:"files_deleted"`
+	FilesMissing  []string         `json:"files_missing,omitempty"`
+	Retained      []Retained       `json:"retained"`
+}
+
+// Retained adalah data yang tidak dihapus beserta dasar penyimpanannya.
+type Retained struct {
+	Table  string `json:"table"`
+	Rows   int64  `json:"rows"`
+	Reason string `json:"reason"`
+}
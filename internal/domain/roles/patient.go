@@ -7,29 +7,55 @@ import (
 )
 
 type Patient struct {
-	ID          uuid.UUID `json:"id"`
-	NIK         string    `json:"nik"`
-	FullName    string    `json:"full_name"`
-	BirthPlace  string    `json:"birth_place"`
-	BirthDate   string    `json:"birth_date"`
-	Gender      string    `json:"gender"`
-	Address     string    `json:"address"`
-	RT          string    `json:"rt"`
-	RW          string    `json:"rw"`
-	Village     string    `json:"village"`
-	District    string    `json:"district"`
-	Religion    string    `json:"religion"`
-	Marital     string    `json:"marital"`
-	Job         string    `json:"job"`
-	Nationality string    `json:"nationality"`
-	ValidUntil  string    `json:"valid_until"`
-	BloodType   string    `json:"blood_type"`
-	Height      int       `json:"height"`
-	Weight      int       `json:"weight"`
-	Age         int       `json:"age"`
-	Email       string    `json:"email"`
-	Phone       string    `json:"phone"`
-	KTPImages   []string  `json:"ktp_images,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uuid.UUID  `json:"id"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"` // akun login pasien, kosong untuk pasien walk-in
+	NIK         string     `json:"nik"`
+	FullName    string     `json:"full_name"`
+	BirthPlace  string     `json:"birth_place"`
+	BirthDate   string     `json:"birth_date"`
+	Gender      string     `json:"gender"`
+	Address     string     `json:"address"`
+	RT          string     `json:"rt"`
+	RW          string     `json:"rw"`
+	Village     string     `json:"village"`
+	District    string     `json:"district"`
+	Religion    string     `json:"religion"`
+	Marital     string     `json:"marital"`
+	Job         string     `json:"job"`
+	Nationality string     `json:"nationality"`
+	ValidUntil  string     `json:"valid_until"`
+	BloodType   string     `json:"blood_type"`
+	Height      int        `json:"height"`
+	Weight      int        `json:"weight"`
+	Age         int        `json:"age"`
+	Email       string     `json:"email"`
+	Phone       string     `json:"phone"`
+	KTPImages   []string   `json:"ktp_images,omitempty"`
+	MergedInto  *uuid.UUID `json:"merged_into,omitempty"` // diisi jika data sudah digabung ke pasien lain
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PatientSearch adalah kriteria pencarian pasien. Query dicocokkan dengan NIK
+// (sebagian), nama (fuzzy), telepon, email dan nomor MR; field lain adalah
+// filter. Field kosong atau nil diabaikan.
+type PatientSearch struct {
+	Query          string
+	Gender         string
+	AgeMin         *int
+	AgeMax         *int
+	BloodType      string
+	Village        string
+	District       string
+	RegisteredFrom *time.Time
+	RegisteredTo   *time.Time // eksklusif
+	Page           int
+	Limit          int
+}
+
+// PatientSearchResult adalah pasien hasil pencarian beserta skor relevansi (0..1).
+type PatientSearchResult struct {
+	Patient
+	MRNumber string  `json:"mr_number,omitempty"`
+	Score    float64 `json:"score"`
 }
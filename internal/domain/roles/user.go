@@ -1,6 +1,10 @@
 package roles
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	ID       uuid.UUID `json:"id"`
@@ -8,4 +12,16 @@ type User struct {
 	Password string    `json:"-"`
 	Role     string    `json:"role"`
 	Avatar   string    `json:"avatar,omitempty"`
+
+	// Penguncian akun setelah login gagal berulang
+	FailedLogins int        `json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+}
+
+// UserProfile adalah respons GET /me: data akun beserta profil sesuai role
+// (pasien, dokter, paramedis, admin, atau kasir). Profile kosong jika akun
+// belum punya profil.
+type UserProfile struct {
+	User
+	Profile interface{} `json:"profile,omitempty"`
 }
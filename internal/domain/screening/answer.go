@@ -34,10 +34,15 @@ type ScreeningAnswer struct {
 	ID          uuid.UUID    `json:"id"`
 	PatientInfo PatientInfo  `json:"patient_info"`
 	Answers     []AnswerItem `json:"answers"`
+	RiskLevel   string       `json:"risk_level"`
+	RiskFlags   []string     `json:"risk_flags"`
 	CreatedAt   time.Time    `json:"created_at"`
 }
 
+// AnswerItem adalah jawaban satu pertanyaan. Label hanya diisi saat dibaca
+// dari database, tidak disimpan.
 type AnswerItem struct {
 	QuestionID uuid.UUID   `json:"question_id"`
+	Label      string      `json:"label,omitempty"`
 	Answer     interface{} `json:"answer"`
 }
@@ -1,10 +1,41 @@
 package screening
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operator kondisi tampil pertanyaan screening
+const (
+	ConditionEquals   = "equals"
+	ConditionIncludes = "includes"
+	ConditionAnyOf    = "any_of"
+	ConditionLessThan = "lt"
+	ConditionMoreThan = "gt"
+)
+
+// QuestionNumber adalah tipe pertanyaan yang jawabannya wajib berupa angka,
+// sehingga bisa dipakai operator lt/gt.
+const QuestionNumber = "number"
 
 type ScreeningQuestion struct {
-	ID      uuid.UUID `json:"id"`
-	Label   string    `json:"label"`
-	Type    string    `json:"type"`
-	Options []string  `json:"options,omitempty"`
+	ID         uuid.UUID          `json:"id"`
+	Label      string             `json:"label"`
+	Type       string             `json:"type"`
+	Options    []string           `json:"options,omitempty"`
+	Position   int                `json:"position"`
+	Required   bool               `json:"required"`
+	Conditions []DisplayCondition `json:"conditions,omitempty"`
+	DeletedAt  *time.Time         `json:"deleted_at,omitempty"`
+}
+
+// DisplayCondition menentukan kapan sebuah pertanyaan ditampilkan berdasarkan
+// jawaban pertanyaan sebelumnya. Semua kondisi dalam satu pertanyaan harus
+// terpenuhi (AND).
+type DisplayCondition struct {
+	QuestionID uuid.UUID `json:"question_id"`
+	Operator   string    `json:"operator"` // equals, includes, any_of, lt, gt
+	Value      string    `json:"value,omitempty"`
+	Values     []string  `json:"values,omitempty"`
 }
@@ -6,11 +6,38 @@ import (
 	"github.com/google/uuid"
 )
 
+// Status antrean screening. Antrean baru selalu QueueWaiting; hanya antrean
+// waiting yang punya posisi.
+const (
+	QueueWaiting    = "waiting"
+	QueueInProgress = "in_progress"
+	QueueDone       = "done"
+)
+
 type ScreeningQueue struct {
 	ID                uuid.UUID   `json:"id"`
 	PatientInfo       PatientInfo `json:"patient_info"`
 	ScreeningAnswerID uuid.UUID   `json:"screening_answer_id"`
-	Status            string      `json:"status"` // waiting, in_progress, done
+	Status            string      `json:"status"`
+	RiskLevel         string      `json:"risk_level"`
+	RiskFlags         []string    `json:"risk_flags"`
+	NeedsDoctor       bool        `json:"needs_doctor"`
 	CreatedAt         time.Time   `json:"created_at"`
 	UpdatedAt         time.Time   `json:"updated_at"`
 }
+
+// QueueStat adalah ringkasan antrean aktif per status untuk metrik.
+// OldestCreatedAt nil jika tidak ada antrean dengan status tersebut.
+type QueueStat struct {
+	Status          string     `json:"status"`
+	Count           int64      `json:"count"`
+	OldestCreatedAt *time.Time `json:"oldest_created_at,omitempty"`
+}
+
+// QueuePosition adalah posisi antrean pasien saat ini. Ahead adalah jumlah
+// pasien waiting yang akan dipanggil lebih dulu.
+type QueuePosition struct {
+	Queue    ScreeningQueue `json:"queue"`
+	Ahead    int64          `json:"ahead"`
+	Position int64          `json:"position"`
+}
@@ -0,0 +1,35 @@
+package screening
+
+import "github.com/google/uuid"
+
+// Tingkat risiko pendaki hasil screening
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// RiskRule adalah aturan skoring yang dievaluasi terhadap jawaban screening.
+// Jika kondisi terpenuhi, Flag dicatat dan tingkat risiko minimal menjadi Level.
+type RiskRule struct {
+	ID        uuid.UUID        `json:"id"`
+	Flag      string           `json:"flag"`
+	Level     string           `json:"level"` // medium, high
+	Condition DisplayCondition `json:"condition"`
+}
+
+type RiskAssessment struct {
+	Level string   `json:"risk_level"`
+	Flags []string `json:"risk_flags"`
+}
+
+// RiskRank mengurutkan tingkat risiko, makin besar makin berisiko.
+func RiskRank(level string) int {
+	switch level {
+	case RiskHigh:
+		return 2
+	case RiskMedium:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package timeline
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis event pada timeline pasien
+const (
+	TypeMedicalRecord       = "medical_record"
+	TypeScreeningAnswer     = "screening_answer"
+	TypeQueue               = "queue"
+	TypePhysicalExamination = "physical_examination"
+	TypeConsultation        = "consultation"
+	TypeCertificate         = "certificate"
+)
+
+// Types berisi seluruh jenis event yang dikenal, sesuai urutan tampil di dokumentasi.
+var Types = []string{
+	TypeMedicalRecord,
+	TypeScreeningAnswer,
+	TypeQueue,
+	TypePhysicalExamination,
+	TypeConsultation,
+	TypeCertificate,
+}
+
+// Event adalah satu kejadian klinis pasien. Isi Payload bergantung pada Type
+// dan berupa ringkasan; detail lengkap diambil dari endpoint masing-masing.
+type Event struct {
+	Type       string          `json:"type"`
+	ID         uuid.UUID       `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
@@ -0,0 +1,254 @@
+// Package fieldcrypt mengenkripsi kolom data pribadi pasien dengan envelope
+// encryption: setiap nilai dienkripsi AES-256-GCM dengan data key acak, lalu
+// data key dibungkus dengan key encryption key (KEK) dari KeyProvider.
+// Nilai terenkripsi disimpan sebagai teks:
+//
+//	enc:v1:<key id>:<data key terbungkus, base64>:<nonce+ciphertext, base64>
+//
+// Karena ciphertext selalu berbeda, pencarian dengan nilai persis memakai
+// blind index (HMAC-SHA256 dari nilai yang dinormalisasi).
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Prefix menandai nilai terenkripsi; nilai tanpa prefix adalah plaintext lama.
+const Prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrMalformed  = errors.New("malformed encrypted value")
+)
+
+// KeyProvider menyimpan KEK. Implementasi lain (KMS, Vault) cukup memenuhi
+// interface ini; data key tidak pernah disimpan tanpa dibungkus.
+type KeyProvider interface {
+	// ActiveKeyID adalah KEK untuk enkripsi baru.
+	ActiveKeyID() string
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey mengembalikan ErrUnknownKey jika keyID tidak dikenal.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+	// IndexKey adalah kunci HMAC blind index. Kunci ini tidak dirotasi karena
+	// rotasi berarti menghitung ulang semua index.
+	IndexKey() []byte
+}
+
+type Cipher struct {
+	keys KeyProvider
+}
+
+func NewCipher(keys KeyProvider) *Cipher {
+	return &Cipher{keys: keys}
+}
+
+// Encrypt mengenkripsi plaintext dengan KEK aktif. String kosong tetap kosong
+// agar kolom opsional bisa dibedakan dari kolom yang terisi.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	sealed, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	keyID := c.keys.ActiveKeyID()
+	wrapped, err := c.keys.WrapKey(keyID, dataKey)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return Prefix + keyID + ":" + enc.EncodeToString(wrapped) + ":" + enc.EncodeToString(sealed), nil
+}
+
+// Decrypt membuka nilai dari Encrypt. Nilai tanpa prefix dianggap plaintext
+// lama yang belum dienkripsi dan dikembalikan apa adanya.
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, Prefix) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", ErrMalformed
+	}
+	enc := base64.RawStdEncoding
+	wrapped, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	sealed, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	dataKey, err := c.keys.UnwrapKey(parts[0], wrapped)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation bernilai true untuk plaintext lama atau nilai yang dienkripsi
+// dengan KEK selain yang aktif.
+func (c *Cipher) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !strings.HasPrefix(value, Prefix) {
+		return true
+	}
+	return !strings.HasPrefix(value, Prefix+c.keys.ActiveKeyID()+":")
+}
+
+// EncryptJSON mengenkripsi v dalam bentuk JSON. Hasilnya berupa string JSON
+// sehingga tetap bisa disimpan di kolom JSONB.
+func (c *Cipher) EncryptJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.Encrypt(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// DecryptJSON membaca hasil EncryptJSON ke v. Objek JSON yang belum
+// dienkripsi dibaca langsung.
+func (c *Cipher) DecryptJSON(data []byte, v interface{}) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return json.Unmarshal(data, v)
+	}
+	if value == "" {
+		return nil
+	}
+	plaintext, err := c.Decrypt(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(plaintext), v)
+}
+
+// NeedsRotationJSON sama dengan NeedsRotation untuk kolom dari EncryptJSON.
+func (c *Cipher) NeedsRotationJSON(data []byte) bool {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return len(data) > 0 && string(data) != "null"
+	}
+	return c.NeedsRotation(value)
+}
+
+// NIKIndex adalah blind index NIK. String kosong untuk NIK kosong.
+func (c *Cipher) NIKIndex(nik string) string {
+	return c.index("nik", digits(nik))
+}
+
+// PhoneIndex menyamakan 08xx, +628xx dan 628xx sebelum di-hash.
+func (c *Cipher) PhoneIndex(phone string) string {
+	d := digits(phone)
+	if strings.HasPrefix(d, "62") {
+		d = d[2:]
+	} else {
+		d = strings.TrimPrefix(d, "0")
+	}
+	return c.index("phone", d)
+}
+
+// NIKSuffixIndex dan PhoneSuffixIndex adalah blind index 4 digit terakhir
+// untuk pencarian sebagian di front desk. Entropinya rendah sehingga hanya
+// dipakai untuk pencarian, tidak untuk keunikan atau pencocokan identitas.
+func (c *Cipher) NIKSuffixIndex(nik string) string {
+	return c.index("nik_last4", last4(digits(nik)))
+}
+
+func (c *Cipher) PhoneSuffixIndex(phone string) string {
+	return c.index("phone_last4", last4(digits(phone)))
+}
+
+func last4(d string) string {
+	if len(d) > 4 {
+		return d[len(d)-4:]
+	}
+	return d
+}
+
+// EmailIndex tidak membedakan huruf besar dan kecil.
+func (c *Cipher) EmailIndex(email string) string {
+	return c.index("email", strings.ToLower(strings.TrimSpace(email)))
+}
+
+// index memakai nama field sebagai domain agar nilai yang sama di field
+// berbeda tidak menghasilkan index yang sama.
+func (c *Cipher) index(field, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, c.keys.IndexKey())
+	mac.Write([]byte(field + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// seal mengenkripsi dengan AES-256-GCM; nonce diletakkan di depan ciphertext.
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
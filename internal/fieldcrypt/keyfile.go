@@ -0,0 +1,159 @@
+package fieldcrypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// keyFile adalah format file kunci lokal:
+//
+//	{"active": "20260101T000000-a1b2c3", "keys": {"20260101T000000-a1b2c3": "<32 byte base64>"}, "index_key": "<32 byte base64>"}
+//
+// Kunci lama tetap disimpan di "keys" sampai semua data selesai dienkripsi
+// ulang dengan kunci aktif.
+type keyFile struct {
+	Active   string            `json:"active"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LocalKeyProvider membaca KEK dari file JSON di server. File harus dibatasi
+// hak aksesnya (0600) dan tidak ikut di-backup bersama database.
+type LocalKeyProvider struct {
+	active string
+	keys   map[string][]byte
+	index  []byte
+}
+
+func LoadKeyFile(path string) (*LocalKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("key file: %w", err)
+	}
+	p := &LocalKeyProvider{active: f.Active, keys: map[string][]byte{}}
+	for id, encoded := range f.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("key file: invalid key id %q", id)
+		}
+		if p.keys[id], err = decodeKey(encoded); err != nil {
+			return nil, fmt.Errorf("key file: key %q: %w", id, err)
+		}
+	}
+	if _, ok := p.keys[f.Active]; !ok {
+		return nil, errors.New("key file: active key not found")
+	}
+	if p.index, err = decodeKey(f.IndexKey); err != nil {
+		return nil, fmt.Errorf("key file: index_key: %w", err)
+	}
+	return p, nil
+}
+
+// GenerateKeyFile membuat file kunci baru; gagal jika file sudah ada.
+func GenerateKeyFile(path string) error {
+	id := newKeyID()
+	f := keyFile{Active: id, Keys: map[string]string{id: randomKey()}, IndexKey: randomKey()}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// RotateKeyFile menambah KEK baru dan menjadikannya aktif. Kunci lama tidak
+// dihapus; hapus manual setelah enkripsi ulang selesai.
+func RotateKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return "", fmt.Errorf("key file: %w", err)
+	}
+	id := newKeyID()
+	if _, ok := f.Keys[id]; ok {
+		return "", errors.New("key file: key id already exists")
+	}
+	f.Keys[id] = randomKey()
+	f.Active = id
+	if data, err = json.MarshalIndent(f, "", "  "); err != nil {
+		return "", err
+	}
+	// Tulis ke file sementara dulu agar file kunci tidak rusak jika gagal di tengah
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return "", err
+	}
+	return id, os.Rename(tmp, path)
+}
+
+func (p *LocalKeyProvider) ActiveKeyID() string {
+	return p.active
+}
+
+func (p *LocalKeyProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return seal(kek, dataKey)
+}
+
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return open(kek, wrapped)
+}
+
+func (p *LocalKeyProvider) IndexKey() []byte {
+	return p.index
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, errors.New("key must be 32 bytes")
+	}
+	return key, nil
+}
+
+func randomKey() string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// newKeyID memakai waktu pembuatan agar urutan kunci mudah dibaca.
+func newKeyID() string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		panic(err)
+	}
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,133 @@
+// Package health menjalankan pengecekan kesiapan server untuk /readyz.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"v2/internal/fieldcrypt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok atau fail
+}
+
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker membuat Checker; setiap pengecekan dibatasi timeout.
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Ready menjalankan semua pengecekan secara paralel. Detail error hanya
+// dicatat di log karena /readyz bisa diakses tanpa login.
+func (c *Checker) Ready(ctx context.Context) (bool, []Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = Result{Name: check.Name, Status: "ok"}
+			if err := check.Run(ctx); err != nil {
+				slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "err", err)
+				results[i].Status = "fail"
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, r := range results {
+		ready = ready && r.Status == "ok"
+	}
+	return ready, results
+}
+
+func Database(db *pgxpool.Pool) Check {
+	return Check{Name: "database", Run: db.Ping}
+}
+
+// PendingLister dipenuhi oleh *migrate.Migrator.
+type PendingLister interface {
+	Pending(ctx context.Context) ([]string, error)
+}
+
+func Migrations(m PendingLister) Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) error {
+		pending, err := m.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("pending: %s", strings.Join(pending, ", "))
+		}
+		return nil
+	}}
+}
+
+// Directory memastikan folder ada dan bisa ditulisi.
+func Directory(name, dir string) Check {
+	return Check{Name: name, Run: func(ctx context.Context) error {
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return err
+		}
+		f.Close()
+		return os.Remove(f.Name())
+	}}
+}
+
+// pendingEncryptionQuery menghitung baris yang masih plaintext atau belum
+// punya blind index. Hanya mengecek bentuk data sehingga tidak butuh kunci.
+const pendingEncryptionQuery = `SELECT
+	(SELECT count(*) FROM patients WHERE
+		(nik <> '' AND nik NOT LIKE $1 || '%') OR (phone <> '' AND phone NOT LIKE $1 || '%')
+		OR (email <> '' AND email NOT LIKE $1 || '%') OR (address <> '' AND address NOT LIKE $1 || '%')
+		OR (nik <> '' AND (nik_bidx IS NULL OR nik_last4_bidx IS NULL))
+		OR (phone <> '' AND (phone_bidx IS NULL OR phone_last4_bidx IS NULL))
+		OR (email <> '' AND email_bidx IS NULL))
+	+ (SELECT count(*) FROM screening_answers WHERE jsonb_typeof(patient_info) = 'object')
+	+ (SELECT count(*) FROM screening_queues WHERE jsonb_typeof(patient_info) = 'object')
+	+ (SELECT count(*) FROM certificates WHERE
+		(patient_name <> '' AND patient_name NOT LIKE $1 || '%') OR (patient_nik <> '' AND patient_nik NOT LIKE $1 || '%'))
+	+ (SELECT count(*) FROM users WHERE totp_secret <> '' AND totp_secret NOT LIKE $1 || '%')`
+
+// PendingEncryption mengembalikan jumlah baris yang harus diproses
+// cmd/reencrypt sebelum server boleh melayani request.
+func PendingEncryption(ctx context.Context, db *pgxpool.Pool) (int64, error) {
+	var n int64
+	err := db.QueryRow(ctx, pendingEncryptionQuery, fieldcrypt.Prefix).Scan(&n)
+	return n, err
+}
+
+func FieldEncryption(db *pgxpool.Pool) Check {
+	return Check{Name: "field_encryption", Run: func(ctx context.Context) error {
+		n, err := PendingEncryption(ctx, db)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return fmt.Errorf("%d rows pending, run cmd/reencrypt", n)
+		}
+		return nil
+	}}
+}
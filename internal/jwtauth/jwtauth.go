@@ -0,0 +1,181 @@
+// Package jwtauth menerbitkan dan memverifikasi JWT login.
+//
+// HS256 memakai satu secret bersama. RS256 dan EdDSA memakai kunci privat
+// di folder kunci; setiap file <kid>.pem adalah satu kunci dan kid ditulis di
+// header token sehingga beberapa kunci bisa berlaku bersamaan selama rotasi.
+// Kunci publiknya dipublikasikan lewat JWKS agar layanan lain bisa
+// memverifikasi token tanpa mengetahui secret.
+package jwtauth
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma yang didukung
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Settings dibaca dari config.
+type Settings struct {
+	Algorithm string // default HS256
+	// Secret wajib untuk HS256. Untuk RS256/EdDSA boleh diisi sementara agar
+	// token HS256 lama tetap valid sampai kedaluwarsa.
+	Secret string
+	// KeysDir berisi <kid>.pem untuk RS256/EdDSA. File kunci publik saja
+	// dipakai untuk verifikasi, tidak untuk menandatangani.
+	KeysDir string
+	// ActiveKeyID adalah kid yang dipakai menandatangani; default kid terbesar
+	// (kid dari cmd/jwtkey berawalan waktu pembuatan sehingga kid terbesar
+	// adalah kunci terbaru).
+	ActiveKeyID string
+	Issuer      string
+	TTL         time.Duration
+}
+
+type key struct {
+	id      string
+	alg     string
+	private crypto.Signer // nil untuk kunci yang hanya dipakai verifikasi
+	public  crypto.PublicKey
+}
+
+type Manager struct {
+	secret []byte
+	keys   map[string]*key
+	active *key // nil berarti HS256
+	issuer string
+	ttl    time.Duration
+}
+
+// New gagal jika secret atau kunci untuk algoritma yang dipilih tidak ada,
+// sehingga server tidak pernah berjalan dengan secret kosong.
+func New(s Settings) (*Manager, error) {
+	m := &Manager{secret: []byte(s.Secret), keys: map[string]*key{}, issuer: s.Issuer, ttl: s.TTL}
+	if m.ttl <= 0 {
+		return nil, errors.New("jwt: token lifetime must be positive")
+	}
+	if s.KeysDir != "" {
+		keys, err := loadKeyDir(s.KeysDir)
+		if err != nil {
+			return nil, err
+		}
+		m.keys = keys
+	}
+
+	switch s.Algorithm {
+	case "", HS256:
+		if s.Secret == "" {
+			return nil, errors.New("jwt: JWT_SECRET is required for HS256")
+		}
+		if len(s.Secret) < 32 {
+			slog.Warn("JWT_SECRET is shorter than 32 bytes; use a longer random secret")
+		}
+	case RS256, EdDSA:
+		k, err := activeKey(m.keys, s.ActiveKeyID)
+		if err != nil {
+			return nil, err
+		}
+		if k.alg != s.Algorithm {
+			return nil, fmt.Errorf("jwt: active key %q is %s, not %s", k.id, k.alg, s.Algorithm)
+		}
+		m.active = k
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", s.Algorithm)
+	}
+	return m, nil
+}
+
+func activeKey(keys map[string]*key, id string) (*key, error) {
+	if id == "" {
+		for kid, k := range keys {
+			if k.private != nil && kid > id {
+				id = kid
+			}
+		}
+		if id == "" {
+			return nil, errors.New("jwt: no private key found in JWT_KEYS_DIR")
+		}
+	}
+	k, ok := keys[id]
+	if !ok {
+		return nil, fmt.Errorf("jwt: active key %q not found", id)
+	}
+	if k.private == nil {
+		return nil, fmt.Errorf("jwt: active key %q has no private key", id)
+	}
+	return k, nil
+}
+
+// Algorithm mengembalikan algoritma yang dipakai menandatangani.
+func (m *Manager) Algorithm() string {
+	if m.active == nil {
+		return HS256
+	}
+	return m.active.alg
+}
+
+func (m *Manager) Generate(userID, role string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"iat":     now.Unix(),
+		"exp":     now.Add(m.ttl).Unix(),
+	}
+	if m.issuer != "" {
+		claims["iss"] = m.issuer
+	}
+	if m.active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(m.active.alg), claims)
+	token.Header["kid"] = m.active.id
+	return token.SignedString(m.active.private)
+}
+
+// Parse memverifikasi token. Kunci dipilih dari kid dan algoritma di header
+// harus sama dengan jenis kuncinya agar kunci publik tidak bisa dipakai
+// sebagai secret HMAC.
+func (m *Manager) Parse(tokenStr string) (jwt.MapClaims, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{HS256, RS256, EdDSA}), jwt.WithExpirationRequired()}
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	token, err := jwt.Parse(tokenStr, m.keyFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, jwt.ErrTokenMalformed
+}
+
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if token.Method.Alg() != HS256 || len(m.secret) == 0 {
+			return nil, ErrUnknownKey
+		}
+		return m.secret, nil
+	}
+	k, ok := m.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != k.alg {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return k.public, nil
+}
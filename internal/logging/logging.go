@@ -0,0 +1,75 @@
+// Package logging menyiapkan slog untuk server: format JSON atau teks,
+// request ID dari context di setiap log, dan penyamaran data sensitif
+// (password, NIK, token) sebelum ditulis.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"v2/internal/tracing"
+)
+
+// RequestIDKey adalah key request ID di context. Middleware RequestID
+// menyimpannya di Locals Fiber sehingga c.Context() yang diteruskan handler
+// ke usecase dan repository ikut membawanya.
+type RequestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(RequestIDKey{}).(string)
+	return id
+}
+
+// New membuat logger. format "json" atau "text"; level debug, info, warn
+// atau error.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("logging: level %q: %w", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: redactAttr}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q", format)
+	}
+	return slog.New(&contextHandler{Handler: h}), nil
+}
+
+// contextHandler menambahkan request_id (dan trace_id jika tracing aktif) dari context sehingga pemanggil cukup
+// memakai slog.InfoContext(ctx, ...) tanpa meneruskan ID secara manual.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.Message = redactText(r.Message)
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := tracing.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
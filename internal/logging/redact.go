@@ -0,0 +1,114 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys disamarkan jika nama key sama persis (huruf kecil). Key yang
+// memuat "password", "secret" atau "token" selalu disamarkan.
+var sensitiveKeys = map[string]bool{
+	"nik":            true,
+	"authorization":  true,
+	"cookie":         true,
+	"otp":            true,
+	"mfa_code":       true,
+	"recovery_code":  true,
+	"recovery_codes": true,
+	"email":          true,
+	"phone":          true,
+	"address":        true,
+	"patient_info":   true,
+}
+
+var sensitiveParts = []string{"password", "secret", "token"}
+
+// nikPattern menangkap NIK yang ikut tertulis di pesan atau error, misalnya
+// dari validasi atau constraint database.
+var nikPattern = regexp.MustCompile(`\b\d{16}\b`)
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] || strings.HasSuffix(key, "_nik") {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactText(s string) string {
+	return nikPattern.ReplaceAllString(s, Redacted)
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && isSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	a.Value = redactValue(a.Value)
+	return a
+}
+
+func redactValue(v slog.Value) slog.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.StringValue(redactText(v.String()))
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.StringValue(redactText(x.Error()))
+		case []byte:
+			return slog.StringValue(redactText(string(x)))
+		}
+		return redactAny(v)
+	}
+	return v
+}
+
+// redactAny menyamarkan key sensitif di map dan struct (mengikuti tag json)
+// dengan mengubahnya ke bentuk JSON terlebih dahulu.
+func redactAny(v slog.Value) slog.Value {
+	rv := reflect.Indirect(reflect.ValueOf(v.Any()))
+	switch rv.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+	default:
+		return v
+	}
+	data, err := json.Marshal(v.Any())
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	return slog.AnyValue(redactJSON(generic))
+}
+
+func redactJSON(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, item := range x {
+			if isSensitive(k) {
+				x[k] = Redacted
+			} else {
+				x[k] = redactJSON(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range x {
+			x[i] = redactJSON(item)
+		}
+	case string:
+		return redactText(x)
+	}
+	return v
+}
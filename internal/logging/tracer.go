@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	sql   string
+	start time.Time
+}
+
+// QueryTracer mencatat query yang gagal atau lebih lambat dari SlowThreshold
+// sebagai warning, dan semua query di level debug, lengkap dengan request_id.
+// Argumen query tidak pernah dicatat karena bisa berisi data pasien.
+type QueryTracer struct {
+	SlowThreshold time.Duration // 0 berarti query lambat tidak ditandai
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{sql: data.SQL, start: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(q.start)
+	attrs := []any{"sql", q.sql, "duration_ms", elapsed.Milliseconds()}
+	switch {
+	case data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) && !errors.Is(data.Err, context.Canceled):
+		slog.WarnContext(ctx, "query failed", append(attrs, "err", data.Err)...)
+	case t.SlowThreshold > 0 && elapsed >= t.SlowThreshold:
+		slog.WarnContext(ctx, "slow query", attrs...)
+	default:
+		slog.DebugContext(ctx, "query", attrs...)
+	}
+}
@@ -0,0 +1,60 @@
+// Package mailer mengirim email notifikasi ke pasien. Tanpa SMTP yang
+// dikonfigurasi, email hanya dicatat di log tanpa isi pesan karena isi bisa
+// memuat kredensial.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"v2/internal/config"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// New memilih SMTP jika smtp_host diisi, selain itu LogMailer.
+func New(cfg config.EmailConfig) Mailer {
+	if cfg.SMTPHost == "" {
+		return LogMailer{}
+	}
+	return &SMTPMailer{cfg: cfg}
+}
+
+type SMTPMailer struct {
+	cfg config.EmailConfig
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	addr := net.JoinHostPort(m.cfg.SMTPHost, strconv.Itoa(m.cfg.SMTPPort))
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.SMTPHost)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.cfg.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("mailer: send: %w", err)
+	}
+	return nil
+}
+
+// LogMailer hanya mencatat subjek; penerima dan isi pesan tidak dicatat.
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	slog.InfoContext(ctx, "email not sent, SMTP not configured", "subject", subject)
+	return nil
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"v2/internal/domain/screening"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueSize  = prometheus.NewDesc(namespace+"_screening_queue_size", "Jumlah antrean screening aktif per status.", []string{"status"}, nil)
+	queueWait  = prometheus.NewDesc(namespace+"_screening_queue_oldest_wait_seconds", "Lama tunggu antrean tertua per status.", []string{"status"}, nil)
+	outOfStock = prometheus.NewDesc(namespace+"_medicines_out_of_stock", "Jumlah obat aktif dengan stok habis.", nil, nil)
+)
+
+type QueueStatter interface {
+	Stats(ctx context.Context) ([]screening.QueueStat, error)
+}
+
+type StockCounter interface {
+	CountOutOfStock(ctx context.Context) (int64, error)
+}
+
+// ClinicCollector membaca kondisi antrean dan stok dari database setiap kali
+// /metrics di-scrape. Gagal query hanya dicatat di log; metrik terkait tidak
+// dikirim pada scrape tersebut.
+type ClinicCollector struct {
+	queue   QueueStatter
+	stock   StockCounter
+	timeout time.Duration
+}
+
+func NewClinicCollector(queue QueueStatter, stock StockCounter) *ClinicCollector {
+	return &ClinicCollector{queue: queue, stock: stock, timeout: 5 * time.Second}
+}
+
+func (c *ClinicCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueSize
+	ch <- queueWait
+	ch <- outOfStock
+}
+
+func (c *ClinicCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if stats, err := c.queue.Stats(ctx); err != nil {
+		slog.WarnContext(ctx, "metrics: queue stats failed", "err", err)
+	} else {
+		now := time.Now()
+		for _, status := range []string{screening.QueueWaiting, screening.QueueInProgress} {
+			var count int64
+			var wait float64
+			for _, s := range stats {
+				if s.Status == status {
+					count = s.Count
+					if s.OldestCreatedAt != nil {
+						wait = now.Sub(*s.OldestCreatedAt).Seconds()
+					}
+				}
+			}
+			ch <- prometheus.MustNewConstMetric(queueSize, prometheus.GaugeValue, float64(count), status)
+			ch <- prometheus.MustNewConstMetric(queueWait, prometheus.GaugeValue, wait, status)
+		}
+	}
+
+	if n, err := c.stock.CountOutOfStock(ctx); err != nil {
+		slog.WarnContext(ctx, "metrics: out of stock count failed", "err", err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(outOfStock, prometheus.GaugeValue, float64(n))
+	}
+}
@@ -0,0 +1,96 @@
+// Package metrics menyediakan metrik Prometheus untuk /metrics: request HTTP,
+// pool dan query database, serta kejadian operasional klinik untuk dashboard.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "klinik"
+
+// Registry terpisah dari prometheus.DefaultRegisterer agar isi /metrics
+// hanya metrik yang didaftarkan di sini.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah request HTTP per route dan status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durasi request HTTP per route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durasi query SQL per method repository.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"repository", "method", "status"})
+
+	ScreeningsSubmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "screenings_submitted_total",
+		Help:      "Jumlah jawaban screening yang dikirim per tingkat risiko.",
+	}, []string{"risk_level"})
+
+	Examinations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "physical_examinations_total",
+		Help:      "Jumlah pemeriksaan fisik saat status kesehatan diisi atau diubah.",
+	}, []string{"health_status"})
+
+	CertificatesIssued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "certificates_issued_total",
+		Help:      "Jumlah sertifikat sehat yang diterbitkan per keputusan.",
+	}, []string{"decision"})
+
+	MedicineStockouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "medicine_stockouts_total",
+		Help:      "Jumlah kejadian stok obat diubah menjadi habis.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, QueryDuration,
+		ScreeningsSubmitted, Examinations, CertificatesIssued, MedicineStockouts,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// orUnknown dipakai untuk label yang nilainya bisa kosong.
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func ScreeningSubmitted(riskLevel string) {
+	ScreeningsSubmitted.WithLabelValues(orUnknown(riskLevel)).Inc()
+}
+
+func ExaminationAssessed(healthStatus string) {
+	Examinations.WithLabelValues(orUnknown(healthStatus)).Inc()
+}
+
+func CertificateIssued(decision string) {
+	CertificatesIssued.WithLabelValues(decision).Inc()
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolAcquired = prometheus.NewDesc(namespace+"_db_pool_acquired_conns", "Koneksi yang sedang dipakai.", nil, nil)
+	poolIdle     = prometheus.NewDesc(namespace+"_db_pool_idle_conns", "Koneksi idle.", nil, nil)
+	poolTotal    = prometheus.NewDesc(namespace+"_db_pool_total_conns", "Total koneksi di pool.", nil, nil)
+	poolMax      = prometheus.NewDesc(namespace+"_db_pool_max_conns", "Batas koneksi pool.", nil, nil)
+	poolAcquires = prometheus.NewDesc(namespace+"_db_pool_acquires_total", "Jumlah koneksi diambil dari pool.", nil, nil)
+	poolEmpty    = prometheus.NewDesc(namespace+"_db_pool_empty_acquires_total", "Pengambilan koneksi yang harus menunggu karena pool kosong.", nil, nil)
+	poolCanceled = prometheus.NewDesc(namespace+"_db_pool_canceled_acquires_total", "Pengambilan koneksi yang dibatalkan context.", nil, nil)
+	poolWait     = prometheus.NewDesc(namespace+"_db_pool_acquire_wait_seconds_total", "Total waktu menunggu koneksi.", nil, nil)
+)
+
+// PoolCollector membaca pgxpool.Stat setiap kali /metrics di-scrape.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{pool: pool}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{poolAcquired, poolIdle, poolTotal, poolMax, poolAcquires, poolEmpty, poolCanceled, poolWait} {
+		ch <- d
+	}
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(poolTotal, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmpty, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolWait, prometheus.CounterValue, s.AcquireDuration().Seconds())
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+	"v2/internal/dbcaller"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	repository, method string
+	start              time.Time
+}
+
+// QueryTracer mengukur durasi setiap query dan memberi label method
+// repository pemanggilnya.
+type QueryTracer struct{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	repo, method := dbcaller.Repository()
+	return context.WithValue(ctx, queryStartKey{}, queryStart{repository: repo, method: method, start: time.Now()})
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	status := "ok"
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		status = "error"
+	}
+	QueryDuration.WithLabelValues(q.repository, q.method, status).Observe(time.Since(q.start).Seconds())
+}
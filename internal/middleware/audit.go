@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"v2/internal/domain/audit"
+	auditusecase "v2/internal/usecase/audit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditRequest menyimpan info request ke context agar usecase dapat mencatat
+// IP dan endpoint pada audit log. Pasang sekali di grup API.
+func AuditRequest() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(audit.RequestKey{}, audit.Request{
+			IP:        c.IP(),
+			UserAgent: c.Get(fiber.HeaderUserAgent),
+			Method:    c.Method(),
+			Path:      c.Path(),
+		})
+		return c.Next()
+	}
+}
+
+// AuditRead mencatat akses baca data pasien yang berhasil. ID entitas diambil
+// dari parameter :id atau :number, atau query patient_id/nik.
+func AuditRead(rec auditusecase.Recorder, entity string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest {
+			return nil
+		}
+		id := c.Params("id")
+		if id == "" {
+			id = c.Params("number")
+		}
+		if id == "" {
+			id = c.Query("patient_id", c.Query("nik"))
+		}
+		rec.Record(c.Context(), audit.ActionRead, entity, id, nil, nil)
+		return nil
+	}
+}
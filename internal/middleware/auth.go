@@ -2,19 +2,24 @@ package middleware
 
 import (
 	"strings"
-	"v2/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware() fiber.Handler {
+// TokenParser memverifikasi JWT login; diimplementasikan jwtauth.Manager.
+type TokenParser interface {
+	Parse(tokenStr string) (jwt.MapClaims, error)
+}
+
+func AuthMiddleware(tokens TokenParser) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		header := c.Get("Authorization")
 		if header == "" || !strings.HasPrefix(header, "Bearer ") {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "missing or invalid authorization header"})
 		}
 		tokenStr := strings.TrimPrefix(header, "Bearer ")
-		claims, err := utils.ParseJWT(tokenStr)
+		claims, err := tokens.Parse(tokenStr)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
 		}
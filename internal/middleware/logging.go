@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+	"v2/internal/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// ID dari klien/proxy hanya dipakai jika aman ditulis ke log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID memberi setiap request ID (dari header X-Request-ID atau UUID
+// baru), mengembalikannya di header respons, dan menyimpannya di context
+// agar ikut tercatat di log usecase dan repository.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Locals(logging.RequestIDKey{}, id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		c.Set(HeaderRequestID, id)
+		return c.Next()
+	}
+}
+
+var quietPaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// AccessLog mencatat satu baris per request setelah respons selesai. Query
+// string tidak dicatat karena bisa memuat NIK (misalnya ?nik=).
+func AccessLog() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		if err != nil {
+			// Panggil error handler di sini agar status yang dicatat sesuai
+			// respons yang diterima klien
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case quietPaths[c.Path()]:
+			// Probe orchestrator datang setiap beberapa detik
+			level = slog.LevelDebug
+		}
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"route", c.Route().Path,
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", len(c.Response().Body()),
+			"ip", c.IP(),
+		}
+		if userID, ok := c.Locals("user_id").(string); ok {
+			attrs = append(attrs, "user_id", userID)
+		}
+		if err != nil {
+			attrs = append(attrs, "err", err)
+		}
+		slog.Log(c.Context(), level, "request", attrs...)
+		return nil
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+	"v2/internal/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics mencatat jumlah dan durasi request per pola route (misalnya
+// /api/v1/patients/:id), bukan path asli, agar jumlah label tetap terbatas.
+// Dipasang setelah AccessLog sehingga error sudah diubah menjadi status.
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+		metrics.HTTPRequests.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Method(), route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import "github.com/gofiber/fiber/v2"
+
+// RoleOnly membatasi akses hanya untuk role yang disebutkan.
+func RoleOnly(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("role").(string)
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "forbidden"})
+	}
+}
@@ -0,0 +1,132 @@
+// Package migrate menerapkan file SQL di folder migrations secara berurutan
+// dan mencatatnya di tabel schema_migrations.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version TEXT PRIMARY KEY,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`
+
+type Migrator struct {
+	db    *pgxpool.Pool
+	files fs.FS
+}
+
+func New(db *pgxpool.Pool, files fs.FS) *Migrator {
+	return &Migrator{db: db, files: files}
+}
+
+// Versions mengembalikan nama file migrasi (tanpa .sql) terurut.
+func (m *Migrator) Versions() ([]string, error) {
+	names, err := fs.Glob(m.files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(names))
+	for _, name := range names {
+		versions = append(versions, strings.TrimSuffix(path.Base(name), ".sql"))
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// Pending mengembalikan migrasi yang belum tercatat. Tanpa tabel
+// schema_migrations semua migrasi dianggap belum diterapkan.
+func (m *Migrator) Pending(ctx context.Context) ([]string, error) {
+	versions, err := m.Versions()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []string
+	for _, v := range versions {
+		if !applied[v] {
+			pending = append(pending, v)
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[string]bool, error) {
+	var exists bool
+	if err := m.db.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[string]bool{}
+	if !exists {
+		return applied, nil
+	}
+	rows, err := m.db.Query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// Up menerapkan semua migrasi yang tertunda, masing-masing dalam satu
+// transaksi, dan mengembalikan versi yang diterapkan.
+func (m *Migrator) Up(ctx context.Context) ([]string, error) {
+	return m.run(ctx, true)
+}
+
+// Baseline mencatat semua migrasi sebagai sudah diterapkan tanpa
+// menjalankannya, untuk database yang dimigrasi manual sebelum ada
+// schema_migrations.
+func (m *Migrator) Baseline(ctx context.Context) ([]string, error) {
+	return m.run(ctx, false)
+}
+
+func (m *Migrator) run(ctx context.Context, execute bool) ([]string, error) {
+	if _, err := m.db.Exec(ctx, createTable); err != nil {
+		return nil, err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []string
+	for _, version := range pending {
+		err := pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+			if execute {
+				sql, err := fs.ReadFile(m.files, version+".sql")
+				if err != nil {
+					return err
+				}
+				// Tanpa argumen pgx memakai simple protocol sehingga satu file
+				// boleh berisi banyak statement
+				if _, err := tx.Exec(ctx, string(sql)); err != nil {
+					return err
+				}
+			}
+			_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s: %w", version, err)
+		}
+		done = append(done, version)
+	}
+	return done, nil
+}
@@ -0,0 +1,173 @@
+// Package nik mengurai Nomor Induk Kependudukan (NIK) 16 digit:
+//
+//	PP RR DD TTBBYY SSSS
+//
+// PP kode provinsi, RR kode kabupaten/kota, DD kode kecamatan, TTBBYY tanggal
+// lahir (tanggal ditambah 40 untuk perempuan) dan SSSS nomor urut.
+package nik
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid NIK")
+
+// Jenis kelamin mengikuti penulisan di KTP.
+const (
+	GenderMale   = "Laki-laki"
+	GenderFemale = "Perempuan"
+)
+
+// Info adalah hasil penguraian NIK.
+type Info struct {
+	NIK          string    `json:"nik"`
+	ProvinceCode string    `json:"province_code"`
+	Province     string    `json:"province"`
+	RegencyCode  string    `json:"regency_code"`  // 4 digit: provinsi + kabupaten/kota
+	DistrictCode string    `json:"district_code"` // 6 digit: provinsi + kabupaten/kota + kecamatan
+	BirthDate    time.Time `json:"birth_date"`
+	Gender       string    `json:"gender"`
+	Serial       string    `json:"serial"`
+}
+
+// Parse memvalidasi struktur NIK dan mengambil data yang terkandung di dalamnya.
+// now dipakai untuk menentukan abad tahun lahir.
+func Parse(s string, now time.Time) (*Info, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 16 {
+		return nil, fmt.Errorf("%w: must be 16 digits", ErrInvalid)
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("%w: must be 16 digits", ErrInvalid)
+		}
+	}
+	province, ok := provinces[s[0:2]]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown province code %s", ErrInvalid, s[0:2])
+	}
+	if s[2:4] == "00" {
+		return nil, fmt.Errorf("%w: regency code cannot be 00", ErrInvalid)
+	}
+	if s[4:6] == "00" {
+		return nil, fmt.Errorf("%w: district code cannot be 00", ErrInvalid)
+	}
+	if s[12:16] == "0000" {
+		return nil, fmt.Errorf("%w: serial number cannot be 0000", ErrInvalid)
+	}
+
+	day, _ := strconv.Atoi(s[6:8])
+	month, _ := strconv.Atoi(s[8:10])
+	yy, _ := strconv.Atoi(s[10:12])
+	gender := GenderMale
+	if day > 40 {
+		day -= 40
+		gender = GenderFemale
+	}
+	// Tahun dua digit: anggap abad ini kecuali hasilnya di masa depan
+	year := now.Year()/100*100 + yy
+	if year > now.Year() {
+		year -= 100
+	}
+	birth := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if day < 1 || month < 1 || month > 12 || birth.Day() != day || birth.Month() != time.Month(month) {
+		return nil, fmt.Errorf("%w: invalid birth date", ErrInvalid)
+	}
+
+	return &Info{
+		NIK:          s,
+		ProvinceCode: s[0:2],
+		Province:     province,
+		RegencyCode:  s[0:4],
+		DistrictCode: s[0:6],
+		BirthDate:    birth,
+		Gender:       gender,
+		Serial:       s[12:16],
+	}, nil
+}
+
+// dateLayouts adalah format tanggal lahir yang diterima dari klien dan OCR KTP.
+var dateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006", time.RFC3339}
+
+// ParseDate membaca tanggal lahir dalam format ISO atau format KTP (DD-MM-YYYY).
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid birth date %q", s)
+}
+
+// NormalizeGender mengubah variasi penulisan jenis kelamin menjadi GenderMale
+// atau GenderFemale. String kosong dikembalikan jika tidak dikenali.
+func NormalizeGender(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "laki-laki", "laki laki", "lakilaki", "l", "pria", "male", "m":
+		return GenderMale
+	case "perempuan", "p", "wanita", "female", "f":
+		return GenderFemale
+	}
+	return ""
+}
+
+// Age menghitung usia dalam tahun penuh pada tanggal now.
+func Age(birth, now time.Time) int {
+	age := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// provinces berisi kode provinsi Kemendagri. Kode 91–96 dipakai bersama oleh
+// provinsi-provinsi di Papua dan penomorannya berubah sejak pemekaran 2022,
+// sehingga hanya diberi nama "Papua".
+var provinces = map[string]string{
+	"11": "Aceh",
+	"12": "Sumatera Utara",
+	"13": "Sumatera Barat",
+	"14": "Riau",
+	"15": "Jambi",
+	"16": "Sumatera Selatan",
+	"17": "Bengkulu",
+	"18": "Lampung",
+	"19": "Kepulauan Bangka Belitung",
+	"21": "Kepulauan Riau",
+	"31": "DKI Jakarta",
+	"32": "Jawa Barat",
+	"33": "Jawa Tengah",
+	"34": "DI Yogyakarta",
+	"35": "Jawa Timur",
+	"36": "Banten",
+	"51": "Bali",
+	"52": "Nusa Tenggara Barat",
+	"53": "Nusa Tenggara Timur",
+	"61": "Kalimantan Barat",
+	"62": "Kalimantan Tengah",
+	"63": "Kalimantan Selatan",
+	"64": "Kalimantan Timur",
+	"65": "Kalimantan Utara",
+	"71": "Sulawesi Utara",
+	"72": "Sulawesi Tengah",
+	"73": "Sulawesi Selatan",
+	"74": "Sulawesi Tenggara",
+	"75": "Gorontalo",
+	"76": "Sulawesi Barat",
+	"81": "Maluku",
+	"82": "Maluku Utara",
+	"91": "Papua",
+	"92": "Papua",
+	"93": "Papua",
+	"94": "Papua",
+	"95": "Papua",
+	"96": "Papua",
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type window struct {
+	start time.Time
+	hits  int
+}
+
+type MemoryLimiter struct {
+	rule      Rule
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastPrune time.Time
+}
+
+func NewMemoryLimiter(rule Rule) *MemoryLimiter {
+	return &MemoryLimiter{rule: rule, windows: map[string]*window{}, lastPrune: time.Now()}
+}
+
+func (l *MemoryLimiter) Hit(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.rule.Window {
+		w = &window{start: now}
+		l.windows[key] = w
+	}
+	w.hits++
+	if w.hits > l.rule.Max {
+		return false, w.start.Add(l.rule.Window).Sub(now), nil
+	}
+	return true, 0, nil
+}
+
+func (l *MemoryLimiter) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	delete(l.windows, key)
+	l.mu.Unlock()
+	return nil
+}
+
+// prune membuang jendela yang sudah lewat, paling sering sekali per Window.
+func (l *MemoryLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.rule.Window {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.rule.Window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastPrune = now
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLimiter menyimpan hitungan di tabel rate_limits sehingga berlaku
+// untuk semua instance. Waktu memakai jam database agar tidak terpengaruh
+// selisih jam antar server. scope memisahkan limiter yang memakai tabel sama.
+type PostgresLimiter struct {
+	db    *pgxpool.Pool
+	scope string
+	rule  Rule
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewPostgresLimiter(db *pgxpool.Pool, scope string, rule Rule) *PostgresLimiter {
+	return &PostgresLimiter{db: db, scope: scope, rule: rule, lastPrune: time.Now()}
+}
+
+func (l *PostgresLimiter) Hit(ctx context.Context, key string) (bool, time.Duration, error) {
+	l.prune(ctx)
+	var hits int
+	var remaining float64
+	// Pada ON CONFLICT, rate_limits.window_start adalah nilai lama sehingga
+	// kedua CASE memakai kondisi yang sama
+	err := l.db.QueryRow(ctx, `INSERT INTO rate_limits (scope, key, window_start, hits) VALUES ($1, $2, NOW(), 1)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			window_start = CASE WHEN rate_limits.window_start <= NOW() - $3::float8 * INTERVAL '1 second' THEN NOW() ELSE rate_limits.window_start END,
+			hits = CASE WHEN rate_limits.window_start <= NOW() - $3::float8 * INTERVAL '1 second' THEN 1 ELSE rate_limits.hits + 1 END
+		RETURNING hits, EXTRACT(EPOCH FROM window_start + $3::float8 * INTERVAL '1 second' - NOW())::float8`,
+		l.scope, key, l.rule.Window.Seconds()).Scan(&hits, &remaining)
+	if err != nil {
+		return false, 0, err
+	}
+	if hits > l.rule.Max {
+		return false, time.Duration(remaining * float64(time.Second)), nil
+	}
+	return true, 0, nil
+}
+
+func (l *PostgresLimiter) Reset(ctx context.Context, key string) error {
+	_, err := l.db.Exec(ctx, `DELETE FROM rate_limits WHERE scope=$1 AND key=$2`, l.scope, key)
+	return err
+}
+
+// prune menghapus jendela yang sudah lewat, paling sering sekali per Window
+// per instance. Kegagalan hanya dicatat karena tidak memengaruhi hitungan.
+func (l *PostgresLimiter) prune(ctx context.Context) {
+	l.mu.Lock()
+	if time.Since(l.lastPrune) < l.rule.Window {
+		l.mu.Unlock()
+		return
+	}
+	l.lastPrune = time.Now()
+	l.mu.Unlock()
+	_, err := l.db.Exec(ctx, `DELETE FROM rate_limits WHERE scope=$1 AND window_start <= NOW() - $2::float8 * INTERVAL '1 second'`, l.scope, l.rule.Window.Seconds())
+	if err != nil {
+		slog.WarnContext(ctx, "ratelimit prune failed", "scope", l.scope, "err", err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package ratelimit menghitung percobaan per key dalam jendela waktu tetap.
+// Implementasi memori cukup untuk satu instance; untuk beberapa instance di
+// belakang load balancer pakai implementasi Postgres agar hitungannya bersama.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Rule membatasi Max percobaan per Window.
+type Rule struct {
+	Max    int
+	Window time.Duration
+}
+
+type Limiter interface {
+	// Hit menambah hitungan key. allowed false jika hitungan melewati
+	// Rule.Max; retryAfter adalah sisa waktu sampai jendela berikutnya.
+	Hit(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+	// Reset menghapus hitungan key, misalnya setelah login berhasil.
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"v2/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdminPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAdminPostgresRepository(db *pgxpool.Pool) *AdminPostgresRepository {
+	return &AdminPostgresRepository{db: db}
+}
+
+func (r *AdminPostgresRepository) Create(ctx context.Context, a *domain.Admin) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO admins (id, user_id, full_name, nik, phone_number, address) VALUES ($1, $2, $3, $4, $5, $6)`,
+		a.ID, a.UserID, a.FullName, a.NIK, a.PhoneNumber, a.Address)
+	return err
+}
+
+func (r *AdminPostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Admin, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, '') FROM admins WHERE user_id=$1`, userID)
+	var a domain.Admin
+	if err := row.Scan(&a.ID, &a.UserID, &a.FullName, &a.NIK, &a.PhoneNumber, &a.Address); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"v2/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 type AdminRepository interface {
 	Create(ctx context.Context, admin *domain.Admin) error
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Admin, error)
 }
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"v2/internal/domain/audit"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditPostgresRepository(db *pgxpool.Pool) *AuditPostgresRepository {
+	return &AuditPostgresRepository{db: db}
+}
+
+const auditColumns = `id, actor_id, actor_role, action, entity, entity_id, before, after, ip, user_agent, method, path, created_at`
+
+const auditFilter = ` WHERE ($1 = '' OR actor_id::text = $1)
+	AND ($2 = '' OR actor_role = $2)
+	AND ($3 = '' OR action = $3)
+	AND ($4 = '' OR entity = $4)
+	AND ($5 = '' OR entity_id = $5)
+	AND ($6::timestamp IS NULL OR created_at >= $6)
+	AND ($7::timestamp IS NULL OR created_at < $7)`
+
+func (r *AuditPostgresRepository) Create(ctx context.Context, e *audit.Entry) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return r.db.QueryRow(ctx, `INSERT INTO audit_logs (id, actor_id, actor_role, action, entity, entity_id, before, after, ip, user_agent, method, path, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW()) RETURNING created_at`,
+		e.ID, e.ActorID, e.ActorRole, e.Action, e.Entity, e.EntityID, nullJSON(e.Before), nullJSON(e.After), e.IP, e.UserAgent, e.Method, e.Path).Scan(&e.CreatedAt)
+}
+
+func (r *AuditPostgresRepository) FindPaginated(ctx context.Context, f audit.Filter) ([]audit.Entry, int64, error) {
+	args := []interface{}{f.ActorID, f.Role, f.Action, f.Entity, f.EntityID, f.From, f.To}
+	offset := (f.Page - 1) * f.Limit
+	rows, err := r.db.Query(ctx, `SELECT `+auditColumns+` FROM audit_logs`+auditFilter+` ORDER BY created_at DESC LIMIT $8 OFFSET $9`, append(args, f.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []audit.Entry
+	for rows.Next() {
+		var e audit.Entry
+		var before, after []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.Entity, &e.EntityID, &before, &after, &e.IP, &e.UserAgent, &e.Method, &e.Path, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		e.Before, e.After = before, after
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM audit_logs`+auditFilter, args...)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// nullJSON menyimpan NULL alih-alih JSON kosong.
+func nullJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"context"
+	"v2/internal/domain/audit"
+)
+
+// AuditRepository sengaja tidak menyediakan update/delete: audit log append-only.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *audit.Entry) error
+	FindPaginated(ctx context.Context, filter audit.Filter) ([]audit.Entry, int64, error)
+}
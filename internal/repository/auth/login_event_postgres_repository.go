@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"v2/internal/domain/auth"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoginEventPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginEventPostgresRepository(db *pgxpool.Pool) *LoginEventPostgresRepository {
+	return &LoginEventPostgresRepository{db: db}
+}
+
+const loginEventColumns = `id, user_id, email, success, reason, ip, user_agent, created_at`
+
+const loginEventFilter = ` WHERE ($1 = '' OR user_id::text = $1)
+	AND ($2 = '' OR lower(email) = lower($2))
+	AND ($3 = '' OR ip = $3)
+	AND ($4 = '' OR success::text = $4)
+	AND ($5 = '' OR reason = $5)
+	AND ($6::timestamp IS NULL OR created_at >= $6)
+	AND ($7::timestamp IS NULL OR created_at < $7)`
+
+func (r *LoginEventPostgresRepository) Create(ctx context.Context, e *auth.LoginEvent) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return r.db.QueryRow(ctx, `INSERT INTO login_events (`+loginEventColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW()) RETURNING created_at`,
+		e.ID, e.UserID, e.Email, e.Success, e.Reason, e.IP, e.UserAgent).Scan(&e.CreatedAt)
+}
+
+func (r *LoginEventPostgresRepository) FindPaginated(ctx context.Context, f auth.EventFilter) ([]auth.LoginEvent, int64, error) {
+	args := []interface{}{f.UserID, f.Email, f.IP, f.Success, f.Reason, f.From, f.To}
+	offset := (f.Page - 1) * f.Limit
+	rows, err := r.db.Query(ctx, `SELECT `+loginEventColumns+` FROM login_events`+loginEventFilter+` ORDER BY created_at DESC LIMIT $8 OFFSET $9`, append(args, f.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []auth.LoginEvent
+	for rows.Next() {
+		var e auth.LoginEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Email, &e.Success, &e.Reason, &e.IP, &e.UserAgent, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM login_events`+loginEventFilter, args...)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
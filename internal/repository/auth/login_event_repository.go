@@ -0,0 +1,11 @@
+package auth
+
+import (
+	"context"
+	"v2/internal/domain/auth"
+)
+
+type LoginEventRepository interface {
+	Create(ctx context.Context, event *auth.LoginEvent) error
+	FindPaginated(ctx context.Context, filter auth.EventFilter) ([]auth.LoginEvent, int64, error)
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"v2/internal/domain/auth"
+	"v2/internal/fieldcrypt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MFAPostgresRepository menyimpan secret TOTP terenkripsi dengan kunci yang
+// sama dengan data pribadi pasien.
+type MFAPostgresRepository struct {
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+func NewMFAPostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *MFAPostgresRepository {
+	return &MFAPostgresRepository{db: db, cipher: cipher}
+}
+
+func (r *MFAPostgresRepository) FindTOTP(ctx context.Context, userID uuid.UUID) (*auth.TOTP, error) {
+	var t auth.TOTP
+	err := r.db.QueryRow(ctx, `SELECT COALESCE(totp_secret, ''), totp_enabled_at, COALESCE(totp_last_step, 0) FROM users WHERE id=$1`, userID).
+		Scan(&t.Secret, &t.EnabledAt, &t.LastStep)
+	if err != nil {
+		return nil, err
+	}
+	if t.Secret, err = r.cipher.Decrypt(t.Secret); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *MFAPostgresRepository) SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	sealed, err := r.cipher.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `UPDATE users SET totp_secret=$2, totp_enabled_at=NULL, totp_last_step=NULL WHERE id=$1`, userID, sealed)
+	return err
+}
+
+func (r *MFAPostgresRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE users SET totp_enabled_at=NOW(), totp_last_step=$2 WHERE id=$1`, userID, step); err != nil {
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *MFAPostgresRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=NULL WHERE id=$1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_challenges WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *MFAPostgresRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE users SET totp_last_step=$2 WHERE id=$1 AND COALESCE(totp_last_step, 0) < $2`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFAPostgresRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, NOW())`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MFAPostgresRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE mfa_recovery_codes SET used_at=NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *MFAPostgresRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id=$1 AND used_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *MFAPostgresRepository) CreateChallenge(ctx context.Context, c *auth.Challenge, tokenHash string) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	// Challenge lama yang sudah kedaluwarsa ikut dibersihkan
+	if _, err := r.db.Exec(ctx, `DELETE FROM mfa_challenges WHERE expires_at < NOW() - INTERVAL '1 day'`); err != nil {
+		return err
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO mfa_challenges (id, token_hash, user_id, kind, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, 0, $5, NOW())`, c.ID, tokenHash, c.UserID, c.Kind, c.ExpiresAt)
+	return err
+}
+
+func (r *MFAPostgresRepository) FindChallenge(ctx context.Context, tokenHash string) (*auth.Challenge, error) {
+	var c auth.Challenge
+	err := r.db.QueryRow(ctx, `SELECT id, user_id, kind, attempts, expires_at, used_at FROM mfa_challenges WHERE token_hash=$1`, tokenHash).
+		Scan(&c.ID, &c.UserID, &c.Kind, &c.Attempts, &c.ExpiresAt, &c.UsedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *MFAPostgresRepository) AddChallengeAttempt(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id=$1`, id)
+	return err
+}
+
+func (r *MFAPostgresRepository) ConsumeChallenge(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE mfa_challenges SET used_at=NOW() WHERE id=$1 AND used_at IS NULL AND expires_at > NOW()`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
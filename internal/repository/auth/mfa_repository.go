@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"v2/internal/domain/auth"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository interface {
+	// FindTOTP mengembalikan status 2FA akun; Secret kosong jika belum pernah mendaftar.
+	FindTOTP(ctx context.Context, userID uuid.UUID) (*auth.TOTP, error)
+	// SetPendingSecret menyimpan secret yang belum dikonfirmasi.
+	SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	// Enable mengaktifkan 2FA dan mengganti semua recovery code.
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error
+	Disable(ctx context.Context, userID uuid.UUID) error
+	// UseStep menandai periode TOTP sudah dipakai; false jika periode yang
+	// sama atau lebih baru sudah pernah dipakai.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	// UseRecoveryCode menandai recovery code terpakai; false jika tidak ada
+	// atau sudah dipakai.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+
+	CreateChallenge(ctx context.Context, challenge *auth.Challenge, tokenHash string) error
+	FindChallenge(ctx context.Context, tokenHash string) (*auth.Challenge, error)
+	AddChallengeAttempt(ctx context.Context, id uuid.UUID) error
+	// ConsumeChallenge menandai challenge terpakai; false jika sudah dipakai.
+	ConsumeChallenge(ctx context.Context, id uuid.UUID) (bool, error)
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"v2/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CashierPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCashierPostgresRepository(db *pgxpool.Pool) *CashierPostgresRepository {
+	return &CashierPostgresRepository{db: db}
+}
+
+func (r *CashierPostgresRepository) Create(ctx context.Context, c *domain.Cashier) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO cashiers (id, user_id, full_name, nik, phone_number, address) VALUES ($1, $2, $3, $4, $5, $6)`,
+		c.ID, c.UserID, c.FullName, c.NIK, c.PhoneNumber, c.Address)
+	return err
+}
+
+func (r *CashierPostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Cashier, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, '') FROM cashiers WHERE user_id=$1`, userID)
+	var c domain.Cashier
+	if err := row.Scan(&c.ID, &c.UserID, &c.FullName, &c.NIK, &c.PhoneNumber, &c.Address); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -3,8 +3,11 @@ package repository
 import (
 	"context"
 	"v2/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 type CashierRepository interface {
 	Create(ctx context.Context, cashier *domain.Cashier) error
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Cashier, error)
 }
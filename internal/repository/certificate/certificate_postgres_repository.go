@@ -0,0 +1,110 @@
+package certificate
+
+import (
+	"context"
+	"encoding/json"
+	"v2/internal/domain/certificate"
+	"v2/internal/fieldcrypt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Nama dan NIK pasien yang disalin ke sertifikat disimpan terenkripsi.
+type CertificatePostgresRepository struct {
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+func NewCertificatePostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *CertificatePostgresRepository {
+	return &CertificatePostgresRepository{db: db, cipher: cipher}
+}
+
+const certificateColumns = `id, certificate_number, patient_id, physical_examination_id, patient_name, COALESCE(patient_nik, ''), COALESCE(mr_number, ''), vitals, decision, decided_by_role, COALESCE(decided_by_name, ''), COALESCE(notes, ''), COALESCE(climb_date, ''), issued_by, issued_at, valid_until, revoked_at, revoked_by, COALESCE(revoke_reason, '')`
+
+func (r *CertificatePostgresRepository) Create(ctx context.Context, c *certificate.Certificate) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	name, err := r.cipher.Encrypt(c.PatientName)
+	if err != nil {
+		return err
+	}
+	nik, err := r.cipher.Encrypt(c.PatientNIK)
+	if err != nil {
+		return err
+	}
+	vitals, _ := json.Marshal(c.Vitals)
+	// Unique index idx_certificates_active_exam menjaga satu sertifikat aktif per pemeriksaan
+	tag, err := r.db.Exec(ctx, `INSERT INTO certificates (id, certificate_number, patient_id, physical_examination_id, patient_name, patient_nik, mr_number, vitals, decision, decided_by_role, decided_by_name, notes, climb_date, issued_by, issued_at, valid_until) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
+		ON CONFLICT (physical_examination_id) WHERE revoked_at IS NULL DO NOTHING`,
+		c.ID, c.CertificateNumber, c.PatientID, c.PhysicalExaminationID, name, nik, c.MRNumber, vitals, c.Decision, c.DecidedByRole, c.DecidedByName, c.Notes, c.ClimbDate, c.IssuedBy, c.IssuedAt, c.ValidUntil)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CertificatePostgresRepository) FindActiveByExaminationID(ctx context.Context, examID uuid.UUID) (*certificate.Certificate, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+certificateColumns+` FROM certificates WHERE physical_examination_id=$1 AND revoked_at IS NULL`, examID)
+	return r.scanCertificate(row)
+}
+
+func (r *CertificatePostgresRepository) FindByNumber(ctx context.Context, number string) (*certificate.Certificate, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+certificateColumns+` FROM certificates WHERE certificate_number=$1`, number)
+	return r.scanCertificate(row)
+}
+
+func (r *CertificatePostgresRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]certificate.Certificate, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+certificateColumns+` FROM certificates WHERE patient_id=$1 ORDER BY issued_at DESC`, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []certificate.Certificate
+	for rows.Next() {
+		c, err := r.scanCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *c)
+	}
+	return result, nil
+}
+
+func (r *CertificatePostgresRepository) Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID, reason string) error {
+	_, err := r.db.Exec(ctx, `UPDATE certificates SET revoked_at=NOW(), revoked_by=$1, revoke_reason=$2 WHERE id=$3 AND revoked_at IS NULL`, revokedBy, reason, id)
+	return err
+}
+
+func (r *CertificatePostgresRepository) IsOwnedByUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	var owned bool
+	row := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM certificates c JOIN patients p ON p.id = c.patient_id WHERE c.id=$1 AND p.user_id=$2)`, id, userID)
+	if err := row.Scan(&owned); err != nil {
+		return false, err
+	}
+	return owned, nil
+}
+
+func (r *CertificatePostgresRepository) scanCertificate(row interface {
+	Scan(dest ...interface{}) error
+}) (*certificate.Certificate, error) {
+	var c certificate.Certificate
+	var vitalsData []byte
+	if err := row.Scan(&c.ID, &c.CertificateNumber, &c.PatientID, &c.PhysicalExaminationID, &c.PatientName, &c.PatientNIK, &c.MRNumber, &vitalsData, &c.Decision, &c.DecidedByRole, &c.DecidedByName, &c.Notes, &c.ClimbDate, &c.IssuedBy, &c.IssuedAt, &c.ValidUntil, &c.RevokedAt, &c.RevokedBy, &c.RevokeReason); err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal(vitalsData, &c.Vitals)
+	var err error
+	if c.PatientName, err = r.cipher.Decrypt(c.PatientName); err != nil {
+		return nil, err
+	}
+	if c.PatientNIK, err = r.cipher.Decrypt(c.PatientNIK); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
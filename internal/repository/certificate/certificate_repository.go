@@ -0,0 +1,18 @@
+package certificate
+
+import (
+	"context"
+	"v2/internal/domain/certificate"
+
+	"github.com/google/uuid"
+)
+
+type CertificateRepository interface {
+	// Create mengembalikan pgx.ErrNoRows jika pemeriksaan sudah punya sertifikat aktif.
+	Create(ctx context.Context, cert *certificate.Certificate) error
+	FindActiveByExaminationID(ctx context.Context, examID uuid.UUID) (*certificate.Certificate, error)
+	FindByNumber(ctx context.Context, number string) (*certificate.Certificate, error)
+	FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]certificate.Certificate, error)
+	Revoke(ctx context.Context, id uuid.UUID, revokedBy uuid.UUID, reason string) error
+	IsOwnedByUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
+}
@@ -0,0 +1,138 @@
+package consultation
+
+import (
+	"context"
+	"time"
+	"v2/internal/domain/consultation"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ConsultationPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewConsultationPostgresRepository(db *pgxpool.Pool) *ConsultationPostgresRepository {
+	return &ConsultationPostgresRepository{db: db}
+}
+
+const consultationColumns = `id, physical_examination_id, patient_id, doctor_id, status, COALESCE(reason, ''), COALESCE(subjective, ''), COALESCE(objective, ''), COALESCE(assessment, ''), COALESCE(plan, ''), COALESCE(diagnosis, ''), COALESCE(fitness_decision, ''), COALESCE(referral_note, ''), requested_by, accepted_at, started_at, finished_at, created_at, updated_at`
+
+func (r *ConsultationPostgresRepository) Create(ctx context.Context, c *consultation.Consultation) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO consultations (id, physical_examination_id, patient_id, doctor_id, status, reason, subjective, objective, assessment, plan, diagnosis, fitness_decision, referral_note, requested_by, accepted_at, started_at, finished_at, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)`,
+		c.ID, c.PhysicalExaminationID, c.PatientID, c.DoctorID, c.Status, c.Reason, c.Notes.Subjective, c.Notes.Objective, c.Notes.Assessment, c.Notes.Plan, c.Diagnosis, c.FitnessDecision, c.ReferralNote, c.RequestedBy, c.AcceptedAt, c.StartedAt, c.FinishedAt, c.CreatedAt, c.UpdatedAt)
+	return err
+}
+
+func (r *ConsultationPostgresRepository) Update(ctx context.Context, c *consultation.Consultation) error {
+	_, err := r.db.Exec(ctx, `UPDATE consultations SET doctor_id=$1, status=$2, reason=$3, subjective=$4, objective=$5, assessment=$6, plan=$7, diagnosis=$8, fitness_decision=$9, referral_note=$10, accepted_at=$11, started_at=$12, finished_at=$13, updated_at=$14 WHERE id=$15`,
+		c.DoctorID, c.Status, c.Reason, c.Notes.Subjective, c.Notes.Objective, c.Notes.Assessment, c.Notes.Plan, c.Diagnosis, c.FitnessDecision, c.ReferralNote, c.AcceptedAt, c.StartedAt, c.FinishedAt, c.UpdatedAt, c.ID)
+	return err
+}
+
+func (r *ConsultationPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*consultation.Consultation, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+consultationColumns+` FROM consultations WHERE id=$1`, id)
+	return scanConsultation(row)
+}
+
+func (r *ConsultationPostgresRepository) FindByExaminationID(ctx context.Context, examID uuid.UUID) (*consultation.Consultation, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+consultationColumns+` FROM consultations WHERE physical_examination_id=$1 ORDER BY created_at DESC LIMIT 1`, examID)
+	return scanConsultation(row)
+}
+
+// FindByPatientID mengembalikan semua konsultasi pasien, terbaru lebih dulu.
+func (r *ConsultationPostgresRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]consultation.Consultation, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+consultationColumns+` FROM consultations WHERE patient_id=$1 ORDER BY created_at DESC`, patientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []consultation.Consultation
+	for rows.Next() {
+		c, err := scanConsultation(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *c)
+	}
+	return result, rows.Err()
+}
+
+// FindPaginated mengembalikan konsultasi; jika doctorID diisi hanya milik dokter tersebut.
+func (r *ConsultationPostgresRepository) FindPaginated(ctx context.Context, doctorID *uuid.UUID, status string, page, limit int) ([]consultation.Consultation, int64, error) {
+	offset := (page - 1) * limit
+	where := ` WHERE ($1::uuid IS NULL OR doctor_id=$1) AND ($2 = '' OR status=$2)`
+	rows, err := r.db.Query(ctx, `SELECT `+consultationColumns+` FROM consultations`+where+` ORDER BY created_at ASC LIMIT $3 OFFSET $4`, doctorID, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []consultation.Consultation
+	for rows.Next() {
+		c, err := scanConsultation(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, *c)
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM consultations`+where, doctorID, status)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// ReplaceDiagnoses mengganti seluruh kode diagnosis konsultasi dalam satu transaksi.
+func (r *ConsultationPostgresRepository) ReplaceDiagnoses(ctx context.Context, id uuid.UUID, diagnoses []consultation.Diagnosis) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM consultation_diagnoses WHERE consultation_id=$1`, id); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, d := range diagnoses {
+		if _, err := tx.Exec(ctx, `INSERT INTO consultation_diagnoses (consultation_id, code, is_primary, created_at) VALUES ($1, $2, $3, $4)`, id, d.Code, d.Primary, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// FindDiagnoses mengembalikan kode diagnosis konsultasi, diagnosis primer lebih dulu.
+func (r *ConsultationPostgresRepository) FindDiagnoses(ctx context.Context, id uuid.UUID) ([]consultation.Diagnosis, error) {
+	rows, err := r.db.Query(ctx, `SELECT d.code, i.name_en, i.name_id, d.is_primary FROM consultation_diagnoses d
+		JOIN icd10_codes i ON i.code = d.code
+		WHERE d.consultation_id=$1 ORDER BY d.is_primary DESC, d.code`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []consultation.Diagnosis
+	for rows.Next() {
+		var d consultation.Diagnosis
+		if err := rows.Scan(&d.Code, &d.NameEN, &d.NameID, &d.Primary); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+func scanConsultation(row interface {
+	Scan(dest ...interface{}) error
+}) (*consultation.Consultation, error) {
+	var c consultation.Consultation
+	if err := row.Scan(&c.ID, &c.PhysicalExaminationID, &c.PatientID, &c.DoctorID, &c.Status, &c.Reason, &c.Notes.Subjective, &c.Notes.Objective, &c.Notes.Assessment, &c.Notes.Plan, &c.Diagnosis, &c.FitnessDecision, &c.ReferralNote, &c.RequestedBy, &c.AcceptedAt, &c.StartedAt, &c.FinishedAt, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
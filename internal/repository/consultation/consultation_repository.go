@@ -0,0 +1,19 @@
+package consultation
+
+import (
+	"context"
+	"v2/internal/domain/consultation"
+
+	"github.com/google/uuid"
+)
+
+type ConsultationRepository interface {
+	Create(ctx context.Context, c *consultation.Consultation) error
+	Update(ctx context.Context, c *consultation.Consultation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*consultation.Consultation, error)
+	FindByExaminationID(ctx context.Context, examID uuid.UUID) (*consultation.Consultation, error)
+	FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]consultation.Consultation, error)
+	FindPaginated(ctx context.Context, doctorID *uuid.UUID, status string, page, limit int) ([]consultation.Consultation, int64, error)
+	ReplaceDiagnoses(ctx context.Context, id uuid.UUID, diagnoses []consultation.Diagnosis) error
+	FindDiagnoses(ctx context.Context, id uuid.UUID) ([]consultation.Diagnosis, error)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"v2/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DoctorPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDoctorPostgresRepository(db *pgxpool.Pool) *DoctorPostgresRepository {
+	return &DoctorPostgresRepository{db: db}
+}
+
+func (r *DoctorPostgresRepository) Create(ctx context.Context, d *domain.Doctor) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO doctors (id, user_id, full_name, nik, phone_number, address, specialty, license_number) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		d.ID, d.UserID, d.FullName, d.NIK, d.PhoneNumber, d.Address, d.Specialty, d.LicenseNumber)
+	return err
+}
+
+func (r *DoctorPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Doctor, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, ''), COALESCE(specialty, ''), COALESCE(license_number, '') FROM doctors WHERE id=$1`, id)
+	var d domain.Doctor
+	if err := row.Scan(&d.ID, &d.UserID, &d.FullName, &d.NIK, &d.PhoneNumber, &d.Address, &d.Specialty, &d.LicenseNumber); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *DoctorPostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Doctor, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, ''), COALESCE(specialty, ''), COALESCE(license_number, '') FROM doctors WHERE user_id=$1`, userID)
+	var d domain.Doctor
+	if err := row.Scan(&d.ID, &d.UserID, &d.FullName, &d.NIK, &d.PhoneNumber, &d.Address, &d.Specialty, &d.LicenseNumber); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"v2/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 type DoctorRepository interface {
 	Create(ctx context.Context, doctor *domain.Doctor) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Doctor, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Doctor, error)
 }
@@ -0,0 +1,339 @@
+package duplicate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+	"v2/internal/domain/duplicate"
+	"v2/internal/fieldcrypt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DuplicatePostgresRepository struct {
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+func NewDuplicatePostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *DuplicatePostgresRepository {
+	return &DuplicatePostgresRepository{db: db, cipher: cipher}
+}
+
+// Telepon dan email terenkripsi sehingga dibandingkan lewat blind index, yang
+// sudah dinormalisasi (08xx = +628xx, email tanpa membedakan huruf besar).
+var (
+	samePhone = `COALESCE(a.phone_bidx = b.phone_bidx, false)`
+	sameEmail = `COALESCE(a.email_bidx = b.email_bidx, false)`
+	sameBirth = `COALESCE(a.birth_date, '') <> '' AND a.birth_date = COALESCE(b.birth_date, '')`
+)
+
+// Skor: kemiripan nama (trigram) 50%, tanggal lahir 20%, telepon 20%, email 10%.
+var detectQuery = `
+INSERT INTO patient_duplicate_candidates (id, patient_id, candidate_id, score, reasons, status, created_at)
+SELECT gen_random_uuid(), aid, bid, score, reasons, 'pending', NOW() FROM (
+	SELECT aid, bid,
+		0.5 * name_sim + 0.2 * bd::int + 0.2 * ph::int + 0.1 * em::int AS score,
+		array_remove(ARRAY[
+			CASE WHEN name_sim >= 0.6 THEN 'name' END,
+			CASE WHEN bd THEN 'birth_date' END,
+			CASE WHEN ph THEN 'phone' END,
+			CASE WHEN em THEN 'email' END], NULL) AS reasons
+	FROM (
+		SELECT a.id AS aid, b.id AS bid,
+			similarity(a.full_name, b.full_name) AS name_sim,
+			` + sameBirth + ` AS bd,
+			` + samePhone + ` AS ph,
+			` + sameEmail + ` AS em
+		FROM patients a JOIN patients b ON a.id < b.id
+		WHERE a.merged_into IS NULL AND b.merged_into IS NULL
+			AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+			AND ($1::uuid IS NULL OR a.id = $1 OR b.id = $1)
+			AND (a.full_name % b.full_name OR (` + samePhone + `) OR (` + sameEmail + `))
+	) pairs
+) scored
+WHERE score >= $2
+ON CONFLICT (patient_id, candidate_id) DO NOTHING`
+
+func (r *DuplicatePostgresRepository) Detect(ctx context.Context, patientID *uuid.UUID, minScore float64) (int64, error) {
+	tag, err := r.db.Exec(ctx, detectQuery, patientID, minScore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Flag mengurutkan pasangan karena patient_id harus lebih kecil dari
+// candidate_id, sama seperti urutan uuid di Postgres.
+func (r *DuplicatePostgresRepository) Flag(ctx context.Context, a, b uuid.UUID, score float64, reasons []string) error {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO patient_duplicate_candidates (id, patient_id, candidate_id, score, reasons, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', NOW())
+		ON CONFLICT (patient_id, candidate_id) DO UPDATE SET score=EXCLUDED.score, reasons=EXCLUDED.reasons
+		WHERE patient_duplicate_candidates.status = 'pending'`, uuid.New(), a, b, score, reasons)
+	return err
+}
+
+const candidateSelect = `SELECT c.id, c.score, c.reasons, c.status, c.reviewed_by, c.reviewed_at, c.created_at,
+	a.id, COALESCE(a.nik, ''), a.full_name, COALESCE(a.birth_date, ''), COALESCE(a.gender, ''), COALESCE(a.phone, ''), COALESCE(a.email, ''), a.created_at,
+	b.id, COALESCE(b.nik, ''), b.full_name, COALESCE(b.birth_date, ''), COALESCE(b.gender, ''), COALESCE(b.phone, ''), COALESCE(b.email, ''), b.created_at
+FROM patient_duplicate_candidates c
+JOIN patients a ON a.id = c.patient_id
+JOIN patients b ON b.id = c.candidate_id`
+
+func (r *DuplicatePostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*duplicate.Candidate, error) {
+	row := r.db.QueryRow(ctx, candidateSelect+` WHERE c.id=$1`, id)
+	return r.scanCandidate(row)
+}
+
+func (r *DuplicatePostgresRepository) FindPaginated(ctx context.Context, status string, page, limit int) ([]duplicate.Candidate, int64, error) {
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(ctx, candidateSelect+` WHERE ($1 = '' OR c.status=$1) ORDER BY c.score DESC, c.created_at LIMIT $2 OFFSET $3`, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []duplicate.Candidate
+	for rows.Next() {
+		c, err := r.scanCandidate(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, *c)
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM patient_duplicate_candidates WHERE ($1 = '' OR status=$1)`, status)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+func (r *DuplicatePostgresRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string, reviewedBy *uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE patient_duplicate_candidates SET status=$1, reviewed_by=$2, reviewed_at=NOW() WHERE id=$3`, status, reviewedBy, id)
+	return err
+}
+
+// mergeTables berisi tabel dengan kolom patient_id yang dipindahkan saat merge.
+var mergeTables = []string{"medical_records", "physical_examinations", "consultations", "certificates"}
+
+// Merge mengembalikan pgx.ErrNoRows jika salah satu pasien sudah digabung atau dihapus.
+func (r *DuplicatePostgresRepository) Merge(ctx context.Context, survivorID, mergedID uuid.UUID, candidateID, mergedBy *uuid.UUID) (*duplicate.Merge, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci kedua pasien agar merge yang berjalan bersamaan tidak saling menimpa
+	var locked int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM (SELECT id FROM patients WHERE id IN ($1, $2) AND merged_into IS NULL AND deleted_at IS NULL FOR UPDATE) p`, survivorID, mergedID).Scan(&locked); err != nil {
+		return nil, err
+	}
+	if locked != 2 {
+		return nil, pgx.ErrNoRows
+	}
+
+	var snapshot []byte
+	if err := tx.QueryRow(ctx, `SELECT to_jsonb(p) FROM patients p WHERE id=$1`, mergedID).Scan(&snapshot); err != nil {
+		return nil, err
+	}
+
+	// Lepas NIK dan akun dari pasien yang digabung dulu karena keduanya unik
+	if _, err := tx.Exec(ctx, `UPDATE patients SET merged_into=$1, nik=NULL, nik_bidx=NULL, nik_last4_bidx=NULL, user_id=NULL, updated_at=NOW() WHERE id=$2`, survivorID, mergedID); err != nil {
+		return nil, err
+	}
+	// Lengkapi field kosong pasien utama dari pasien yang digabung. Nilai
+	// terenkripsi disalin apa adanya beserta blind index-nya.
+	var survivorNIK, survivorNIKIndex string
+	err = tx.QueryRow(ctx, `UPDATE patients SET
+		nik=COALESCE(NULLIF(nik, ''), NULLIF($2::jsonb->>'nik', '')),
+		nik_bidx=CASE WHEN COALESCE(nik, '') = '' THEN $2::jsonb->>'nik_bidx' ELSE nik_bidx END,
+		nik_last4_bidx=CASE WHEN COALESCE(nik, '') = '' THEN $2::jsonb->>'nik_last4_bidx' ELSE nik_last4_bidx END,
+		user_id=COALESCE(user_id, ($2::jsonb->>'user_id')::uuid),
+		birth_date=COALESCE(NULLIF(birth_date, ''), NULLIF($2::jsonb->>'birth_date', '')),
+		gender=COALESCE(NULLIF(gender, ''), NULLIF($2::jsonb->>'gender', '')),
+		phone=COALESCE(NULLIF(phone, ''), NULLIF($2::jsonb->>'phone', '')),
+		phone_bidx=CASE WHEN COALESCE(phone, '') = '' THEN $2::jsonb->>'phone_bidx' ELSE phone_bidx END,
+		phone_last4_bidx=CASE WHEN COALESCE(phone, '') = '' THEN $2::jsonb->>'phone_last4_bidx' ELSE phone_last4_bidx END,
+		email=COALESCE(NULLIF(email, ''), NULLIF($2::jsonb->>'email', '')),
+		email_bidx=CASE WHEN COALESCE(email, '') = '' THEN $2::jsonb->>'email_bidx' ELSE email_bidx END,
+		updated_at=NOW()
+		WHERE id=$1 RETURNING COALESCE(nik, ''), COALESCE(nik_bidx, '')`, survivorID, snapshot).Scan(&survivorNIK, &survivorNIKIndex)
+	if err != nil {
+		return nil, err
+	}
+	if survivorNIK, err = r.cipher.Decrypt(survivorNIK); err != nil {
+		return nil, err
+	}
+
+	moved := map[string]int64{}
+	for _, table := range mergeTables {
+		tag, err := tx.Exec(ctx, `UPDATE `+table+` SET patient_id=$1 WHERE patient_id=$2`, survivorID, mergedID)
+		if err != nil {
+			return nil, err
+		}
+		moved[table] = tag.RowsAffected()
+	}
+	// Screening dan antrean terhubung lewat NIK di patient_info
+	var merged struct {
+		NIKIndex string `json:"nik_bidx"`
+	}
+	_ = json.Unmarshal(snapshot, &merged)
+	for _, table := range []string{"screening_answers", "screening_queues"} {
+		var affected int64
+		if merged.NIKIndex != "" && survivorNIKIndex != "" && merged.NIKIndex != survivorNIKIndex {
+			if affected, err = r.moveScreening(ctx, tx, table, merged.NIKIndex, survivorNIK, survivorNIKIndex); err != nil {
+				return nil, err
+			}
+		}
+		moved[table] = affected
+	}
+
+	// Kandidat yang dipakai ditandai merged, kandidat lain untuk pasien yang digabung ditutup
+	if candidateID != nil {
+		if _, err := tx.Exec(ctx, `UPDATE patient_duplicate_candidates SET status='merged', reviewed_by=$1, reviewed_at=NOW() WHERE id=$2`, mergedBy, *candidateID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE patient_duplicate_candidates SET status='dismissed', reviewed_by=$1, reviewed_at=NOW() WHERE status='pending' AND (patient_id=$2 OR candidate_id=$2)`, mergedBy, mergedID); err != nil {
+		return nil, err
+	}
+
+	m := &duplicate.Merge{
+		ID:             uuid.New(),
+		SurvivorID:     survivorID,
+		MergedID:       mergedID,
+		CandidateID:    candidateID,
+		MergedSnapshot: snapshot,
+		Moved:          moved,
+		MergedBy:       mergedBy,
+		MergedAt:       time.Now(),
+	}
+	movedJSON, _ := json.Marshal(moved)
+	if _, err := tx.Exec(ctx, `INSERT INTO patient_merges (id, survivor_id, merged_id, candidate_id, merged_snapshot, moved, merged_by, merged_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		m.ID, m.SurvivorID, m.MergedID, m.CandidateID, snapshot, movedJSON, m.MergedBy, m.MergedAt); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if m.MergedSnapshot, err = r.openSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// moveScreening memindahkan jawaban/antrean dari NIK pasien yang digabung ke
+// NIK pasien utama. patient_info terenkripsi sehingga NIK di dalamnya diganti
+// satu per satu di aplikasi.
+func (r *DuplicatePostgresRepository) moveScreening(ctx context.Context, tx pgx.Tx, table, fromIndex, toNIK, toIndex string) (int64, error) {
+	rows, err := tx.Query(ctx, `SELECT id, patient_info FROM `+table+` WHERE nik_bidx=$1 FOR UPDATE`, fromIndex)
+	if err != nil {
+		return 0, err
+	}
+	type item struct {
+		id   uuid.UUID
+		info map[string]interface{}
+	}
+	var items []item
+	for rows.Next() {
+		var it item
+		var data []byte
+		if err := rows.Scan(&it.id, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := r.cipher.DecryptJSON(data, &it.info); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, it := range items {
+		if it.info == nil {
+			it.info = map[string]interface{}{}
+		}
+		it.info["nik"] = toNIK
+		data, err := r.cipher.EncryptJSON(it.info)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE `+table+` SET patient_info=$1, nik_bidx=$2 WHERE id=$3`, data, toIndex, it.id); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(items)), nil
+}
+
+// openSnapshot mendekripsi field terenkripsi snapshot pasien untuk ditampilkan.
+// Snapshot di patient_merges tetap tersimpan terenkripsi.
+func (r *DuplicatePostgresRepository) openSnapshot(snapshot []byte) (json.RawMessage, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(snapshot, &m); err != nil {
+		return snapshot, nil
+	}
+	for _, key := range []string{"nik", "phone", "email", "address"} {
+		if v, ok := m[key].(string); ok {
+			plain, err := r.cipher.Decrypt(v)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = plain
+		}
+	}
+	for _, key := range []string{"nik_bidx", "phone_bidx", "email_bidx", "nik_last4_bidx", "phone_last4_bidx"} {
+		delete(m, key)
+	}
+	return json.Marshal(m)
+}
+
+func (r *DuplicatePostgresRepository) FindMerges(ctx context.Context, survivorID uuid.UUID) ([]duplicate.Merge, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, survivor_id, merged_id, candidate_id, merged_snapshot, moved, merged_by, merged_at FROM patient_merges WHERE survivor_id=$1 ORDER BY merged_at DESC`, survivorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []duplicate.Merge
+	for rows.Next() {
+		var m duplicate.Merge
+		var moved []byte
+		if err := rows.Scan(&m.ID, &m.SurvivorID, &m.MergedID, &m.CandidateID, &m.MergedSnapshot, &moved, &m.MergedBy, &m.MergedAt); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(moved, &m.Moved)
+		if m.MergedSnapshot, err = r.openSnapshot(m.MergedSnapshot); err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, rows.Err()
+}
+
+func (r *DuplicatePostgresRepository) scanCandidate(row interface {
+	Scan(dest ...interface{}) error
+}) (*duplicate.Candidate, error) {
+	var c duplicate.Candidate
+	a, b := &c.Patient, &c.Candidate
+	if err := row.Scan(&c.ID, &c.Score, &c.Reasons, &c.Status, &c.ReviewedBy, &c.ReviewedAt, &c.CreatedAt,
+		&a.ID, &a.NIK, &a.FullName, &a.BirthDate, &a.Gender, &a.Phone, &a.Email, &a.CreatedAt,
+		&b.ID, &b.NIK, &b.FullName, &b.BirthDate, &b.Gender, &b.Phone, &b.Email, &b.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, f := range []*string{&a.NIK, &a.Phone, &a.Email, &b.NIK, &b.Phone, &b.Email} {
+		var err error
+		if *f, err = r.cipher.Decrypt(*f); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
@@ -0,0 +1,22 @@
+package duplicate
+
+import (
+	"context"
+	"v2/internal/domain/duplicate"
+
+	"github.com/google/uuid"
+)
+
+type DuplicateRepository interface {
+	// Detect menyimpan kandidat baru dengan skor minimal minScore. Jika
+	// patientID diisi, hanya pasangan yang melibatkan pasien tersebut.
+	Detect(ctx context.Context, patientID *uuid.UUID, minScore float64) (int64, error)
+	// Flag menyimpan kandidat untuk pasangan yang ditemukan di luar Detect.
+	Flag(ctx context.Context, a, b uuid.UUID, score float64, reasons []string) error
+	FindByID(ctx context.Context, id uuid.UUID) (*duplicate.Candidate, error)
+	FindPaginated(ctx context.Context, status string, page, limit int) ([]duplicate.Candidate, int64, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, reviewedBy *uuid.UUID) error
+	// Merge memindahkan seluruh data mergedID ke survivorID dalam satu transaksi.
+	Merge(ctx context.Context, survivorID, mergedID uuid.UUID, candidateID, mergedBy *uuid.UUID) (*duplicate.Merge, error)
+	FindMerges(ctx context.Context, survivorID uuid.UUID) ([]duplicate.Merge, error)
+}
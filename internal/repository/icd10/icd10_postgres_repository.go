@@ -0,0 +1,89 @@
+package icd10
+
+import (
+	"context"
+	"strings"
+	"time"
+	"v2/internal/domain/icd10"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ICD10PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewICD10PostgresRepository(db *pgxpool.Pool) *ICD10PostgresRepository {
+	return &ICD10PostgresRepository{db: db}
+}
+
+// Search mencari berdasarkan awalan kode atau potongan nama (Indonesia/Inggris).
+// Kecocokan kode ditampilkan lebih dulu.
+func (r *ICD10PostgresRepository) Search(ctx context.Context, query string, limit int) ([]icd10.Code, error) {
+	q := escapeLike(strings.TrimSpace(query))
+	rows, err := r.db.Query(ctx, `SELECT code, name_en, name_id FROM icd10_codes
+		WHERE code ILIKE $1 || '%' OR name_id ILIKE '%' || $1 || '%' OR name_en ILIKE '%' || $1 || '%'
+		ORDER BY (code ILIKE $1 || '%') DESC, code
+		LIMIT $2`, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []icd10.Code
+	for rows.Next() {
+		var c icd10.Code
+		if err := rows.Scan(&c.Code, &c.NameEN, &c.NameID); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// escapeLike meng-escape wildcard LIKE agar "%" dan "_" pada query dicari
+// apa adanya (escape bawaan Postgres adalah backslash).
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func (r *ICD10PostgresRepository) FindByCodes(ctx context.Context, codes []string) ([]icd10.Code, error) {
+	rows, err := r.db.Query(ctx, `SELECT code, name_en, name_id FROM icd10_codes WHERE code = ANY($1)`, codes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []icd10.Code
+	for rows.Next() {
+		var c icd10.Code
+		if err := rows.Scan(&c.Code, &c.NameEN, &c.NameID); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// CountDiagnoses menghitung diagnosis per kode untuk konsultasi yang dibuat
+// dalam rentang [from, to).
+func (r *ICD10PostgresRepository) CountDiagnoses(ctx context.Context, from, to time.Time) ([]icd10.DiagnosisCount, error) {
+	rows, err := r.db.Query(ctx, `SELECT d.code, i.name_en, i.name_id, COUNT(*) FILTER (WHERE d.is_primary), COUNT(*)
+		FROM consultation_diagnoses d
+		JOIN consultations c ON c.id = d.consultation_id
+		JOIN icd10_codes i ON i.code = d.code
+		WHERE c.created_at >= $1 AND c.created_at < $2
+		GROUP BY d.code, i.name_en, i.name_id
+		ORDER BY COUNT(*) DESC, d.code`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []icd10.DiagnosisCount
+	for rows.Next() {
+		var c icd10.DiagnosisCount
+		if err := rows.Scan(&c.Code, &c.NameEN, &c.NameID, &c.Primary, &c.Total); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,13 @@
+package icd10
+
+import (
+	"context"
+	"time"
+	"v2/internal/domain/icd10"
+)
+
+type ICD10Repository interface {
+	Search(ctx context.Context, query string, limit int) ([]icd10.Code, error)
+	FindByCodes(ctx context.Context, codes []string) ([]icd10.Code, error)
+	CountDiagnoses(ctx context.Context, from, to time.Time) ([]icd10.DiagnosisCount, error)
+}
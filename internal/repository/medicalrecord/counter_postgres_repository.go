@@ -14,13 +14,13 @@ func NewCounterPostgresRepository(db *pgxpool.Pool) *CounterPostgresRepository {
 	return &CounterPostgresRepository{db: db}
 }
 
-func (r *CounterPostgresRepository) GetNextSequence(ctx context.Context, name string) (int, error) {
+func (r *CounterPostgresRepository) GetNextSequence(ctx context.Context, name string) (int64, error) {
 	// Upsert counter row, increment seq, return new value
 	_, err := r.db.Exec(ctx, `INSERT INTO counters (name, seq) VALUES ($1, 1) ON CONFLICT (name) DO UPDATE SET seq = counters.seq + 1`, name)
 	if err != nil {
 		return 0, err
 	}
-	var seq int
+	var seq int64
 	row := r.db.QueryRow(ctx, `SELECT seq FROM counters WHERE name=$1`, name)
 	if err := row.Scan(&seq); err != nil {
 		return 0, err
@@ -2,9 +2,11 @@ package medicalrecord
 
 import (
 	"context"
+	"errors"
 	"v2/internal/domain/medicalrecord"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -24,10 +26,24 @@ func (r *MedicalRecordPostgresRepository) Create(ctx context.Context, mr *medica
 	return err
 }
 
+// FindByPatientID mengembalikan MR tertua; pasien hasil merge bisa memiliki lebih dari satu.
 func (r *MedicalRecordPostgresRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID) (*medicalrecord.MedicalRecord, error) {
-	row := r.db.QueryRow(ctx, `SELECT id, patient_id, mr_number, created_at FROM medical_records WHERE patient_id=$1`, patientID)
+	row := r.db.QueryRow(ctx, `SELECT id, patient_id, mr_number, created_at FROM medical_records WHERE patient_id=$1 ORDER BY created_at ASC LIMIT 1`, patientID)
+	return scanMedicalRecord(row)
+}
+
+func (r *MedicalRecordPostgresRepository) FindByMRNumber(ctx context.Context, mrNumber string) (*medicalrecord.MedicalRecord, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, patient_id, mr_number, created_at FROM medical_records WHERE mr_number=$1`, mrNumber)
+	return scanMedicalRecord(row)
+}
+
+// scanMedicalRecord mengembalikan nil tanpa error jika MR belum ada.
+func scanMedicalRecord(row pgx.Row) (*medicalrecord.MedicalRecord, error) {
 	var mr medicalrecord.MedicalRecord
 	if err := row.Scan(&mr.ID, &mr.PatientID, &mr.MRNumber, &mr.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &mr, nil
@@ -5,6 +5,7 @@ import (
 	"v2/internal/domain/medicine"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -24,13 +25,20 @@ func (r *MedicinePostgresRepository) Create(ctx context.Context, m *medicine.Med
 	return err
 }
 
+// Update mengubah data obat aktif; pgx.ErrNoRows jika obat tidak ada atau sudah terhapus.
 func (r *MedicinePostgresRepository) Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
-	_, err := r.db.Exec(ctx, `UPDATE medicines SET barcode=$1, medicine_name=$2, brand_name=$3, category=$4, dosage=$5, content=$6, quantity=$7, updated_at=NOW() WHERE id=$8`, update["barcode"], update["medicine_name"], update["brand_name"], update["category"], update["dosage"], update["content"], update["quantity"], id)
-	return err
+	tag, err := r.db.Exec(ctx, `UPDATE medicines SET barcode=$1, medicine_name=$2, brand_name=$3, category=$4, dosage=$5, content=$6, quantity=$7, updated_at=NOW() WHERE id=$8 AND deleted_at IS NULL`, update["barcode"], update["medicine_name"], update["brand_name"], update["category"], update["dosage"], update["content"], update["quantity"], id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
 func (r *MedicinePostgresRepository) FindAll(ctx context.Context) ([]medicine.Medicine, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, barcode, medicine_name, brand_name, category, dosage, content, quantity, created_at, updated_at FROM medicines`)
+	rows, err := r.db.Query(ctx, `SELECT id, barcode, medicine_name, brand_name, category, dosage, content, quantity, created_at, updated_at FROM medicines WHERE deleted_at IS NULL`)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +56,7 @@ func (r *MedicinePostgresRepository) FindAll(ctx context.Context) ([]medicine.Me
 
 func (r *MedicinePostgresRepository) FindAllPaginated(ctx context.Context, page, limit int) ([]medicine.Medicine, int64, error) {
 	offset := (page - 1) * limit
-	rows, err := r.db.Query(ctx, `SELECT id, barcode, medicine_name, brand_name, category, dosage, content, quantity, created_at, updated_at FROM medicines ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+	rows, err := r.db.Query(ctx, `SELECT id, barcode, medicine_name, brand_name, category, dosage, content, quantity, created_at, updated_at FROM medicines WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -63,9 +71,39 @@ func (r *MedicinePostgresRepository) FindAllPaginated(ctx context.Context, page,
 	}
 	// Hitung total
 	var total int64
-	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM medicines`)
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM medicines WHERE deleted_at IS NULL`)
 	if err := row.Scan(&total); err != nil {
 		return nil, 0, err
 	}
 	return result, total, nil
 }
+
+// SoftDelete menandai obat terhapus; pgx.ErrNoRows jika tidak ada obat aktif.
+func (r *MedicinePostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE medicines SET deleted_at=NOW(), deleted_by=$1 WHERE id=$2 AND deleted_at IS NULL`, deletedBy, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Restore memulihkan obat terhapus; pgx.ErrNoRows jika obat tidak sedang terhapus.
+func (r *MedicinePostgresRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE medicines SET deleted_at=NULL, deleted_by=NULL WHERE id=$1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *MedicinePostgresRepository) CountOutOfStock(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM medicines WHERE deleted_at IS NULL AND quantity <= 0`).Scan(&total)
+	return total, err
+}
@@ -3,11 +3,16 @@ package medicine
 import (
 	"context"
 	"v2/internal/domain/medicine"
+
+	"github.com/google/uuid"
 )
 
 type MedicineRepository interface {
 	Create(ctx context.Context, medicine *medicine.Medicine) error
-	Update(ctx context.Context, id string, update map[string]interface{}) error
+	Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error
 	FindAll(ctx context.Context) ([]medicine.Medicine, error)
 	FindAllPaginated(ctx context.Context, page, limit int) ([]medicine.Medicine, int64, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	CountOutOfStock(ctx context.Context) (int64, error)
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"v2/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ParamedicPostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewParamedicPostgresRepository(db *pgxpool.Pool) *ParamedicPostgresRepository {
+	return &ParamedicPostgresRepository{db: db}
+}
+
+func (r *ParamedicPostgresRepository) Create(ctx context.Context, p *domain.Paramedic) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO paramedics (id, user_id, full_name, nik, phone_number, address) VALUES ($1, $2, $3, $4, $5, $6)`,
+		p.ID, p.UserID, p.FullName, p.NIK, p.PhoneNumber, p.Address)
+	return err
+}
+
+func (r *ParamedicPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Paramedic, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, '') FROM paramedics WHERE id=$1`, id)
+	var p domain.Paramedic
+	if err := row.Scan(&p.ID, &p.UserID, &p.FullName, &p.NIK, &p.PhoneNumber, &p.Address); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *ParamedicPostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Paramedic, error) {
+	row := r.db.QueryRow(ctx, `SELECT id, user_id, full_name, COALESCE(nik, ''), COALESCE(phone_number, ''), COALESCE(address, '') FROM paramedics WHERE user_id=$1`, userID)
+	var p domain.Paramedic
+	if err := row.Scan(&p.ID, &p.UserID, &p.FullName, &p.NIK, &p.PhoneNumber, &p.Address); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"v2/internal/domain"
+
+	"github.com/google/uuid"
 )
 
 type ParamedicRepository interface {
 	Create(ctx context.Context, paramedic *domain.Paramedic) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.Paramedic, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*domain.Paramedic, error)
 }
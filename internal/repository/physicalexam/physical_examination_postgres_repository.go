@@ -5,6 +5,7 @@ import (
 	"v2/internal/domain/physicalexam"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,55 +17,95 @@ func NewPhysicalExaminationPostgresRepository(db *pgxpool.Pool) *PhysicalExamina
 	return &PhysicalExaminationPostgresRepository{db: db}
 }
 
+const examColumns = `id, patient_id, paramedis_id, doctor_id, blood_pressure, systolic, diastolic, heart_rate, oxygen_saturation, respiratory_rate, body_temperature, physical_assessment, reason, medical_advice, health_status, pendampingan, konsultasi_dokter, konsultasi_dokter_status, doctor_advice, abnormal_flags, created_at, updated_at`
+
 func (r *PhysicalExaminationPostgresRepository) Create(ctx context.Context, exam *physicalexam.PhysicalExamination) error {
 	if exam.ID == uuid.Nil {
 		exam.ID = uuid.New()
 	}
-	_, err := r.db.Exec(ctx, `INSERT INTO physical_examinations (id, patient_id, paramedis_id, doctor_id, blood_pressure, heart_rate, oxygen_saturation, respiratory_rate, body_temperature, physical_assessment, reason, medical_advice, health_status, pendampingan, konsultasi_dokter, konsultasi_dokter_status, doctor_advice, created_at, updated_at) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)`, exam.ID, exam.PatientID, exam.ParamedisID, exam.DoctorID, exam.BloodPressure, exam.HeartRate, exam.OxygenSaturation, exam.RespiratoryRate, exam.BodyTemperature, exam.PhysicalAssessment, exam.Reason, exam.MedicalAdvice, exam.HealthStatus, exam.Pendampingan, exam.KonsultasiDokter, exam.KonsultasiDokterStatus, exam.DoctorAdvice, exam.CreatedAt, exam.UpdatedAt)
+	_, err := r.db.Exec(ctx, `INSERT INTO physical_examinations (`+examColumns+`) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)`, exam.ID, exam.PatientID, exam.ParamedisID, exam.DoctorID, exam.BloodPressure, exam.Systolic, exam.Diastolic, exam.HeartRate, exam.OxygenSaturation, exam.RespiratoryRate, exam.BodyTemperature, exam.PhysicalAssessment, exam.Reason, exam.MedicalAdvice, exam.HealthStatus, exam.Pendampingan, exam.KonsultasiDokter, exam.KonsultasiDokterStatus, exam.DoctorAdvice, flagsOrEmpty(exam.AbnormalFlags), exam.CreatedAt, exam.UpdatedAt)
 	return err
 }
 
+func (r *PhysicalExaminationPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*physicalexam.PhysicalExamination, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+examColumns+` FROM physical_examinations WHERE id=$1 AND deleted_at IS NULL`, id)
+	return scanExam(row)
+}
+
 func (r *PhysicalExaminationPostgresRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]physicalexam.PhysicalExamination, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, patient_id, paramedis_id, doctor_id, blood_pressure, heart_rate, oxygen_saturation, respiratory_rate, body_temperature, physical_assessment, reason, medical_advice, health_status, pendampingan, konsultasi_dokter, konsultasi_dokter_status, doctor_advice, created_at, updated_at FROM physical_examinations WHERE patient_id=$1`, patientID)
+	rows, err := r.db.Query(ctx, `SELECT `+examColumns+` FROM physical_examinations WHERE patient_id=$1 AND deleted_at IS NULL ORDER BY created_at DESC`, patientID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var result []physicalexam.PhysicalExamination
 	for rows.Next() {
-		var e physicalexam.PhysicalExamination
-		if err := rows.Scan(&e.ID, &e.PatientID, &e.ParamedisID, &e.DoctorID, &e.BloodPressure, &e.HeartRate, &e.OxygenSaturation, &e.RespiratoryRate, &e.BodyTemperature, &e.PhysicalAssessment, &e.Reason, &e.MedicalAdvice, &e.HealthStatus, &e.Pendampingan, &e.KonsultasiDokter, &e.KonsultasiDokterStatus, &e.DoctorAdvice, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		e, err := scanExam(rows)
+		if err != nil {
 			return nil, err
 		}
-		result = append(result, e)
+		result = append(result, *e)
 	}
 	return result, nil
 }
 
-func (r *PhysicalExaminationPostgresRepository) FindDoctorConsultations(ctx context.Context) ([]physicalexam.PhysicalExamination, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, patient_id, paramedis_id, doctor_id, blood_pressure, heart_rate, oxygen_saturation, respiratory_rate, body_temperature, physical_assessment, reason, medical_advice, health_status, pendampingan, konsultasi_dokter, konsultasi_dokter_status, doctor_advice, created_at, updated_at FROM physical_examinations WHERE konsultasi_dokter=true`)
+// SyncConsultation menyalin status dan hasil konsultasi dokter ke pemeriksaan.
+// doctorID dan doctorAdvice hanya ditimpa jika diisi.
+func (r *PhysicalExaminationPostgresRepository) SyncConsultation(ctx context.Context, id uuid.UUID, status string, doctorID *uuid.UUID, doctorAdvice string) error {
+	_, err := r.db.Exec(ctx, `UPDATE physical_examinations SET konsultasi_dokter=true, konsultasi_dokter_status=$1, doctor_id=COALESCE($2, doctor_id), doctor_advice=COALESCE(NULLIF($3, ''), doctor_advice), updated_at=NOW() WHERE id=$4`, status, doctorID, doctorAdvice, id)
+	return err
+}
+
+// Update mengubah tanda vital dan hasil pemeriksaan aktif; pgx.ErrNoRows jika
+// pemeriksaan tidak ada atau sudah terhapus.
+func (r *PhysicalExaminationPostgresRepository) Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
+	tag, err := r.db.Exec(ctx, `UPDATE physical_examinations SET blood_pressure=$1, systolic=$2, diastolic=$3, heart_rate=$4, oxygen_saturation=$5, respiratory_rate=$6, body_temperature=$7, physical_assessment=$8, reason=$9, medical_advice=$10, health_status=$11, pendampingan=$12, konsultasi_dokter=$13, konsultasi_dokter_status=$14, doctor_advice=$15, abnormal_flags=$16, updated_at=NOW() WHERE id=$17 AND deleted_at IS NULL`, update["blood_pressure"], update["systolic"], update["diastolic"], update["heart_rate"], update["oxygen_saturation"], update["respiratory_rate"], update["body_temperature"], update["physical_assessment"], update["reason"], update["medical_advice"], update["health_status"], update["pendampingan"], update["konsultasi_dokter"], update["konsultasi_dokter_status"], update["doctor_advice"], update["abnormal_flags"], id)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
-	var result []physicalexam.PhysicalExamination
-	for rows.Next() {
-		var e physicalexam.PhysicalExamination
-		if err := rows.Scan(&e.ID, &e.PatientID, &e.ParamedisID, &e.DoctorID, &e.BloodPressure, &e.HeartRate, &e.OxygenSaturation, &e.RespiratoryRate, &e.BodyTemperature, &e.PhysicalAssessment, &e.Reason, &e.MedicalAdvice, &e.HealthStatus, &e.Pendampingan, &e.KonsultasiDokter, &e.KonsultasiDokterStatus, &e.DoctorAdvice, &e.CreatedAt, &e.UpdatedAt); err != nil {
-			return nil, err
-		}
-		result = append(result, e)
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
 	}
-	return result, nil
+	return nil
 }
 
-func (r *PhysicalExaminationPostgresRepository) UpdateConsultationStatus(ctx context.Context, id uuid.UUID, status string) error {
-	_, err := r.db.Exec(ctx, `UPDATE physical_examinations SET konsultasi_dokter_status=$1 WHERE id=$2`, status, id)
-	return err
+// SoftDelete menandai pemeriksaan terhapus; pgx.ErrNoRows jika tidak ada pemeriksaan aktif.
+func (r *PhysicalExaminationPostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE physical_examinations SET deleted_at=NOW(), deleted_by=$1 WHERE id=$2 AND deleted_at IS NULL`, deletedBy, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
-func (r *PhysicalExaminationPostgresRepository) Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
-	// Sederhana: hanya update beberapa field
-	_, err := r.db.Exec(ctx, `UPDATE physical_examinations SET blood_pressure=$1, heart_rate=$2, oxygen_saturation=$3, respiratory_rate=$4, body_temperature=$5, physical_assessment=$6, reason=$7, medical_advice=$8, health_status=$9, pendampingan=$10, konsultasi_dokter=$11, konsultasi_dokter_status=$12, doctor_advice=$13, updated_at=NOW() WHERE id=$14`, update["blood_pressure"], update["heart_rate"], update["oxygen_saturation"], update["respiratory_rate"], update["body_temperature"], update["physical_assessment"], update["reason"], update["medical_advice"], update["health_status"], update["pendampingan"], update["konsultasi_dokter"], update["konsultasi_dokter_status"], update["doctor_advice"], id)
-	return err
+// Restore memulihkan pemeriksaan terhapus; pgx.ErrNoRows jika pemeriksaan tidak sedang terhapus.
+func (r *PhysicalExaminationPostgresRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE physical_examinations SET deleted_at=NULL, deleted_by=NULL WHERE id=$1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func scanExam(row interface {
+	Scan(dest ...interface{}) error
+}) (*physicalexam.PhysicalExamination, error) {
+	var e physicalexam.PhysicalExamination
+	if err := row.Scan(&e.ID, &e.PatientID, &e.ParamedisID, &e.DoctorID, &e.BloodPressure, &e.Systolic, &e.Diastolic, &e.HeartRate, &e.OxygenSaturation, &e.RespiratoryRate, &e.BodyTemperature, &e.PhysicalAssessment, &e.Reason, &e.MedicalAdvice, &e.HealthStatus, &e.Pendampingan, &e.KonsultasiDokter, &e.KonsultasiDokterStatus, &e.DoctorAdvice, &e.AbnormalFlags, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func flagsOrEmpty(flags []string) []string {
+	if flags == nil {
+		return []string{}
+	}
+	return flags
 }
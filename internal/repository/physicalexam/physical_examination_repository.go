@@ -3,12 +3,16 @@ package physicalexam
 import (
 	"context"
 	"v2/internal/domain/physicalexam"
+
+	"github.com/google/uuid"
 )
 
 type PhysicalExaminationRepository interface {
 	Create(ctx context.Context, exam *physicalexam.PhysicalExamination) error
-	FindByPatientID(ctx context.Context, patientID string) ([]physicalexam.PhysicalExamination, error)
-	FindDoctorConsultations(ctx context.Context) ([]physicalexam.PhysicalExamination, error)
-	UpdateConsultationStatus(ctx context.Context, id string, status string) error
-	Update(ctx context.Context, id string, update map[string]interface{}) error
+	FindByID(ctx context.Context, id uuid.UUID) (*physicalexam.PhysicalExamination, error)
+	FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]physicalexam.PhysicalExamination, error)
+	SyncConsultation(ctx context.Context, id uuid.UUID, status string, doctorID *uuid.UUID, doctorAdvice string) error
+	Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 }
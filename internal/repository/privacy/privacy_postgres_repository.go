@@ -0,0 +1,278 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"v2/internal/domain/privacy"
+	"v2/internal/fieldcrypt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PrivacyPostgresRepository struct {
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+func NewPrivacyPostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *PrivacyPostgresRepository {
+	return &PrivacyPostgresRepository{db: db, cipher: cipher}
+}
+
+const requestColumns = `id, patient_id, type, status, COALESCE(reason, ''), requested_by, COALESCE(file_path, ''), report, COALESCE(error, ''), created_at, completed_at`
+
+func (r *PrivacyPostgresRepository) Create(ctx context.Context, req *privacy.Request) error {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO privacy_requests (id, patient_id, type, status, reason, requested_by, file_path, report, error, created_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), $10, $11)`,
+		req.ID, req.PatientID, req.Type, req.Status, req.Reason, req.RequestedBy, req.FilePath, nullJSON(req.Report), req.Error, req.CreatedAt, req.CompletedAt)
+	return err
+}
+
+func (r *PrivacyPostgresRepository) Finish(ctx context.Context, req *privacy.Request) error {
+	_, err := r.db.Exec(ctx, `UPDATE privacy_requests SET status=$1, file_path=NULLIF($2, ''), report=$3, error=NULLIF($4, ''), completed_at=$5 WHERE id=$6`,
+		req.Status, req.FilePath, nullJSON(req.Report), req.Error, req.CompletedAt, req.ID)
+	return err
+}
+
+func (r *PrivacyPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*privacy.Request, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+requestColumns+` FROM privacy_requests WHERE id=$1`, id)
+	return scanRequest(row)
+}
+
+func (r *PrivacyPostgresRepository) FindPaginated(ctx context.Context, patientID *uuid.UUID, reqType, status string, page, limit int) ([]privacy.Request, int64, error) {
+	offset := (page - 1) * limit
+	where := ` WHERE ($1::uuid IS NULL OR patient_id=$1) AND ($2 = '' OR type=$2) AND ($3 = '' OR status=$3)`
+	rows, err := r.db.Query(ctx, `SELECT `+requestColumns+` FROM privacy_requests`+where+` ORDER BY created_at DESC LIMIT $4 OFFSET $5`, patientID, reqType, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []privacy.Request
+	for rows.Next() {
+		req, err := scanRequest(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, *req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM privacy_requests`+where, patientID, reqType, status)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+func (r *PrivacyPostgresRepository) FailInterrupted(ctx context.Context, message string) (int64, error) {
+	tag, err := r.db.Exec(ctx, `UPDATE privacy_requests SET status='failed', error=$1, completed_at=NOW() WHERE status IN ('pending', 'processing')`, message)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// erasedFields adalah kolom identitas pasien yang dikosongkan. Jenis kelamin,
+// umur, golongan darah, tinggi, berat dan kecamatan disimpan untuk statistik.
+var erasedFields = []string{"nik", "full_name", "birth_place", "birth_date", "address", "rt", "rw", "village", "religion", "marital", "job", "nationality", "valid_until", "email", "phone", "ktp_images", "user_id"}
+
+var keptFields = []string{"gender", "age", "blood_type", "height", "weight", "district", "created_at"}
+
+// erasedName menggantikan full_name yang wajib terisi.
+const erasedName = "ANONIM"
+
+// retainedTables berisi data klinis yang wajib disimpan dan tidak dianonimkan.
+var retainedTables = []privacy.Retained{
+	{Table: "medical_records", Reason: "Rekam medis wajib disimpan fasilitas kesehatan (Permenkes 24/2022)"},
+	{Table: "physical_examinations", Reason: "Bagian dari rekam medis (Permenkes 24/2022)"},
+	{Table: "consultations", Reason: "Bagian dari rekam medis (Permenkes 24/2022)"},
+	{Table: "certificates", Reason: "Dokumen sertifikat yang sudah diterbitkan; nama dan NIK pada sertifikat tetap tersimpan sebagai bukti penerbitan"},
+}
+
+// Erase mengembalikan pgx.ErrNoRows jika pasien tidak ada, sudah digabung
+// ke pasien lain, atau sudah dianonimkan.
+func (r *PrivacyPostgresRepository) Erase(ctx context.Context, patientID uuid.UUID, erasedBy *uuid.UUID) (*privacy.ErasureReport, []string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var nikIndex string
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(nik_bidx, '') FROM patients WHERE id=$1 AND merged_into IS NULL AND erased_at IS NULL FOR UPDATE`, patientID).Scan(&nikIndex); err != nil {
+		return nil, nil, err
+	}
+
+	// Pasien yang pernah digabung ke pasien ini adalah orang yang sama
+	rows, err := tx.Query(ctx, `WITH RECURSIVE m AS (
+			SELECT id FROM patients WHERE id=$1
+			UNION SELECT p.id FROM patients p JOIN m ON p.merged_into = m.id)
+		SELECT p.id, COALESCE(p.ktp_images, '{}'), p.user_id FROM patients p JOIN m ON m.id = p.id`, patientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ids, userIDs []uuid.UUID
+	var files []string
+	for rows.Next() {
+		var id uuid.UUID
+		var ktp []string
+		var userID *uuid.UUID
+		if err := rows.Scan(&id, &ktp, &userID); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		files = append(files, ktp...)
+		if userID != nil {
+			userIDs = append(userIDs, *userID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	report := &privacy.ErasureReport{
+		PatientIDs:    ids,
+		FieldsRemoved: erasedFields,
+		FieldsKept:    keptFields,
+		Anonymized:    map[string]int64{},
+	}
+
+	for _, table := range []string{"screening_answers", "screening_queues"} {
+		var n int64
+		if nikIndex != "" {
+			if n, err = r.eraseScreening(ctx, tx, table, nikIndex); err != nil {
+				return nil, nil, err
+			}
+		}
+		report.Anonymized[table] = n
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE patients SET
+		nik=NULL, nik_bidx=NULL, nik_last4_bidx=NULL, full_name=$1, birth_place=NULL, birth_date=NULL, address=NULL, rt=NULL, rw=NULL,
+		village=NULL, religion=NULL, marital=NULL, job=NULL, nationality=NULL, valid_until=NULL,
+		email=NULL, email_bidx=NULL, phone=NULL, phone_bidx=NULL, phone_last4_bidx=NULL, ktp_images=NULL, user_id=NULL,
+		erased_at=NOW(), erased_by=$2,
+		deleted_at=COALESCE(deleted_at, NOW()), deleted_by=COALESCE(deleted_by, $2), updated_at=NOW()
+		WHERE id = ANY($3)`, erasedName, erasedBy, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Anonymized["patients"] = tag.RowsAffected()
+
+	// Akun login pasien tidak dihapus karena dirujuk tabel lain, tetapi email
+	// diganti dan password dikosongkan sehingga tidak bisa dipakai login.
+	tag, err = tx.Exec(ctx, `UPDATE users SET email='erased-' || id || '@invalid', password='', totp_secret=NULL, totp_enabled_at=NULL WHERE id = ANY($1) AND role='pasien'`, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Anonymized["users"] = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `UPDATE login_events SET email='', ip='', user_agent='' WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Anonymized["login_events"] = tag.RowsAffected()
+
+	// Snapshot merge berisi identitas pasien yang digabung
+	tag, err = tx.Exec(ctx, `UPDATE patient_merges SET merged_snapshot = merged_snapshot - $1::text[] WHERE survivor_id = ANY($2) OR merged_id = ANY($2)`,
+		append(erasedFields, "nik_bidx", "phone_bidx", "email_bidx", "nik_last4_bidx", "phone_last4_bidx"), ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Anonymized["patient_merges"] = tag.RowsAffected()
+
+	tag, err = tx.Exec(ctx, `UPDATE patient_duplicate_candidates SET status='dismissed', reviewed_by=$1, reviewed_at=NOW() WHERE status='pending' AND (patient_id = ANY($2) OR candidate_id = ANY($2))`, erasedBy, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Anonymized["patient_duplicate_candidates"] = tag.RowsAffected()
+
+	for _, t := range retainedTables {
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM `+t.Table+` WHERE patient_id = ANY($1)`, ids).Scan(&t.Rows); err != nil {
+			return nil, nil, err
+		}
+		report.Retained = append(report.Retained, t)
+	}
+	report.Retained = append(report.Retained, privacy.Retained{Table: "audit_logs", Reason: "Log audit bersifat append-only dan tetap mencatat siapa mengakses atau mengubah data pasien ini; NIK, nama, tempat/tanggal lahir, alamat, kontak, data KTP dan patient_info disamarkan saat dicatat sehingga log tidak memuat nilainya"})
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	return report, files, nil
+}
+
+// eraseScreening menyisakan jenis kelamin, umur dan golongan darah di
+// patient_info agar hasil skrining tetap bisa dipakai untuk statistik.
+func (r *PrivacyPostgresRepository) eraseScreening(ctx context.Context, tx pgx.Tx, table, nikIndex string) (int64, error) {
+	rows, err := tx.Query(ctx, `SELECT id, patient_info FROM `+table+` WHERE nik_bidx=$1 FOR UPDATE`, nikIndex)
+	if err != nil {
+		return 0, err
+	}
+	type item struct {
+		id   uuid.UUID
+		info map[string]interface{}
+	}
+	var items []item
+	for rows.Next() {
+		var it item
+		var data []byte
+		if err := rows.Scan(&it.id, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := r.cipher.DecryptJSON(data, &it.info); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		items = append(items, it)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, it := range items {
+		kept := map[string]interface{}{"full_name": erasedName}
+		for _, key := range []string{"gender", "age", "blood_type"} {
+			if v, ok := it.info[key]; ok {
+				kept[key] = v
+			}
+		}
+		data, err := r.cipher.EncryptJSON(kept)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE `+table+` SET patient_info=$1, nik_bidx=NULL WHERE id=$2`, data, it.id); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(items)), nil
+}
+
+func scanRequest(row interface {
+	Scan(dest ...interface{}) error
+}) (*privacy.Request, error) {
+	var req privacy.Request
+	var report []byte
+	if err := row.Scan(&req.ID, &req.PatientID, &req.Type, &req.Status, &req.Reason, &req.RequestedBy, &req.FilePath, &report, &req.Error, &req.CreatedAt, &req.CompletedAt); err != nil {
+		return nil, err
+	}
+	req.Report = report
+	return &req, nil
+}
+
+// nullJSON menyimpan NULL alih-alih JSON kosong.
+func nullJSON(b json.RawMessage) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
@@ -0,0 +1,21 @@
+package privacy
+
+import (
+	"context"
+	"v2/internal/domain/privacy"
+
+	"github.com/google/uuid"
+)
+
+type PrivacyRepository interface {
+	Create(ctx context.Context, req *privacy.Request) error
+	// Finish menyimpan status akhir, file, laporan dan error permintaan.
+	Finish(ctx context.Context, req *privacy.Request) error
+	FindByID(ctx context.Context, id uuid.UUID) (*privacy.Request, error)
+	FindPaginated(ctx context.Context, patientID *uuid.UUID, reqType, status string, page, limit int) ([]privacy.Request, int64, error)
+	// FailInterrupted menandai permintaan yang terputus karena server berhenti.
+	FailInterrupted(ctx context.Context, message string) (int64, error)
+	// Erase menganonimkan pasien dalam satu transaksi dan mengembalikan
+	// laporan beserta path file KTP yang harus dihapus.
+	Erase(ctx context.Context, patientID uuid.UUID, erasedBy *uuid.UUID) (*privacy.ErasureReport, []string, error)
+}
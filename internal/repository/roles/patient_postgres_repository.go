@@ -2,41 +2,97 @@ package roles
 
 import (
 	"context"
+	"strings"
 	"v2/internal/domain/roles"
+	"v2/internal/fieldcrypt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// NIK, telepon, email dan alamat disimpan terenkripsi; pencarian nilai persis
+// memakai kolom blind index nik_bidx, phone_bidx dan email_bidx, dan pencarian
+// 4 digit terakhir memakai nik_last4_bidx dan phone_last4_bidx.
 type PatientPostgresRepository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
 }
 
-func NewPatientPostgresRepository(db *pgxpool.Pool) *PatientPostgresRepository {
-	return &PatientPostgresRepository{db: db}
+func NewPatientPostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *PatientPostgresRepository {
+	return &PatientPostgresRepository{db: db, cipher: cipher}
 }
 
+// sealedPatient berisi nilai kolom terenkripsi beserta blind index-nya.
+type sealedPatient struct {
+	NIK, Phone, Email, Address       string
+	NIKIndex, PhoneIndex, EmailIndex *string
+	NIKSuffixIndex, PhoneSuffixIndex *string
+}
+
+func (r *PatientPostgresRepository) seal(p *roles.Patient) (*sealedPatient, error) {
+	s := &sealedPatient{
+		NIKIndex:         nullIndex(r.cipher.NIKIndex(p.NIK)),
+		PhoneIndex:       nullIndex(r.cipher.PhoneIndex(p.Phone)),
+		EmailIndex:       nullIndex(r.cipher.EmailIndex(p.Email)),
+		NIKSuffixIndex:   nullIndex(r.cipher.NIKSuffixIndex(p.NIK)),
+		PhoneSuffixIndex: nullIndex(r.cipher.PhoneSuffixIndex(p.Phone)),
+	}
+	var err error
+	if s.NIK, err = r.cipher.Encrypt(p.NIK); err != nil {
+		return nil, err
+	}
+	if s.Phone, err = r.cipher.Encrypt(p.Phone); err != nil {
+		return nil, err
+	}
+	if s.Email, err = r.cipher.Encrypt(p.Email); err != nil {
+		return nil, err
+	}
+	if s.Address, err = r.cipher.Encrypt(p.Address); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// nullIndex menyimpan index kosong sebagai NULL agar tidak bentrok di unique index.
+func nullIndex(idx string) *string {
+	if idx == "" {
+		return nil
+	}
+	return &idx
+}
+
+// Kolom teks boleh NULL untuk data lama, sehingga dibaca sebagai string kosong.
+const patientColumns = `id, user_id, COALESCE(nik, ''), full_name, COALESCE(birth_place, ''), COALESCE(birth_date, ''), COALESCE(gender, ''), COALESCE(address, ''), COALESCE(rt, ''), COALESCE(rw, ''), COALESCE(village, ''), COALESCE(district, ''), COALESCE(religion, ''), COALESCE(marital, ''), COALESCE(job, ''), COALESCE(nationality, ''), COALESCE(valid_until, ''), COALESCE(blood_type, ''), COALESCE(height, 0), COALESCE(weight, 0), COALESCE(age, 0), COALESCE(email, ''), COALESCE(phone, ''), ktp_images, merged_into, created_at, updated_at`
+
 func (r *PatientPostgresRepository) Create(ctx context.Context, patient *roles.Patient) error {
 	if patient.ID == uuid.Nil {
 		patient.ID = uuid.New()
 	}
-	_, err := r.db.Exec(ctx, `INSERT INTO patients (
-		id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `INSERT INTO patients (
+		id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at, user_id, nik_bidx, phone_bidx, email_bidx, nik_last4_bidx, phone_last4_bidx
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31
 	)`,
-		patient.ID, patient.NIK, patient.FullName, patient.BirthPlace, patient.BirthDate, patient.Gender, patient.Address, patient.RT, patient.RW, patient.Village, patient.District, patient.Religion, patient.Marital, patient.Job, patient.Nationality, patient.ValidUntil, patient.BloodType, patient.Height, patient.Weight, patient.Age, patient.Email, patient.Phone, patient.KTPImages, patient.CreatedAt, patient.UpdatedAt)
+		patient.ID, sealed.NIK, patient.FullName, patient.BirthPlace, patient.BirthDate, patient.Gender, sealed.Address, patient.RT, patient.RW, patient.Village, patient.District, patient.Religion, patient.Marital, patient.Job, patient.Nationality, patient.ValidUntil, patient.BloodType, patient.Height, patient.Weight, patient.Age, sealed.Email, sealed.Phone, patient.KTPImages, patient.CreatedAt, patient.UpdatedAt, patient.UserID, sealed.NIKIndex, sealed.PhoneIndex, sealed.EmailIndex, sealed.NIKSuffixIndex, sealed.PhoneSuffixIndex)
 	return err
 }
 
+// CreateOrUpdateByNIK mengembalikan pgx.ErrNoRows jika NIK milik pasien yang
+// sudah dihapus; pasien tersebut harus dipulihkan dulu.
 func (r *PatientPostgresRepository) CreateOrUpdateByNIK(ctx context.Context, patient *roles.Patient) (*roles.Patient, error) {
-	// Upsert by NIK
+	// Upsert by NIK (lewat blind index karena kolom nik terenkripsi)
 	query := `INSERT INTO patients (
-		id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at
+		id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at, user_id, nik_bidx, phone_bidx, email_bidx, nik_last4_bidx, phone_last4_bidx
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31
 	)
-	ON CONFLICT (nik) DO UPDATE SET
+	ON CONFLICT (nik_bidx) DO UPDATE SET
+		nik=EXCLUDED.nik,
 		full_name=EXCLUDED.full_name,
 		birth_place=EXCLUDED.birth_place,
 		birth_date=EXCLUDED.birth_date,
@@ -57,16 +113,26 @@ func (r *PatientPostgresRepository) CreateOrUpdateByNIK(ctx context.Context, pat
 		age=EXCLUDED.age,
 		email=EXCLUDED.email,
 		phone=EXCLUDED.phone,
+		phone_bidx=EXCLUDED.phone_bidx,
+		email_bidx=EXCLUDED.email_bidx,
+		nik_last4_bidx=EXCLUDED.nik_last4_bidx,
+		phone_last4_bidx=EXCLUDED.phone_last4_bidx,
 		ktp_images=EXCLUDED.ktp_images,
+		user_id=COALESCE(patients.user_id, EXCLUDED.user_id),
 		updated_at=EXCLUDED.updated_at
-	RETURNING id`
+	WHERE patients.deleted_at IS NULL
+	RETURNING id, user_id`
 	if patient.ID == uuid.Nil {
 		patient.ID = uuid.New()
 	}
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return nil, err
+	}
 	row := r.db.QueryRow(ctx, query,
-		patient.ID, patient.NIK, patient.FullName, patient.BirthPlace, patient.BirthDate, patient.Gender, patient.Address, patient.RT, patient.RW, patient.Village, patient.District, patient.Religion, patient.Marital, patient.Job, patient.Nationality, patient.ValidUntil, patient.BloodType, patient.Height, patient.Weight, patient.Age, patient.Email, patient.Phone, patient.KTPImages, patient.CreatedAt, patient.UpdatedAt)
+		patient.ID, sealed.NIK, patient.FullName, patient.BirthPlace, patient.BirthDate, patient.Gender, sealed.Address, patient.RT, patient.RW, patient.Village, patient.District, patient.Religion, patient.Marital, patient.Job, patient.Nationality, patient.ValidUntil, patient.BloodType, patient.Height, patient.Weight, patient.Age, sealed.Email, sealed.Phone, patient.KTPImages, patient.CreatedAt, patient.UpdatedAt, patient.UserID, sealed.NIKIndex, sealed.PhoneIndex, sealed.EmailIndex, sealed.NIKSuffixIndex, sealed.PhoneSuffixIndex)
 	var id uuid.UUID
-	err := row.Scan(&id)
+	err = row.Scan(&id, &patient.UserID)
 	if err != nil {
 		return nil, err
 	}
@@ -74,20 +140,43 @@ func (r *PatientPostgresRepository) CreateOrUpdateByNIK(ctx context.Context, pat
 	return patient, nil
 }
 
+func (r *PatientPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*roles.Patient, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+patientColumns+` FROM patients WHERE id=$1 AND deleted_at IS NULL`, id)
+	return r.scanPatient(row)
+}
+
 func (r *PatientPostgresRepository) FindByNIK(ctx context.Context, nik string) (*roles.Patient, error) {
-	row := r.db.QueryRow(ctx, `SELECT id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at FROM patients WHERE nik=$1`, nik)
-	return scanPatient(row)
+	row := r.db.QueryRow(ctx, `SELECT `+patientColumns+` FROM patients WHERE nik_bidx=$1 AND deleted_at IS NULL`, r.cipher.NIKIndex(nik))
+	return r.scanPatient(row)
+}
+
+// FindByUserID mencari profil pasien milik akun login.
+func (r *PatientPostgresRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*roles.Patient, error) {
+	row := r.db.QueryRow(ctx, `SELECT `+patientColumns+` FROM patients WHERE user_id=$1 AND deleted_at IS NULL`, userID)
+	return r.scanPatient(row)
+}
+
+// UpdateProfile hanya menyimpan field yang boleh diubah pasien sendiri.
+// Data identitas sesuai KTP tetap diubah lewat CreateOrUpdateByNIK oleh staf.
+func (r *PatientPostgresRepository) UpdateProfile(ctx context.Context, patient *roles.Patient) error {
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `UPDATE patients SET phone=$1, address=$2, rt=$3, rw=$4, village=$5, district=$6, marital=$7, job=$8, height=$9, weight=$10, updated_at=$11, phone_bidx=$12, phone_last4_bidx=$13 WHERE id=$14 AND deleted_at IS NULL`,
+		sealed.Phone, sealed.Address, patient.RT, patient.RW, patient.Village, patient.District, patient.Marital, patient.Job, patient.Height, patient.Weight, patient.UpdatedAt, sealed.PhoneIndex, sealed.PhoneSuffixIndex, patient.ID)
+	return err
 }
 
 func (r *PatientPostgresRepository) FindAll(ctx context.Context) ([]roles.Patient, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at FROM patients`)
+	rows, err := r.db.Query(ctx, `SELECT `+patientColumns+` FROM patients WHERE merged_into IS NULL AND deleted_at IS NULL`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var result []roles.Patient
 	for rows.Next() {
-		p, err := scanPatient(rows)
+		p, err := r.scanPatient(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -98,14 +187,14 @@ func (r *PatientPostgresRepository) FindAll(ctx context.Context) ([]roles.Patien
 
 func (r *PatientPostgresRepository) FindAllPaginated(ctx context.Context, page, limit int) ([]roles.Patient, int64, error) {
 	offset := (page - 1) * limit
-	rows, err := r.db.Query(ctx, `SELECT id, nik, full_name, birth_place, birth_date, gender, address, rt, rw, village, district, religion, marital, job, nationality, valid_until, blood_type, height, weight, age, email, phone, ktp_images, created_at, updated_at FROM patients ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+	rows, err := r.db.Query(ctx, `SELECT `+patientColumns+` FROM patients WHERE merged_into IS NULL AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 	var result []roles.Patient
 	for rows.Next() {
-		p, err := scanPatient(rows)
+		p, err := r.scanPatient(rows)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -113,23 +202,152 @@ func (r *PatientPostgresRepository) FindAllPaginated(ctx context.Context, page,
 	}
 	// Hitung total
 	var total int64
-	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM patients`)
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM patients WHERE merged_into IS NULL AND deleted_at IS NULL`)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
+
+// SoftDelete menandai pasien terhapus; pgx.ErrNoRows jika tidak ada pasien aktif.
+func (r *PatientPostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE patients SET deleted_at=NOW(), deleted_by=$1 WHERE id=$2 AND deleted_at IS NULL`, deletedBy, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Restore memulihkan pasien terhapus; pgx.ErrNoRows jika pasien tidak sedang
+// terhapus atau sudah dianonimkan.
+func (r *PatientPostgresRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE patients SET deleted_at=NULL, deleted_by=NULL WHERE id=$1 AND deleted_at IS NOT NULL AND erased_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Parameter pencarian: $1 teks query, $2 query yang di-escape untuk LIKE,
+// $3..$5 blind index NIK/telepon/email dari query, $6..$7 blind index 4 digit
+// terakhir NIK/telepon, $8.. filter. Karena NIK, telepon dan email
+// terenkripsi, ketiganya cocok jika sama persis atau (NIK dan telepon) jika
+// query berisi tepat 4 digit terakhir. Prefix nomor RM dicocokkan lewat
+// upper(mr_number) LIKE agar bisa memakai idx_medical_records_mr_number_upper.
+const (
+	searchMatch = `($1 = '' OR full_name ILIKE '%' || $2 || '%' OR $1 <% full_name
+		OR nik_bidx = $3 OR phone_bidx = $4 OR email_bidx = $5
+		OR nik_last4_bidx = $6 OR phone_last4_bidx = $7
+		OR EXISTS (SELECT 1 FROM medical_records m WHERE m.patient_id = patients.id AND upper(m.mr_number) LIKE upper($2) || '%'))`
+	searchFilter = `merged_into IS NULL AND deleted_at IS NULL
+		AND ($8 = '' OR upper(gender) = upper($8))
+		AND ($9::int IS NULL OR age >= $9)
+		AND ($10::int IS NULL OR age <= $10)
+		AND ($11 = '' OR upper(blood_type) = upper($11))
+		AND ($12 = '' OR village ILIKE $12)
+		AND ($13 = '' OR district ILIKE $13)
+		AND ($14::timestamp IS NULL OR created_at >= $14)
+		AND ($15::timestamp IS NULL OR created_at < $15)`
+	searchScore = `GREATEST(
+		CASE WHEN $1 = '' THEN 0 ELSE word_similarity($1, full_name) END,
+		CASE WHEN nik_bidx = $3 THEN 1 WHEN phone_bidx = $4 OR email_bidx = $5 THEN 0.95
+			WHEN nik_last4_bidx = $6 OR phone_last4_bidx = $7 THEN 0.6 ELSE 0 END,
+		CASE WHEN $1 = '' THEN 0
+			WHEN EXISTS (SELECT 1 FROM medical_records m WHERE m.patient_id = patients.id AND upper(m.mr_number) = upper($1)) THEN 1
+			WHEN EXISTS (SELECT 1 FROM medical_records m WHERE m.patient_id = patients.id AND upper(m.mr_number) LIKE upper($2) || '%') THEN 0.8
+			ELSE 0 END)`
+)
+
+// Search mengurutkan hasil berdasarkan relevansi, lalu pasien terbaru.
+func (r *PatientPostgresRepository) Search(ctx context.Context, s roles.PatientSearch) ([]roles.PatientSearchResult, int64, error) {
+	idx := r.searchIndexes(s.Query)
+	args := []interface{}{s.Query, escapeLike(s.Query), idx.NIK, idx.Phone, idx.Email, idx.NIKSuffix, idx.PhoneSuffix, s.Gender, s.AgeMin, s.AgeMax, s.BloodType, s.Village, s.District, s.RegisteredFrom, s.RegisteredTo}
+	where := ` WHERE ` + searchFilter + ` AND ` + searchMatch
+	offset := (s.Page - 1) * s.Limit
+	rows, err := r.db.Query(ctx, `SELECT `+patientColumns+`,
+		COALESCE((SELECT m.mr_number FROM medical_records m WHERE m.patient_id = patients.id ORDER BY m.created_at LIMIT 1), ''),
+		`+searchScore+` AS score
+		FROM patients`+where+` ORDER BY score DESC, created_at DESC LIMIT $16 OFFSET $17`, append(args, s.Limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []roles.PatientSearchResult
+	for rows.Next() {
+		var res roles.PatientSearchResult
+		p, err := r.scanPatient(rows, &res.MRNumber, &res.Score)
+		if err != nil {
+			return nil, 0, err
+		}
+		res.Patient = *p
+		result = append(result, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM patients`+where, args...)
 	if err := row.Scan(&total); err != nil {
 		return nil, 0, err
 	}
 	return result, total, nil
 }
 
-func scanPatient(row interface {
+// escapeLike meng-escape karakter wildcard LIKE.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// searchIndexes berisi blind index dari query pencarian; nil berarti tidak
+// dicocokkan.
+type searchIndexes struct {
+	NIK, Phone, Email      *string
+	NIKSuffix, PhoneSuffix *string
+}
+
+// searchIndexes menghitung blind index dari query. Query angka dicocokkan ke
+// NIK dan telepon (4 digit dicocokkan ke 4 digit terakhir), query dengan "@"
+// ke email; sisanya tidak dicocokkan.
+func (r *PatientPostgresRepository) searchIndexes(q string) searchIndexes {
+	q = strings.TrimSpace(q)
+	if strings.Contains(q, "@") {
+		return searchIndexes{Email: nullIndex(r.cipher.EmailIndex(q))}
+	}
+	plain := strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimLeft(q, "+"))
+	if plain == "" || strings.Trim(plain, "0123456789") != "" {
+		return searchIndexes{}
+	}
+	if len(plain) == 4 {
+		return searchIndexes{NIKSuffix: nullIndex(r.cipher.NIKSuffixIndex(plain)), PhoneSuffix: nullIndex(r.cipher.PhoneSuffixIndex(plain))}
+	}
+	return searchIndexes{NIK: nullIndex(r.cipher.NIKIndex(plain)), Phone: nullIndex(r.cipher.PhoneIndex(plain))}
+}
+
+// scanPatient membaca patientColumns dan mendekripsi kolom terenkripsi; extra
+// untuk kolom tambahan setelahnya.
+func (r *PatientPostgresRepository) scanPatient(row interface {
 	Scan(dest ...interface{}) error
-}) (*roles.Patient, error) {
+}, extra ...interface{}) (*roles.Patient, error) {
 	var p roles.Patient
 	var ktpImages []string
-	err := row.Scan(
-		&p.ID, &p.NIK, &p.FullName, &p.BirthPlace, &p.BirthDate, &p.Gender, &p.Address, &p.RT, &p.RW, &p.Village, &p.District, &p.Religion, &p.Marital, &p.Job, &p.Nationality, &p.ValidUntil, &p.BloodType, &p.Height, &p.Weight, &p.Age, &p.Email, &p.Phone, &ktpImages, &p.CreatedAt, &p.UpdatedAt)
+	dest := []interface{}{
+		&p.ID, &p.UserID, &p.NIK, &p.FullName, &p.BirthPlace, &p.BirthDate, &p.Gender, &p.Address, &p.RT, &p.RW, &p.Village, &p.District, &p.Religion, &p.Marital, &p.Job, &p.Nationality, &p.ValidUntil, &p.BloodType, &p.Height, &p.Weight, &p.Age, &p.Email, &p.Phone, &ktpImages, &p.MergedInto, &p.CreatedAt, &p.UpdatedAt}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
 		return nil, err
 	}
 	p.KTPImages = ktpImages
+	for _, f := range []*string{&p.NIK, &p.Phone, &p.Email, &p.Address} {
+		if *f, err = r.cipher.Decrypt(*f); err != nil {
+			return nil, err
+		}
+	}
 	return &p, nil
 }
@@ -3,12 +3,20 @@ package roles
 import (
 	"context"
 	"v2/internal/domain/roles"
+
+	"github.com/google/uuid"
 )
 
 type PatientRepository interface {
 	Create(ctx context.Context, patient *roles.Patient) error
 	CreateOrUpdateByNIK(ctx context.Context, patient *roles.Patient) (*roles.Patient, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*roles.Patient, error)
 	FindByNIK(ctx context.Context, nik string) (*roles.Patient, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*roles.Patient, error)
+	UpdateProfile(ctx context.Context, patient *roles.Patient) error
 	FindAll(ctx context.Context) ([]roles.Patient, error)
 	FindAllPaginated(ctx context.Context, page, limit int) ([]roles.Patient, int64, error)
+	Search(ctx context.Context, search roles.PatientSearch) ([]roles.PatientSearchResult, int64, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 }
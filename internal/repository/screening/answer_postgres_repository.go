@@ -4,44 +4,86 @@ import (
 	"context"
 	"encoding/json"
 	"v2/internal/domain/screening"
+	"v2/internal/fieldcrypt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// patient_info disimpan terenkripsi; riwayat per pasien dicari lewat nik_bidx.
 type AnswerPostgresRepository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
 }
 
-func NewAnswerPostgresRepository(db *pgxpool.Pool) *AnswerPostgresRepository {
-	return &AnswerPostgresRepository{db: db}
+func NewAnswerPostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *AnswerPostgresRepository {
+	return &AnswerPostgresRepository{db: db, cipher: cipher}
 }
 
 func (r *AnswerPostgresRepository) Create(ctx context.Context, a *screening.ScreeningAnswer) error {
 	if a.ID == uuid.Nil {
 		a.ID = uuid.New()
 	}
-	patientInfo, _ := json.Marshal(a.PatientInfo)
+	patientInfo, err := r.cipher.EncryptJSON(a.PatientInfo)
+	if err != nil {
+		return err
+	}
 	answers, _ := json.Marshal(a.Answers)
-	_, err := r.db.Exec(ctx, `INSERT INTO screening_answers (id, patient_info, answers, created_at) VALUES ($1, $2, $3, $4)`, a.ID, patientInfo, answers, a.CreatedAt)
+	_, err = r.db.Exec(ctx, `INSERT INTO screening_answers (id, patient_info, nik_bidx, answers, risk_level, risk_flags, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`, a.ID, patientInfo, r.cipher.NIKIndex(a.PatientInfo.NIK), answers, a.RiskLevel, a.RiskFlags, a.CreatedAt)
 	return err
 }
 
 func (r *AnswerPostgresRepository) Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
-	// Sederhana: hanya update answers
+	// Sederhana: hanya update answers beserta hasil skoring risiko
 	answers, _ := json.Marshal(update["answers"])
-	_, err := r.db.Exec(ctx, `UPDATE screening_answers SET answers=$1 WHERE id=$2`, answers, id)
+	_, err := r.db.Exec(ctx, `UPDATE screening_answers SET answers=$1, risk_level=$2, risk_flags=$3 WHERE id=$4`, answers, update["risk_level"], update["risk_flags"], id)
 	return err
 }
 
+// answerColumns melengkapi setiap jawaban dengan label pertanyaannya, termasuk
+// pertanyaan yang sudah dihapus, agar riwayat screening tetap terbaca.
+const answerColumns = `a.id, a.patient_info,
+	CASE WHEN jsonb_typeof(a.answers) = 'array' THEN COALESCE((
+		SELECT jsonb_agg(e.item || jsonb_build_object('label', COALESCE(q.label, '')) ORDER BY e.ord)
+		FROM jsonb_array_elements(a.answers) WITH ORDINALITY AS e(item, ord)
+		LEFT JOIN screening_questions q ON q.id::text = e.item->>'question_id'), '[]'::jsonb)
+	ELSE a.answers END,
+	a.risk_level, a.risk_flags, a.created_at`
+
 func (r *AnswerPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*screening.ScreeningAnswer, error) {
-	row := r.db.QueryRow(ctx, `SELECT id, patient_info, answers, created_at FROM screening_answers WHERE id=$1`, id)
+	row := r.db.QueryRow(ctx, `SELECT `+answerColumns+` FROM screening_answers a WHERE a.id=$1`, id)
+	return r.scanAnswer(row)
+}
+
+// FindByNIK mengembalikan riwayat jawaban screening, terbaru lebih dulu.
+func (r *AnswerPostgresRepository) FindByNIK(ctx context.Context, nik string) ([]screening.ScreeningAnswer, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+answerColumns+` FROM screening_answers a WHERE a.nik_bidx=$1 ORDER BY a.created_at DESC`, r.cipher.NIKIndex(nik))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []screening.ScreeningAnswer
+	for rows.Next() {
+		a, err := r.scanAnswer(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *a)
+	}
+	return result, nil
+}
+
+func (r *AnswerPostgresRepository) scanAnswer(row interface {
+	Scan(dest ...interface{}) error
+}) (*screening.ScreeningAnswer, error) {
 	var a screening.ScreeningAnswer
 	var patientInfoData, answersData []byte
-	if err := row.Scan(&a.ID, &patientInfoData, &answersData, &a.CreatedAt); err != nil {
+	if err := row.Scan(&a.ID, &patientInfoData, &answersData, &a.RiskLevel, &a.RiskFlags, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := r.cipher.DecryptJSON(patientInfoData, &a.PatientInfo); err != nil {
 		return nil, err
 	}
-	_ = json.Unmarshal(patientInfoData, &a.PatientInfo)
 	_ = json.Unmarshal(answersData, &a.Answers)
 	return &a, nil
 }
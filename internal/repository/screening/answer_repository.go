@@ -10,4 +10,6 @@ import (
 type AnswerRepository interface {
 	Create(ctx context.Context, answer *screening.ScreeningAnswer) error
 	Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error
+	FindByID(ctx context.Context, id uuid.UUID) (*screening.ScreeningAnswer, error)
+	FindByNIK(ctx context.Context, nik string) ([]screening.ScreeningAnswer, error)
 }
@@ -2,9 +2,11 @@ package screening
 
 import (
 	"context"
+	"encoding/json"
 	"v2/internal/domain/screening"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -16,21 +18,21 @@ func NewQuestionPostgresRepository(db *pgxpool.Pool) *QuestionPostgresRepository
 	return &QuestionPostgresRepository{db: db}
 }
 
+const questionColumns = `id, label, type, options, position, required, display_conditions, deleted_at`
+
 func (r *QuestionPostgresRepository) FindAll(ctx context.Context) ([]screening.ScreeningQuestion, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, label, type, options FROM screening_questions`)
+	rows, err := r.db.Query(ctx, `SELECT `+questionColumns+` FROM screening_questions WHERE deleted_at IS NULL ORDER BY position, id`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var result []screening.ScreeningQuestion
 	for rows.Next() {
-		var q screening.ScreeningQuestion
-		var options []string
-		if err := rows.Scan(&q.ID, &q.Label, &q.Type, &options); err != nil {
+		q, err := scanQuestion(rows)
+		if err != nil {
 			return nil, err
 		}
-		q.Options = options
-		result = append(result, q)
+		result = append(result, *q)
 	}
 	return result, nil
 }
@@ -39,23 +41,72 @@ func (r *QuestionPostgresRepository) Create(ctx context.Context, q *screening.Sc
 	if q.ID == uuid.Nil {
 		q.ID = uuid.New()
 	}
-	_, err := r.db.Exec(ctx, `INSERT INTO screening_questions (id, label, type, options) VALUES ($1, $2, $3, $4)`, q.ID, q.Label, q.Type, q.Options)
+	conditions, _ := json.Marshal(conditionsOrEmpty(q.Conditions))
+	_, err := r.db.Exec(ctx, `INSERT INTO screening_questions (id, label, type, options, position, required, display_conditions) VALUES ($1, $2, $3, $4, $5, $6, $7)`, q.ID, q.Label, q.Type, q.Options, q.Position, q.Required, conditions)
 	return err
 }
 
 func (r *QuestionPostgresRepository) Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
-	// Sederhana: hanya update label, type, options
-	_, err := r.db.Exec(ctx, `UPDATE screening_questions SET label=$1, type=$2, options=$3 WHERE id=$4`, update["label"], update["type"], update["options"], id)
+	// Sederhana: hanya update label, type, options; position hanya jika dikirim
+	_, err := r.db.Exec(ctx, `UPDATE screening_questions SET label=$1, type=$2, options=$3, position=COALESCE($4::int, position) WHERE id=$5 AND deleted_at IS NULL`, update["label"], update["type"], update["options"], update["position"], id)
 	return err
 }
 
+func (r *QuestionPostgresRepository) UpdateConditions(ctx context.Context, id uuid.UUID, conditions []screening.DisplayCondition) error {
+	data, _ := json.Marshal(conditionsOrEmpty(conditions))
+	_, err := r.db.Exec(ctx, `UPDATE screening_questions SET display_conditions=$1 WHERE id=$2 AND deleted_at IS NULL`, data, id)
+	return err
+}
+
+// FindByID juga mengembalikan pertanyaan yang sudah dihapus (DeletedAt terisi)
+// agar jawaban lama tetap dapat ditampilkan.
 func (r *QuestionPostgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*screening.ScreeningQuestion, error) {
-	row := r.db.QueryRow(ctx, `SELECT id, label, type, options FROM screening_questions WHERE id=$1`, id)
+	row := r.db.QueryRow(ctx, `SELECT `+questionColumns+` FROM screening_questions WHERE id=$1`, id)
+	return scanQuestion(row)
+}
+
+// SoftDelete menandai pertanyaan terhapus; pgx.ErrNoRows jika tidak ada pertanyaan aktif.
+func (r *QuestionPostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE screening_questions SET deleted_at=NOW(), deleted_by=$1 WHERE id=$2 AND deleted_at IS NULL`, deletedBy, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// Restore memulihkan pertanyaan terhapus; pgx.ErrNoRows jika pertanyaan tidak sedang terhapus.
+func (r *QuestionPostgresRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `UPDATE screening_questions SET deleted_at=NULL, deleted_by=NULL WHERE id=$1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func scanQuestion(row interface {
+	Scan(dest ...interface{}) error
+}) (*screening.ScreeningQuestion, error) {
 	var q screening.ScreeningQuestion
 	var options []string
-	if err := row.Scan(&q.ID, &q.Label, &q.Type, &options); err != nil {
+	var conditionsData []byte
+	if err := row.Scan(&q.ID, &q.Label, &q.Type, &options, &q.Position, &q.Required, &conditionsData, &q.DeletedAt); err != nil {
 		return nil, err
 	}
 	q.Options = options
+	_ = json.Unmarshal(conditionsData, &q.Conditions)
 	return &q, nil
 }
+
+// conditionsOrEmpty memastikan kolom JSONB berisi [] dan bukan null.
+func conditionsOrEmpty(conditions []screening.DisplayCondition) []screening.DisplayCondition {
+	if conditions == nil {
+		return []screening.DisplayCondition{}
+	}
+	return conditions
+}
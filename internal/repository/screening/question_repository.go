@@ -11,5 +11,8 @@ type QuestionRepository interface {
 	FindAll(ctx context.Context) ([]screening.ScreeningQuestion, error)
 	Create(ctx context.Context, question *screening.ScreeningQuestion) error
 	Update(ctx context.Context, id uuid.UUID, update map[string]interface{}) error
+	UpdateConditions(ctx context.Context, id uuid.UUID, conditions []screening.DisplayCondition) error
 	FindByID(ctx context.Context, id uuid.UUID) (*screening.ScreeningQuestion, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
 }
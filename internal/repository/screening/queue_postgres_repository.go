@@ -2,27 +2,42 @@ package screening
 
 import (
 	"context"
-	"encoding/json"
 	"v2/internal/domain/screening"
+	"v2/internal/fieldcrypt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// patient_info disimpan terenkripsi; antrean per pasien dicari lewat nik_bidx.
 type QueuePostgresRepository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
 }
 
-func NewQueuePostgresRepository(db *pgxpool.Pool) *QueuePostgresRepository {
-	return &QueuePostgresRepository{db: db}
+func NewQueuePostgresRepository(db *pgxpool.Pool, cipher *fieldcrypt.Cipher) *QueuePostgresRepository {
+	return &QueuePostgresRepository{db: db, cipher: cipher}
 }
 
+// Hasil skoring risiko diambil dari jawaban screening yang terkait
+const queueSelect = `SELECT q.id, q.patient_info, q.screening_answer_id, q.status, COALESCE(a.risk_level, 'low'), COALESCE(a.risk_flags, '{}'), q.created_at, q.updated_at FROM screening_queues q LEFT JOIN screening_answers a ON a.id = q.screening_answer_id`
+
+// queueRiskRank sama dengan screening.RiskRank
+const queueRiskRank = `CASE COALESCE(a.risk_level, 'low') WHEN 'high' THEN 2 WHEN 'medium' THEN 1 ELSE 0 END`
+
+// queueCallOrder adalah urutan pemanggilan: risiko tinggi lebih dulu, lalu
+// yang datang lebih awal. CountAhead harus memakai urutan yang sama.
+const queueCallOrder = queueRiskRank + ` DESC, q.created_at, q.id`
+
 func (r *QueuePostgresRepository) Create(ctx context.Context, q *screening.ScreeningQueue) error {
 	if q.ID == uuid.Nil {
 		q.ID = uuid.New()
 	}
-	patientInfo, _ := json.Marshal(q.PatientInfo)
-	_, err := r.db.Exec(ctx, `INSERT INTO screening_queues (id, patient_info, screening_answer_id, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`, q.ID, patientInfo, q.ScreeningAnswerID, q.Status, q.CreatedAt, q.UpdatedAt)
+	patientInfo, err := r.cipher.EncryptJSON(q.PatientInfo)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `INSERT INTO screening_queues (id, patient_info, nik_bidx, screening_answer_id, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`, q.ID, patientInfo, r.cipher.NIKIndex(q.PatientInfo.NIK), q.ScreeningAnswerID, q.Status, q.CreatedAt, q.UpdatedAt)
 	return err
 }
 
@@ -33,59 +48,53 @@ func (r *QueuePostgresRepository) Update(ctx context.Context, id uuid.UUID, upda
 }
 
 func (r *QueuePostgresRepository) FindAll(ctx context.Context) ([]screening.ScreeningQueue, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, patient_info, screening_answer_id, status, created_at, updated_at FROM screening_queues`)
+	rows, err := r.db.Query(ctx, queueSelect+` ORDER BY `+queueCallOrder+``)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var result []screening.ScreeningQueue
 	for rows.Next() {
-		var q screening.ScreeningQueue
-		var patientInfoData []byte
-		if err := rows.Scan(&q.ID, &patientInfoData, &q.ScreeningAnswerID, &q.Status, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		q, err := r.scanQueue(rows)
+		if err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(patientInfoData, &q.PatientInfo)
-		result = append(result, q)
+		result = append(result, *q)
 	}
 	return result, nil
 }
 
 func (r *QueuePostgresRepository) FindByStatus(ctx context.Context, status string) ([]screening.ScreeningQueue, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, patient_info, screening_answer_id, status, created_at, updated_at FROM screening_queues WHERE status=$1`, status)
+	rows, err := r.db.Query(ctx, queueSelect+` WHERE q.status=$1 ORDER BY `+queueCallOrder+``, status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var result []screening.ScreeningQueue
 	for rows.Next() {
-		var q screening.ScreeningQueue
-		var patientInfoData []byte
-		if err := rows.Scan(&q.ID, &patientInfoData, &q.ScreeningAnswerID, &q.Status, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		q, err := r.scanQueue(rows)
+		if err != nil {
 			return nil, err
 		}
-		_ = json.Unmarshal(patientInfoData, &q.PatientInfo)
-		result = append(result, q)
+		result = append(result, *q)
 	}
 	return result, nil
 }
 
 func (r *QueuePostgresRepository) FindPaginatedByStatus(ctx context.Context, status string, page, limit int) ([]screening.ScreeningQueue, int64, error) {
 	offset := (page - 1) * limit
-	rows, err := r.db.Query(ctx, `SELECT id, patient_info, screening_answer_id, status, created_at, updated_at FROM screening_queues WHERE status=$1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`, status, limit, offset)
+	rows, err := r.db.Query(ctx, queueSelect+` WHERE q.status=$1 ORDER BY `+queueCallOrder+` LIMIT $2 OFFSET $3`, status, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 	var result []screening.ScreeningQueue
 	for rows.Next() {
-		var q screening.ScreeningQueue
-		var patientInfoData []byte
-		if err := rows.Scan(&q.ID, &patientInfoData, &q.ScreeningAnswerID, &q.Status, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		q, err := r.scanQueue(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		_ = json.Unmarshal(patientInfoData, &q.PatientInfo)
-		result = append(result, q)
+		result = append(result, *q)
 	}
 	// Hitung total
 	var total int64
@@ -95,3 +104,52 @@ func (r *QueuePostgresRepository) FindPaginatedByStatus(ctx context.Context, sta
 	}
 	return result, total, nil
 }
+
+// FindActiveByNIK mengembalikan antrean terbaru pasien yang belum selesai.
+func (r *QueuePostgresRepository) FindActiveByNIK(ctx context.Context, nik string) (*screening.ScreeningQueue, error) {
+	row := r.db.QueryRow(ctx, queueSelect+` WHERE q.nik_bidx=$1 AND q.status IN ($2, $3) ORDER BY q.created_at DESC LIMIT 1`, r.cipher.NIKIndex(nik), screening.QueueWaiting, screening.QueueInProgress)
+	return r.scanQueue(row)
+}
+
+// CountAhead menghitung antrean waiting yang berada sebelum q pada
+// queueCallOrder: risiko lebih tinggi, atau risiko sama dan datang lebih awal.
+func (r *QueuePostgresRepository) CountAhead(ctx context.Context, q *screening.ScreeningQueue) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM screening_queues q LEFT JOIN screening_answers a ON a.id = q.screening_answer_id
+		WHERE q.status=$4 AND q.id <> $1 AND (
+			`+queueRiskRank+` > $2 OR (`+queueRiskRank+` = $2 AND (q.created_at, q.id) < ($3, $1)))`,
+		q.ID, screening.RiskRank(q.RiskLevel), q.CreatedAt, screening.QueueWaiting).Scan(&count)
+	return count, err
+}
+
+func (r *QueuePostgresRepository) Stats(ctx context.Context) ([]screening.QueueStat, error) {
+	rows, err := r.db.Query(ctx, `SELECT status, COUNT(*), MIN(created_at) FROM screening_queues WHERE status IN ($1, $2) GROUP BY status`, screening.QueueWaiting, screening.QueueInProgress)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []screening.QueueStat
+	for rows.Next() {
+		var s screening.QueueStat
+		if err := rows.Scan(&s.Status, &s.Count, &s.OldestCreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+func (r *QueuePostgresRepository) scanQueue(row interface {
+	Scan(dest ...interface{}) error
+}) (*screening.ScreeningQueue, error) {
+	var q screening.ScreeningQueue
+	var patientInfoData []byte
+	if err := row.Scan(&q.ID, &patientInfoData, &q.ScreeningAnswerID, &q.Status, &q.RiskLevel, &q.RiskFlags, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := r.cipher.DecryptJSON(patientInfoData, &q.PatientInfo); err != nil {
+		return nil, err
+	}
+	q.NeedsDoctor = q.RiskLevel == screening.RiskHigh
+	return &q, nil
+}
@@ -13,4 +13,8 @@ type QueueRepository interface {
 	FindAll(ctx context.Context) ([]screening.ScreeningQueue, error)
 	FindByStatus(ctx context.Context, status string) ([]screening.ScreeningQueue, error)
 	FindPaginatedByStatus(ctx context.Context, status string, page, limit int) ([]screening.ScreeningQueue, int64, error)
+	FindActiveByNIK(ctx context.Context, nik string) (*screening.ScreeningQueue, error)
+	CountAhead(ctx context.Context, q *screening.ScreeningQueue) (int64, error)
+	// Stats meringkas antrean waiting dan in_progress
+	Stats(ctx context.Context) ([]screening.QueueStat, error)
 }
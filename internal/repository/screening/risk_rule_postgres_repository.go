@@ -0,0 +1,71 @@
+package screening
+
+import (
+	"context"
+	"v2/internal/domain/screening"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RiskRulePostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRiskRulePostgresRepository(db *pgxpool.Pool) *RiskRulePostgresRepository {
+	return &RiskRulePostgresRepository{db: db}
+}
+
+func (r *RiskRulePostgresRepository) FindAll(ctx context.Context) ([]screening.RiskRule, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, question_id, operator, value, "values", flag, level FROM screening_risk_rules ORDER BY flag`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []screening.RiskRule
+	for rows.Next() {
+		var rule screening.RiskRule
+		var value *string
+		var values []string
+		if err := rows.Scan(&rule.ID, &rule.Condition.QuestionID, &rule.Condition.Operator, &value, &values, &rule.Flag, &rule.Level); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			rule.Condition.Value = *value
+		}
+		rule.Condition.Values = values
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+func (r *RiskRulePostgresRepository) Create(ctx context.Context, rule *screening.RiskRule) error {
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	_, err := r.db.Exec(ctx, `INSERT INTO screening_risk_rules (id, question_id, operator, value, "values", flag, level) VALUES ($1, $2, $3, $4, $5, $6, $7)`, rule.ID, rule.Condition.QuestionID, rule.Condition.Operator, rule.Condition.Value, rule.Condition.Values, rule.Flag, rule.Level)
+	return err
+}
+
+func (r *RiskRulePostgresRepository) Update(ctx context.Context, rule *screening.RiskRule) error {
+	tag, err := r.db.Exec(ctx, `UPDATE screening_risk_rules SET question_id=$1, operator=$2, value=$3, "values"=$4, flag=$5, level=$6 WHERE id=$7`, rule.Condition.QuestionID, rule.Condition.Operator, rule.Condition.Value, rule.Condition.Values, rule.Flag, rule.Level, rule.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *RiskRulePostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM screening_risk_rules WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package screening
+
+import (
+	"context"
+	"v2/internal/domain/screening"
+
+	"github.com/google/uuid"
+)
+
+type RiskRuleRepository interface {
+	FindAll(ctx context.Context) ([]screening.RiskRule, error)
+	Create(ctx context.Context, rule *screening.RiskRule) error
+	Update(ctx context.Context, rule *screening.RiskRule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
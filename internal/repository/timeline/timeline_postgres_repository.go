@@ -0,0 +1,87 @@
+package timeline
+
+import (
+	"context"
+	"v2/internal/domain/timeline"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TimelinePostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTimelinePostgresRepository(db *pgxpool.Pool) *TimelinePostgresRepository {
+	return &TimelinePostgresRepository{db: db}
+}
+
+// eventsQuery menggabungkan semua sumber event pasien ($1). Jawaban skrining
+// dan antrean belum punya patient_id sehingga dicocokkan lewat blind index NIK.
+const eventsQuery = `
+WITH p AS (SELECT id, nik_bidx FROM patients WHERE id = $1 AND deleted_at IS NULL),
+events AS (
+	SELECT 'medical_record' AS type, m.id, m.created_at AS occurred_at,
+		jsonb_build_object('mr_number', m.mr_number) AS payload
+	FROM medical_records m JOIN p ON m.patient_id = p.id
+	UNION ALL
+	SELECT 'screening_answer', a.id, a.created_at,
+		jsonb_build_object('risk_level', a.risk_level, 'risk_flags', a.risk_flags, 'answers', a.answers)
+	FROM screening_answers a JOIN p ON a.nik_bidx = p.nik_bidx
+	UNION ALL
+	SELECT 'queue', q.id, q.created_at,
+		jsonb_build_object('status', q.status, 'screening_answer_id', q.screening_answer_id, 'updated_at', q.updated_at)
+	FROM screening_queues q JOIN p ON q.nik_bidx = p.nik_bidx
+	UNION ALL
+	SELECT 'physical_examination', e.id, e.created_at,
+		jsonb_build_object('health_status', e.health_status, 'blood_pressure', e.blood_pressure, 'heart_rate', e.heart_rate,
+			'oxygen_saturation', e.oxygen_saturation, 'respiratory_rate', e.respiratory_rate, 'body_temperature', e.body_temperature,
+			'abnormal_flags', e.abnormal_flags, 'konsultasi_dokter', e.konsultasi_dokter, 'paramedis_id', e.paramedis_id)
+	FROM physical_examinations e JOIN p ON e.patient_id = p.id
+	WHERE e.deleted_at IS NULL
+	UNION ALL
+	SELECT 'consultation', c.id, c.created_at,
+		jsonb_build_object('status', c.status, 'physical_examination_id', c.physical_examination_id, 'doctor_id', c.doctor_id,
+			'diagnosis', c.diagnosis, 'fitness_decision', c.fitness_decision, 'finished_at', c.finished_at,
+			'diagnosis_codes', (SELECT COALESCE(jsonb_agg(jsonb_build_object('code', d.code, 'primary', d.is_primary) ORDER BY d.is_primary DESC, d.code), '[]'::jsonb)
+				FROM consultation_diagnoses d WHERE d.consultation_id = c.id))
+	FROM consultations c JOIN p ON c.patient_id = p.id
+	UNION ALL
+	SELECT 'certificate', s.id, s.issued_at,
+		jsonb_build_object('certificate_number', s.certificate_number, 'decision', s.decision, 'climb_date', s.climb_date,
+			'valid_until', s.valid_until, 'revoked_at', s.revoked_at)
+	FROM certificates s JOIN p ON s.patient_id = p.id
+)
+SELECT type, id, occurred_at, payload FROM events
+WHERE cardinality($2::text[]) = 0 OR type = ANY($2)`
+
+// FindByPatientID mengembalikan event terbaru lebih dulu. types kosong berarti semua jenis.
+func (r *TimelinePostgresRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID, types []string, page, limit int) ([]timeline.Event, int64, error) {
+	if types == nil {
+		types = []string{}
+	}
+	offset := (page - 1) * limit
+	rows, err := r.db.Query(ctx, eventsQuery+` ORDER BY occurred_at DESC, id LIMIT $3 OFFSET $4`, patientID, types, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var result []timeline.Event
+	for rows.Next() {
+		var e timeline.Event
+		if err := rows.Scan(&e.Type, &e.ID, &e.OccurredAt, &e.Payload); err != nil {
+			return nil, 0, err
+		}
+		result = append(result, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	// Hitung total
+	var total int64
+	row := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM (`+eventsQuery+`) t`, patientID, types)
+	if err := row.Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	return result, total, nil
+}
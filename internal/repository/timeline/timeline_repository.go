@@ -0,0 +1,12 @@
+package timeline
+
+import (
+	"context"
+	"v2/internal/domain/timeline"
+
+	"github.com/google/uuid"
+)
+
+type TimelineRepository interface {
+	FindByPatientID(ctx context.Context, patientID uuid.UUID, types []string, page, limit int) ([]timeline.Event, int64, error)
+}
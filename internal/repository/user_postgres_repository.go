@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 	roles "v2/internal/domain/roles"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -28,28 +31,52 @@ func (r *UserPostgresRepository) Create(ctx context.Context, user *roles.User) (
 	return user.ID.String(), nil
 }
 
-func (r *UserPostgresRepository) FindByEmail(ctx context.Context, email string) (*roles.User, error) {
-	row := r.db.QueryRow(ctx, `SELECT id, email, password, role FROM users WHERE email=$1`, email)
+const userColumns = `id, email, password, role, failed_logins, locked_until`
+
+func scanUser(row pgx.Row) (*roles.User, error) {
 	var user roles.User
-	var id uuid.UUID
-	if err := row.Scan(&id, &user.Email, &user.Password, &user.Role); err != nil {
+	if err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Role, &user.FailedLogins, &user.LockedUntil); err != nil {
 		return nil, err
 	}
-	user.ID = id
 	return &user, nil
 }
 
-func (r *UserPostgresRepository) FindByID(ctx context.Context, id string) (*roles.User, error) {
-	uuidID, err := uuid.Parse(id)
+// FindByEmail mengembalikan nil tanpa error jika email belum terdaftar.
+func (r *UserPostgresRepository) FindByEmail(ctx context.Context, email string) (*roles.User, error) {
+	user, err := scanUser(r.db.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email=$1`, email))
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	row := r.db.QueryRow(ctx, `SELECT id, email, password, role FROM users WHERE id=$1`, uuidID)
-	var user roles.User
-	var uid uuid.UUID
-	if err := row.Scan(&uid, &user.Email, &user.Password, &user.Role); err != nil {
+	return user, nil
+}
+
+func (r *UserPostgresRepository) FindByID(ctx context.Context, id string) (*roles.User, error) {
+	uuidID, err := uuid.Parse(id)
+	if err != nil {
 		return nil, err
 	}
-	user.ID = uid
-	return &user, nil
+	return scanUser(r.db.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id=$1`, uuidID))
+}
+
+// RecordLoginFailure menambah hitungan login gagal berturut-turut dan
+// mengembalikan nilai barunya.
+func (r *UserPostgresRepository) RecordLoginFailure(ctx context.Context, id uuid.UUID) (int, error) {
+	var failed int
+	err := r.db.QueryRow(ctx, `UPDATE users SET failed_logins = failed_logins + 1 WHERE id=$1 RETURNING failed_logins`, id).Scan(&failed)
+	return failed, err
+}
+
+func (r *UserPostgresRepository) Lock(ctx context.Context, id uuid.UUID, until time.Time) error {
+	_, err := r.db.Exec(ctx, `UPDATE users SET locked_until=$2 WHERE id=$1`, id, until)
+	return err
+}
+
+// ResetLoginFailures dipanggil setelah login berhasil atau saat admin membuka
+// kunci akun.
+func (r *UserPostgresRepository) ResetLoginFailures(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `UPDATE users SET failed_logins=0, locked_until=NULL WHERE id=$1`, id)
+	return err
 }
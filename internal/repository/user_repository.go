@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"time"
 	roles "v2/internal/domain/roles"
+
+	"github.com/google/uuid"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *roles.User) (string, error)
 	FindByEmail(ctx context.Context, email string) (*roles.User, error)
 	FindByID(ctx context.Context, id string) (*roles.User, error)
+	RecordLoginFailure(ctx context.Context, id uuid.UUID) (int, error)
+	Lock(ctx context.Context, id uuid.UUID, until time.Time) error
+	ResetLoginFailures(ctx context.Context, id uuid.UUID) error
 }
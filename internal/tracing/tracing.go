@@ -0,0 +1,111 @@
+// Package tracing menyiapkan OpenTelemetry dan membantu meneruskan span
+// lewat context handler Fiber. Handler meneruskan c.Context() ke usecase,
+// yang tidak membawa nilai dari context.WithValue, sehingga span request
+// juga disimpan di Locals dengan SpanKey.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"v2/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation = "v2/internal/tracing"
+
+// SpanKey adalah key span request di Locals Fiber.
+type SpanKey struct{}
+
+// Setup memasang tracer provider global dan propagator W3C Trace Context.
+// Dengan exporter "none" tracer tetap no-op. Fungsi yang dikembalikan
+// mengirim span tersisa dan harus dipanggil saat shutdown.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == "none" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case "otlp":
+		var u *url.URL
+		if u, err = url.Parse(cfg.Endpoint); err != nil {
+			return nil, fmt.Errorf("tracing: endpoint: %w", err)
+		}
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(u.Host),
+			otlptracehttp.WithURLPath(path.Join("/", u.Path, "v1/traces")),
+		}
+		if u.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// SpanFromContext mengembalikan span aktif, termasuk span request yang
+// disimpan di Locals oleh middleware.
+func SpanFromContext(ctx context.Context) trace.Span {
+	if ctx == nil {
+		return trace.SpanFromContext(ctx)
+	}
+	if span := trace.SpanFromContext(ctx); span.SpanContext().IsValid() {
+		return span
+	}
+	if span, ok := ctx.Value(SpanKey{}).(trace.Span); ok {
+		return span
+	}
+	return trace.SpanFromContext(ctx)
+}
+
+// Start membuat span anak dari span aktif di ctx. Context hasilnya tetap
+// meneruskan nilai context asal (Locals, request ID) sehingga aman
+// diteruskan ke repository.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(withParent(ctx), name, opts...)
+}
+
+func withParent(ctx context.Context) context.Context {
+	if trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx
+	}
+	if span, ok := ctx.Value(SpanKey{}).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+	return ctx
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"v2/internal/domain/audit"
+	repo "v2/internal/repository/audit"
+
+	"github.com/google/uuid"
+)
+
+// Recorder dipakai usecase lain dan middleware untuk mencatat akses/perubahan.
+// Aktor dibaca dari context: "user_id" dan "role" diisi AuthMiddleware, info
+// request diisi middleware.AuditRequest.
+type Recorder interface {
+	// Record mencatat satu kejadian. before/after boleh nil; untuk penulisan
+	// hanya field yang berubah yang disimpan.
+	Record(ctx context.Context, action, entity, entityID string, before, after interface{})
+}
+
+type AuditUsecase interface {
+	Recorder
+	FindPaginated(ctx context.Context, filter audit.Filter) ([]audit.Entry, int64, error)
+}
+
+type auditUsecase struct {
+	repo repo.AuditRepository
+}
+
+func NewAuditUsecase(r repo.AuditRepository) AuditUsecase {
+	return &auditUsecase{repo: r}
+}
+
+// Record tidak mengembalikan error agar kegagalan audit tidak membatalkan
+// operasi yang sudah tersimpan; kegagalan dicatat ke log server.
+func (u *auditUsecase) Record(ctx context.Context, action, entity, entityID string, before, after interface{}) {
+	e := &audit.Entry{Action: action, Entity: entity, EntityID: entityID}
+	if id, ok := ctx.Value("user_id").(string); ok {
+		if uid, err := uuid.Parse(id); err == nil {
+			e.ActorID = &uid
+		}
+	}
+	e.ActorRole, _ = ctx.Value("role").(string)
+	if e.ActorRole == "" {
+		e.ActorRole = audit.RoleAnonymous
+	}
+	if req, ok := ctx.Value(audit.RequestKey{}).(audit.Request); ok {
+		e.IP, e.UserAgent, e.Method, e.Path = req.IP, req.UserAgent, req.Method, req.Path
+	}
+	var err error
+	if e.Before, e.After, err = diff(before, after); err != nil {
+		slog.ErrorContext(ctx, "audit: diff failed", "action", action, "entity", entity, "entity_id", entityID, "err", err)
+	}
+	if err := u.repo.Create(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "audit: record failed", "action", action, "entity", entity, "entity_id", entityID, "err", err)
+	}
+}
+
+func (u *auditUsecase) FindPaginated(ctx context.Context, filter audit.Filter) ([]audit.Entry, int64, error) {
+	return u.repo.FindPaginated(ctx, filter)
+}
+
+// diff mengembalikan field level atas yang berbeda antara before dan after.
+// Jika salah satunya nil, sisi lainnya disimpan utuh.
+func diff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b != nil && a != nil {
+		delete(b, "updated_at")
+		delete(a, "updated_at")
+		for k, v := range b {
+			if av, ok := a[k]; ok && reflect.DeepEqual(v, av) {
+				delete(b, k)
+				delete(a, k)
+			}
+		}
+	}
+	redact(b)
+	redact(a)
+	bj, err := marshalMap(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	aj, err := marshalMap(a)
+	return bj, aj, err
+}
+
+// sensitiveKeys adalah field identitas pasien: yang disimpan terenkripsi di
+// tabel asalnya dan yang dikosongkan saat penghapusan data (UU PDP). Audit log
+// hanya mencatat bahwa field tersebut berubah, karena log tidak ikut dihapus.
+var sensitiveKeys = map[string]bool{
+	"nik": true, "patient_nik": true, "phone": true, "email": true, "address": true, "patient_info": true,
+	"full_name": true, "patient_name": true, "birth_place": true, "birth_date": true,
+	"rt": true, "rw": true, "village": true, "religion": true, "marital": true, "job": true,
+	"nationality": true, "valid_until": true, "ktp_images": true,
+}
+
+const redacted = "[redacted]"
+
+// redact menyamarkan sensitiveKeys di semua level, termasuk snapshot bertingkat.
+func redact(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if sensitiveKeys[k] {
+				if val != nil && val != "" {
+					v[k] = redacted
+				}
+				continue
+			}
+			redact(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			redact(val)
+		}
+	}
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func marshalMap(m map[string]interface{}) (json.RawMessage, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/auth"
+	"v2/internal/domain/roles"
+	"v2/internal/ratelimit"
+	userrepo "v2/internal/repository"
+	repo "v2/internal/repository/auth"
+	"v2/internal/tracing"
+	auditusecase "v2/internal/usecase/audit"
+	"v2/internal/utils"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrTooManyAttempts    = errors.New("too many login attempts")
+	ErrAccountLocked      = errors.New("account is temporarily locked")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidChallenge   = errors.New("mfa token is invalid or expired")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrCodeRequired       = errors.New("code or recovery_code is required")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrMFANotPending      = errors.New("start two-factor enrollment first")
+	ErrMFARequired        = errors.New("two-factor authentication is required for this role")
+)
+
+// RetryError membawa lama tunggu untuk header Retry-After.
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string { return e.Err.Error() }
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// TokenIssuer menerbitkan JWT; diimplementasikan jwtauth.Manager.
+type TokenIssuer interface {
+	Generate(userID, role string) (string, error)
+}
+
+// Settings mengatur batas percobaan login dan penguncian akun.
+type Settings struct {
+	// IPRule membatasi percobaan dari satu IP, AccountRule percobaan ke satu email
+	IPRule      ratelimit.Rule
+	AccountRule ratelimit.Rule
+	// Akun dikunci LockBase setelah LockThreshold kali gagal berturut-turut;
+	// setiap kegagalan berikutnya menggandakan lama kunci sampai LockMax.
+	LockThreshold int
+	LockBase      time.Duration
+	LockMax       time.Duration
+	// Role yang wajib memakai 2FA; akun tanpa 2FA harus mendaftar saat login
+	MFARequiredRoles []string
+	MFAIssuer        string // nama yang tampil di aplikasi authenticator
+	// Challenge MFA berlaku ChallengeTTL dan ditolak setelah ChallengeMaxAttempts kode salah
+	ChallengeTTL         time.Duration
+	ChallengeMaxAttempts int
+	RecoveryCodes        int
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		IPRule:        ratelimit.Rule{Max: 30, Window: 5 * time.Minute},
+		AccountRule:   ratelimit.Rule{Max: 10, Window: 15 * time.Minute},
+		LockThreshold: 5,
+		LockBase:      time.Minute,
+		LockMax:       24 * time.Hour,
+
+		MFARequiredRoles:     []string{"admin", "dokter"},
+		MFAIssuer:            "Klinik",
+		ChallengeTTL:         5 * time.Minute,
+		ChallengeMaxAttempts: 5,
+		RecoveryCodes:        10,
+	}
+}
+
+type AuthUsecase interface {
+	// Login memeriksa password. Jika akun memakai 2FA atau role-nya wajib
+	// 2FA, hasilnya berisi MFAToken untuk langkah berikutnya, bukan JWT.
+	Login(ctx context.Context, email, password string) (*auth.LoginResult, error)
+	// VerifyMFA menyelesaikan login dengan kode TOTP atau recovery code.
+	VerifyMFA(ctx context.Context, mfaToken, code, recoveryCode string) (*auth.LoginResult, error)
+	// StartChallengeEnrollment dan ConfirmChallengeEnrollment dipakai akun
+	// yang wajib 2FA tetapi belum mendaftar; konfirmasi menghasilkan JWT.
+	StartChallengeEnrollment(ctx context.Context, mfaToken string) (*auth.Enrollment, error)
+	ConfirmChallengeEnrollment(ctx context.Context, mfaToken, code string) (*auth.LoginResult, error)
+	MFAStatus(ctx context.Context, userID string) (*MFAStatus, error)
+	StartEnrollment(ctx context.Context, userID string) (*auth.Enrollment, error)
+	// ConfirmEnrollment mengaktifkan 2FA dan mengembalikan recovery code
+	// yang hanya ditampilkan sekali.
+	ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID, code string) error
+	// ResetMFA menghapus 2FA akun lain, misalnya saat ponsel hilang.
+	ResetMFA(ctx context.Context, userID string) error
+	// Unlock membuka kunci akun dan mereset hitungan percobaannya.
+	Unlock(ctx context.Context, userID string) error
+	FindEvents(ctx context.Context, filter auth.EventFilter) ([]auth.LoginEvent, int64, error)
+}
+
+type authUsecase struct {
+	userRepo       userrepo.UserRepository
+	eventRepo      repo.LoginEventRepository
+	mfaRepo        repo.MFARepository
+	ipLimiter      ratelimit.Limiter
+	accountLimiter ratelimit.Limiter
+	audit          auditusecase.Recorder
+	tokens         TokenIssuer
+	settings       Settings
+}
+
+func NewAuthUsecase(ur userrepo.UserRepository, er repo.LoginEventRepository, mr repo.MFARepository, ipLimiter, accountLimiter ratelimit.Limiter, audit auditusecase.Recorder, tokens TokenIssuer, settings Settings) AuthUsecase {
+	return &authUsecase{userRepo: ur, eventRepo: er, mfaRepo: mr, ipLimiter: ipLimiter, accountLimiter: accountLimiter, audit: audit, tokens: tokens, settings: settings}
+}
+
+func (u *authUsecase) Login(ctx context.Context, email, password string) (*auth.LoginResult, error) {
+	ctx, span := tracing.Start(ctx, "AuthUsecase.Login")
+	defer span.End()
+	email = strings.TrimSpace(email)
+	event := newEvent(ctx, email)
+
+	if ok, wait, err := u.ipLimiter.Hit(ctx, event.IP); err != nil {
+		return nil, err
+	} else if !ok {
+		u.record(ctx, event, auth.ReasonIPLimited)
+		return nil, &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+	if ok, wait, err := u.accountLimiter.Hit(ctx, accountKey(email)); err != nil {
+		return nil, err
+	} else if !ok {
+		u.record(ctx, event, auth.ReasonAccountLimited)
+		return nil, &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		u.record(ctx, event, auth.ReasonUnknownUser)
+		return nil, ErrInvalidCredentials
+	}
+	event.UserID = &user.ID
+	if err := u.checkLocked(ctx, user, event); err != nil {
+		return nil, err
+	}
+	if !utils.CheckPasswordHash(password, user.Password) {
+		if err := u.recordFailure(ctx, user); err != nil {
+			return nil, err
+		}
+		u.record(ctx, event, auth.ReasonInvalidPassword)
+		return nil, ErrInvalidCredentials
+	}
+
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case t.EnabledAt != nil:
+		u.record(ctx, event, auth.ReasonMFARequired)
+		return u.newChallenge(ctx, user, auth.ChallengeVerify)
+	case u.mfaRequired(user.Role):
+		u.record(ctx, event, auth.ReasonMFAEnrollment)
+		return u.newChallenge(ctx, user, auth.ChallengeEnroll)
+	}
+	return u.complete(ctx, user, event, auth.ReasonSuccess)
+}
+
+// complete menerbitkan JWT setelah semua langkah login lolos.
+func (u *authUsecase) complete(ctx context.Context, user *roles.User, event *auth.LoginEvent, reason string) (*auth.LoginResult, error) {
+	token, err := u.tokens.Generate(user.ID.String(), user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := u.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.accountLimiter.Reset(ctx, accountKey(user.Email)); err != nil {
+		slog.WarnContext(ctx, "login: reset account limiter failed", "user_id", user.ID, "err", err)
+	}
+	event.Success = true
+	u.record(ctx, event, reason)
+	return &auth.LoginResult{Token: token}, nil
+}
+
+func (u *authUsecase) checkLocked(ctx context.Context, user *roles.User, event *auth.LoginEvent) error {
+	now := time.Now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		u.record(ctx, event, auth.ReasonLocked)
+		return &RetryError{Err: ErrAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// recordFailure menambah hitungan gagal dan mengunci akun jika perlu.
+func (u *authUsecase) recordFailure(ctx context.Context, user *roles.User) error {
+	failed, err := u.userRepo.RecordLoginFailure(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if d := u.lockDuration(failed); d > 0 {
+		return u.userRepo.Lock(ctx, user.ID, time.Now().Add(d))
+	}
+	return nil
+}
+
+// lockDuration mengembalikan lama kunci untuk kegagalan ke-failed, atau 0
+// jika belum mencapai ambang.
+func (u *authUsecase) lockDuration(failed int) time.Duration {
+	s := u.settings
+	if s.LockThreshold <= 0 || failed < s.LockThreshold {
+		return 0
+	}
+	d := s.LockBase
+	for i := s.LockThreshold; i < failed && d < s.LockMax; i++ {
+		d *= 2
+	}
+	if d > s.LockMax {
+		d = s.LockMax
+	}
+	return d
+}
+
+// record tidak mengembalikan error agar kegagalan pencatatan tidak mengubah
+// hasil login; kegagalan dicatat ke log server.
+func (u *authUsecase) record(ctx context.Context, e *auth.LoginEvent, reason string) {
+	e.Reason = reason
+	if err := u.eventRepo.Create(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "login: record event failed", "reason", reason, "err", err)
+	}
+}
+
+func (u *authUsecase) Unlock(ctx context.Context, userID string) error {
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.userRepo.ResetLoginFailures(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := u.accountLimiter.Reset(ctx, accountKey(user.Email)); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionUnlock, audit.EntityUser, user.ID.String(),
+		map[string]interface{}{"failed_logins": user.FailedLogins, "locked_until": user.LockedUntil},
+		map[string]interface{}{"failed_logins": 0, "locked_until": nil})
+	return nil
+}
+
+func (u *authUsecase) FindEvents(ctx context.Context, filter auth.EventFilter) ([]auth.LoginEvent, int64, error) {
+	return u.eventRepo.FindPaginated(ctx, filter)
+}
+
+func newEvent(ctx context.Context, email string) *auth.LoginEvent {
+	event := &auth.LoginEvent{Email: email}
+	if req, ok := ctx.Value(audit.RequestKey{}).(audit.Request); ok {
+		event.IP, event.UserAgent = req.IP, req.UserAgent
+	}
+	return event
+}
+
+// accountKey menyamakan huruf besar/kecil agar variasi penulisan email
+// dihitung sebagai akun yang sama.
+func accountKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,396 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/auth"
+	"v2/internal/domain/roles"
+	"v2/internal/totp"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+// MFAStatus adalah status 2FA akun yang login.
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+func (u *authUsecase) mfaRequired(role string) bool {
+	return slices.Contains(u.settings.MFARequiredRoles, role)
+}
+
+// newChallenge membuat token langkah kedua login. Token acak dikirim ke klien
+// dan hanya hash-nya yang disimpan.
+func (u *authUsecase) newChallenge(ctx context.Context, user *roles.User, kind string) (*auth.LoginResult, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	c := &auth.Challenge{UserID: user.ID, Kind: kind, ExpiresAt: time.Now().Add(u.settings.ChallengeTTL)}
+	if err := u.mfaRepo.CreateChallenge(ctx, c, hashToken(token)); err != nil {
+		return nil, err
+	}
+	return &auth.LoginResult{
+		MFARequired:           kind == auth.ChallengeVerify,
+		MFAEnrollmentRequired: kind == auth.ChallengeEnroll,
+		MFAToken:              token,
+		MFAExpiresAt:          &c.ExpiresAt,
+	}, nil
+}
+
+// challenge mencari challenge yang masih berlaku beserta pemiliknya.
+func (u *authUsecase) challenge(ctx context.Context, token, kind string) (*auth.Challenge, *roles.User, error) {
+	if token == "" {
+		return nil, nil, ErrInvalidChallenge
+	}
+	c, err := u.mfaRepo.FindChallenge(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	if c.Kind != kind || c.UsedAt != nil || time.Now().After(c.ExpiresAt) || c.Attempts >= u.settings.ChallengeMaxAttempts {
+		return nil, nil, ErrInvalidChallenge
+	}
+	user, err := u.userRepo.FindByID(ctx, c.UserID.String())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrInvalidChallenge
+		}
+		return nil, nil, err
+	}
+	return c, user, nil
+}
+
+// challengeFailed mencatat kode salah pada challenge dan ikut menambah
+// hitungan penguncian akun.
+func (u *authUsecase) challengeFailed(ctx context.Context, c *auth.Challenge, user *roles.User, event *auth.LoginEvent) error {
+	if err := u.mfaRepo.AddChallengeAttempt(ctx, c.ID); err != nil {
+		return err
+	}
+	if err := u.recordFailure(ctx, user); err != nil {
+		return err
+	}
+	u.record(ctx, event, auth.ReasonInvalidMFA)
+	return ErrInvalidMFACode
+}
+
+// beginChallenge memeriksa limiter IP, challenge dan kunci akun untuk
+// endpoint langkah kedua login.
+func (u *authUsecase) beginChallenge(ctx context.Context, token, kind string) (*auth.Challenge, *roles.User, *auth.LoginEvent, error) {
+	event := newEvent(ctx, "")
+	if ok, wait, err := u.ipLimiter.Hit(ctx, event.IP); err != nil {
+		return nil, nil, nil, err
+	} else if !ok {
+		u.record(ctx, event, auth.ReasonIPLimited)
+		return nil, nil, nil, &RetryError{Err: ErrTooManyAttempts, RetryAfter: wait}
+	}
+	c, user, err := u.challenge(ctx, token, kind)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	event.Email, event.UserID = user.Email, &user.ID
+	if err := u.checkLocked(ctx, user, event); err != nil {
+		return nil, nil, nil, err
+	}
+	return c, user, event, nil
+}
+
+func (u *authUsecase) VerifyMFA(ctx context.Context, mfaToken, code, recoveryCode string) (*auth.LoginResult, error) {
+	if code == "" && recoveryCode == "" {
+		return nil, ErrCodeRequired
+	}
+	c, user, event, err := u.beginChallenge(ctx, mfaToken, auth.ChallengeVerify)
+	if err != nil {
+		return nil, err
+	}
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if t.EnabledAt == nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	reason := auth.ReasonSuccess
+	var ok bool
+	if code != "" {
+		ok, err = u.useCode(ctx, user.ID, t, code)
+	} else {
+		reason = auth.ReasonRecoveryCode
+		ok, err = u.mfaRepo.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(recoveryCode))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, u.challengeFailed(ctx, c, user, event)
+	}
+	if consumed, err := u.mfaRepo.ConsumeChallenge(ctx, c.ID); err != nil {
+		return nil, err
+	} else if !consumed {
+		return nil, ErrInvalidChallenge
+	}
+	return u.complete(ctx, user, event, reason)
+}
+
+// useCode memverifikasi kode TOTP dan menolak kode yang sudah pernah dipakai.
+func (u *authUsecase) useCode(ctx context.Context, userID uuid.UUID, t *auth.TOTP, code string) (bool, error) {
+	step, ok := totp.Validate(t.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return u.mfaRepo.UseStep(ctx, userID, step)
+}
+
+func (u *authUsecase) StartChallengeEnrollment(ctx context.Context, mfaToken string) (*auth.Enrollment, error) {
+	_, user, _, err := u.beginChallenge(ctx, mfaToken, auth.ChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return u.startEnrollment(ctx, user)
+}
+
+func (u *authUsecase) ConfirmChallengeEnrollment(ctx context.Context, mfaToken, code string) (*auth.LoginResult, error) {
+	c, user, event, err := u.beginChallenge(ctx, mfaToken, auth.ChallengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := u.confirmEnrollment(ctx, user, code)
+	if errors.Is(err, ErrInvalidMFACode) {
+		return nil, u.challengeFailed(ctx, c, user, event)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if consumed, err := u.mfaRepo.ConsumeChallenge(ctx, c.ID); err != nil {
+		return nil, err
+	} else if !consumed {
+		return nil, ErrInvalidChallenge
+	}
+	result, err := u.complete(ctx, user, event, auth.ReasonSuccess)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = codes
+	return result, nil
+}
+
+func (u *authUsecase) MFAStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Enabled: t.EnabledAt != nil, EnabledAt: t.EnabledAt, Required: u.mfaRequired(user.Role)}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = u.mfaRepo.CountRecoveryCodes(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+func (u *authUsecase) StartEnrollment(ctx context.Context, userID string) (*auth.Enrollment, error) {
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.startEnrollment(ctx, user)
+}
+
+func (u *authUsecase) ConfirmEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.confirmEnrollment(ctx, user, code)
+}
+
+// startEnrollment membuat secret baru yang belum aktif sampai dikonfirmasi
+// dengan kode pertama dari aplikasi authenticator.
+func (u *authUsecase) startEnrollment(ctx context.Context, user *roles.User) (*auth.Enrollment, error) {
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if t.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+	uri := totp.URI(u.settings.MFAIssuer, user.Email, secret)
+	qr, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Enrollment{Secret: secret, URI: uri, QRCode: qr}, nil
+}
+
+func (u *authUsecase) confirmEnrollment(ctx context.Context, user *roles.User, code string) ([]string, error) {
+	if code == "" {
+		return nil, ErrCodeRequired
+	}
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if t.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if t.Secret == "" {
+		return nil, ErrMFANotPending
+	}
+	step, ok := totp.Validate(t.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := u.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.Enable(ctx, user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionMFAEnable, audit.EntityUser, user.ID.String(), nil, nil)
+	return codes, nil
+}
+
+func (u *authUsecase) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	user, t, err := u.enabledTOTP(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := u.useCode(ctx, user.ID, t, code); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, hashes, err := u.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.mfaRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (u *authUsecase) DisableMFA(ctx context.Context, userID, code string) error {
+	user, t, err := u.enabledTOTP(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if u.mfaRequired(user.Role) {
+		return ErrMFARequired
+	}
+	if ok, err := u.useCode(ctx, user.ID, t, code); err != nil {
+		return err
+	} else if !ok {
+		return ErrInvalidMFACode
+	}
+	if err := u.mfaRepo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionMFADisable, audit.EntityUser, user.ID.String(), nil, nil)
+	return nil
+}
+
+func (u *authUsecase) ResetMFA(ctx context.Context, userID string) error {
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.mfaRepo.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionMFADisable, audit.EntityUser, user.ID.String(), nil, nil)
+	return nil
+}
+
+func (u *authUsecase) enabledTOTP(ctx context.Context, userID, code string) (*roles.User, *auth.TOTP, error) {
+	if code == "" {
+		return nil, nil, ErrCodeRequired
+	}
+	user, err := u.findUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := u.mfaRepo.FindTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.EnabledAt == nil {
+		return nil, nil, ErrMFANotEnabled
+	}
+	return user, t, nil
+}
+
+func (u *authUsecase) findUser(ctx context.Context, userID string) (*roles.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes membuat recovery code berformat xxxxx-xxxxx beserta hash
+// yang disimpan.
+func (u *authUsecase) newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, u.settings.RecoveryCodes)
+	hashes := make([]string, len(codes))
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		s := strings.ToLower(recoveryEncoding.EncodeToString(raw))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode mengabaikan huruf besar/kecil, spasi dan tanda hubung.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,327 @@
+package certificate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/certificate"
+	"v2/internal/domain/consultation"
+	"v2/internal/domain/physicalexam"
+	"v2/internal/metrics"
+	staffrepo "v2/internal/repository"
+	repo "v2/internal/repository/certificate"
+	consultationrepo "v2/internal/repository/consultation"
+	mrrepo "v2/internal/repository/medicalrecord"
+	examrepo "v2/internal/repository/physicalexam"
+	rolesrepo "v2/internal/repository/roles"
+	"v2/internal/tracing"
+	auditusecase "v2/internal/usecase/audit"
+	mrusecase "v2/internal/usecase/medicalrecord"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrInvalidDecision      = errors.New("decision must be fit, fit_with_conditions or unfit")
+	ErrExaminationNotFound  = errors.New("physical examination not found")
+	ErrCertificateNotFound  = errors.New("certificate not found")
+	ErrCertificateForbidden = errors.New("certificate belongs to another patient")
+	ErrInvalidToken         = errors.New("invalid verification token")
+	ErrAlreadyRevoked       = errors.New("certificate already revoked")
+	ErrConsultationOpen     = errors.New("doctor consultation for this examination is not finished")
+	ErrNotAssessed          = errors.New("physical examination has no fitness decision; health_status must be fit, fit_with_conditions or unfit")
+	ErrDecisionMismatch     = errors.New("decision does not match the clinical decision for this examination")
+	ErrCertificateExists    = errors.New("examination already has an active certificate; revoke it first")
+)
+
+// Settings berisi identitas klinik dan aturan masa berlaku sertifikat.
+type Settings struct {
+	ClinicName    string
+	VerifyBaseURL string        // dipakai untuk isi QR code, misal https://klinik.example/verify
+	Validity      time.Duration // masa berlaku sejak diterbitkan
+	SigningKey    []byte        // kunci HMAC untuk token verifikasi di QR code
+}
+
+// IssueCertificateInput.Decision opsional dan hanya dipakai sebagai
+// konfirmasi: keputusan selalu diambil dari konsultasi atau pemeriksaan.
+type IssueCertificateInput struct {
+	PhysicalExaminationID string
+	Decision              string
+	Notes                 string
+	ClimbDate             string
+	IssuedBy              string
+}
+
+type CertificateUsecase interface {
+	Issue(ctx context.Context, input IssueCertificateInput) (*certificate.Certificate, error)
+	FindByNumber(ctx context.Context, number, userID, role string) (*certificate.Certificate, error)
+	FindByPatientID(ctx context.Context, patientID string) ([]certificate.Certificate, error)
+	RenderPDF(ctx context.Context, number, userID, role string) ([]byte, *certificate.Certificate, error)
+	Verify(ctx context.Context, number, token string) (*certificate.Verification, error)
+	Revoke(ctx context.Context, number, revokedBy, reason string) error
+}
+
+type certificateUsecase struct {
+	certRepo      repo.CertificateRepository
+	examRepo      examrepo.PhysicalExaminationRepository
+	consultRepo   consultationrepo.ConsultationRepository
+	patientRepo   rolesrepo.PatientRepository
+	counterRepo   mrrepo.CounterRepository
+	doctorRepo    staffrepo.DoctorRepository
+	paramedicRepo staffrepo.ParamedicRepository
+	mrUsecase     mrusecase.MedicalRecordUsecase
+	audit         auditusecase.Recorder
+	settings      Settings
+}
+
+func NewCertificateUsecase(cr repo.CertificateRepository, er examrepo.PhysicalExaminationRepository, csr consultationrepo.ConsultationRepository, pr rolesrepo.PatientRepository, counter mrrepo.CounterRepository, dr staffrepo.DoctorRepository, par staffrepo.ParamedicRepository, mru mrusecase.MedicalRecordUsecase, audit auditusecase.Recorder, settings Settings) CertificateUsecase {
+	return &certificateUsecase{
+		certRepo:      cr,
+		examRepo:      er,
+		consultRepo:   csr,
+		patientRepo:   pr,
+		counterRepo:   counter,
+		doctorRepo:    dr,
+		paramedicRepo: par,
+		mrUsecase:     mru,
+		audit:         audit,
+		settings:      settings,
+	}
+}
+
+func (u *certificateUsecase) Issue(ctx context.Context, input IssueCertificateInput) (*certificate.Certificate, error) {
+	ctx, span := tracing.Start(ctx, "CertificateUsecase.Issue")
+	defer span.End()
+	if input.Decision != "" && !validDecision(input.Decision) {
+		return nil, ErrInvalidDecision
+	}
+	examID, err := uuid.Parse(input.PhysicalExaminationID)
+	if err != nil {
+		return nil, err
+	}
+	issuedBy, err := uuid.Parse(input.IssuedBy)
+	if err != nil {
+		return nil, err
+	}
+	exam, err := u.examRepo.FindByID(ctx, examID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExaminationNotFound
+		}
+		return nil, err
+	}
+	decided, err := u.decide(ctx, exam)
+	if err != nil {
+		return nil, err
+	}
+	if input.Decision != "" && input.Decision != decided.Decision {
+		return nil, ErrDecisionMismatch
+	}
+	if _, err := u.certRepo.FindActiveByExaminationID(ctx, exam.ID); err == nil {
+		return nil, ErrCertificateExists
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	patient, err := u.patientRepo.FindByID(ctx, exam.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	mr, err := u.mrUsecase.CreateMedicalRecord(ctx, exam.PatientID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := u.counterRepo.GetNextSequence(ctx, "certificate")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	number, err := certificateNumber(now, seq)
+	if err != nil {
+		return nil, err
+	}
+	cert := &certificate.Certificate{
+		CertificateNumber:     number,
+		PatientID:             patient.ID,
+		PhysicalExaminationID: exam.ID,
+		PatientName:           patient.FullName,
+		PatientNIK:            patient.NIK,
+		MRNumber:              mr.MRNumber,
+		Vitals: certificate.Vitals{
+			BloodPressure:    exam.BloodPressure,
+			HeartRate:        exam.HeartRate,
+			OxygenSaturation: exam.OxygenSaturation,
+			RespiratoryRate:  exam.RespiratoryRate,
+			BodyTemperature:  exam.BodyTemperature,
+			HealthStatus:     exam.HealthStatus,
+		},
+		Decision:      decided.Decision,
+		DecidedByRole: decided.Role,
+		DecidedByName: decided.Name,
+		Notes:         input.Notes,
+		ClimbDate:     input.ClimbDate,
+		IssuedBy:      issuedBy,
+		IssuedAt:      now,
+		ValidUntil:    now.Add(u.settings.Validity),
+	}
+	if err := u.certRepo.Create(ctx, cert); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCertificateExists
+		}
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityCertificate, cert.CertificateNumber, nil, cert)
+	metrics.CertificateIssued(cert.Decision)
+	return cert, nil
+}
+
+// decision adalah keputusan kelayakan beserta pemberi keputusannya.
+type decision struct {
+	Decision, Role, Name string
+}
+
+// decide mengambil keputusan dari data klinis, bukan dari request. Jika
+// pemeriksaan dikonsultasikan, keputusan dokter pada konsultasi terakhir yang
+// dipakai dan konsultasi yang belum selesai menahan penerbitan. Tanpa
+// konsultasi, keputusan paramedis dibaca dari HealthStatus pemeriksaan.
+func (u *certificateUsecase) decide(ctx context.Context, exam *physicalexam.PhysicalExamination) (*decision, error) {
+	c, err := u.consultRepo.FindByExaminationID(ctx, exam.ID)
+	if err == nil {
+		if c.Status != consultation.StatusCompleted && c.Status != consultation.StatusReferred {
+			return nil, ErrConsultationOpen
+		}
+		d := &decision{Decision: c.FitnessDecision, Role: "dokter"}
+		if c.DoctorID != nil {
+			if doctor, err := u.doctorRepo.FindByID(ctx, *c.DoctorID); err == nil {
+				d.Name = doctor.FullName
+			}
+		}
+		return d, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	// Konsultasi diminta (atau tanda vital abnormal) tapi belum dibuat
+	if exam.KonsultasiDokter {
+		return nil, ErrConsultationOpen
+	}
+	status := strings.ToLower(strings.TrimSpace(exam.HealthStatus))
+	if !validDecision(status) {
+		return nil, ErrNotAssessed
+	}
+	d := &decision{Decision: status, Role: "paramedis"}
+	if exam.ParamedisID != nil {
+		if paramedic, err := u.paramedicRepo.FindByID(ctx, *exam.ParamedisID); err == nil {
+			d.Name = paramedic.FullName
+		}
+	}
+	return d, nil
+}
+
+func validDecision(d string) bool {
+	switch d {
+	case certificate.DecisionFit, certificate.DecisionFitWithConditions, certificate.DecisionUnfit:
+		return true
+	}
+	return false
+}
+
+// FindByNumber mengembalikan sertifikat; pasien hanya boleh melihat miliknya sendiri.
+func (u *certificateUsecase) FindByNumber(ctx context.Context, number, userID, role string) (*certificate.Certificate, error) {
+	cert, err := u.certRepo.FindByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, err
+	}
+	if role == "pasien" {
+		uid, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, ErrCertificateForbidden
+		}
+		owned, err := u.certRepo.IsOwnedByUser(ctx, cert.ID, uid)
+		if err != nil {
+			return nil, err
+		}
+		if !owned {
+			return nil, ErrCertificateForbidden
+		}
+	}
+	return cert, nil
+}
+
+func (u *certificateUsecase) FindByPatientID(ctx context.Context, patientID string) ([]certificate.Certificate, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, err
+	}
+	return u.certRepo.FindByPatientID(ctx, pid)
+}
+
+func (u *certificateUsecase) RenderPDF(ctx context.Context, number, userID, role string) ([]byte, *certificate.Certificate, error) {
+	cert, err := u.FindByNumber(ctx, number, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := renderPDF(cert, u.settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, cert, nil
+}
+
+// Verify dipakai endpoint publik. Token dari QR code wajib: tanpa token,
+// siapa pun bisa membaca status dan tanggal pendakian dari nomor saja.
+func (u *certificateUsecase) Verify(ctx context.Context, number, token string) (*certificate.Verification, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	cert, err := u.certRepo.FindByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, err
+	}
+	if !verifyToken(cert, u.settings.SigningKey, token) {
+		return nil, ErrInvalidToken
+	}
+	return &certificate.Verification{
+		CertificateNumber: cert.CertificateNumber,
+		Initials:          initials(cert.PatientName),
+		Status:            cert.Status(time.Now()),
+		ClimbDate:         cert.ClimbDate,
+		ValidUntil:        cert.ValidUntil,
+		SignatureValid:    true,
+	}, nil
+}
+
+func (u *certificateUsecase) Revoke(ctx context.Context, number, revokedBy, reason string) error {
+	by, err := uuid.Parse(revokedBy)
+	if err != nil {
+		return err
+	}
+	cert, err := u.certRepo.FindByNumber(ctx, number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCertificateNotFound
+		}
+		return err
+	}
+	if cert.RevokedAt != nil {
+		return ErrAlreadyRevoked
+	}
+	if err := u.certRepo.Revoke(ctx, cert.ID, by, reason); err != nil {
+		return err
+	}
+	after, err := u.certRepo.FindByNumber(ctx, number)
+	if err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityCertificate, number, cert, after)
+	return nil
+}
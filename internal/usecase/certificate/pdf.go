@@ -0,0 +1,128 @@
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"v2/internal/domain/certificate"
+
+	"github.com/go-pdf/fpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+var decisionLabels = map[string]string{
+	certificate.DecisionFit:               "LAYAK MENDAKI",
+	certificate.DecisionFitWithConditions: "LAYAK MENDAKI DENGAN CATATAN",
+	certificate.DecisionUnfit:             "TIDAK LAYAK MENDAKI",
+}
+
+// verificationContent adalah isi QR code yang dipindai petugas di pos pendakian.
+func verificationContent(cert *certificate.Certificate, settings Settings) string {
+	return strings.TrimRight(settings.VerifyBaseURL, "/") + "/" + cert.CertificateNumber + "?t=" + signToken(cert, settings.SigningKey)
+}
+
+// renderPDF membangun PDF sertifikat hanya dari data yang tersimpan, sehingga
+// sertifikat yang sama selalu menghasilkan dokumen yang sama.
+func renderPDF(cert *certificate.Certificate, settings Settings) ([]byte, error) {
+	qr, err := qrcode.Encode(verificationContent(cert, settings), qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCreationDate(cert.IssuedAt)
+	pdf.SetModificationDate(cert.IssuedAt)
+	pdf.SetCatalogSort(true)
+	pdf.SetTitle("Surat Keterangan Sehat "+cert.CertificateNumber, true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tr(settings.ClinicName), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, "SURAT KETERANGAN SEHAT PENDAKIAN", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "No. "+cert.CertificateNumber, "", 1, "C", false, 0, "")
+	pdf.Ln(6)
+
+	row := func(label, value string) {
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(55, 7, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, 7, ":", "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 7, tr(value), "", "L", false)
+	}
+
+	row("Nama", cert.PatientName)
+	row("NIK", cert.PatientNIK)
+	row("No. Rekam Medis", cert.MRNumber)
+	if cert.ClimbDate != "" {
+		row("Tanggal Pendakian", cert.ClimbDate)
+	}
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, "Hasil Pemeriksaan Fisik", "", 1, "L", false, 0, "")
+	row("Tekanan Darah", dashIfEmpty(cert.Vitals.BloodPressure, "mmHg"))
+	row("Denyut Nadi", intValue(cert.Vitals.HeartRate, "x/menit"))
+	row("Saturasi Oksigen", intValue(cert.Vitals.OxygenSaturation, "%"))
+	row("Frekuensi Napas", intValue(cert.Vitals.RespiratoryRate, "x/menit"))
+	if cert.Vitals.BodyTemperature != nil {
+		row("Suhu Tubuh", fmt.Sprintf("%.1f °C", *cert.Vitals.BodyTemperature))
+	} else {
+		row("Suhu Tubuh", "-")
+	}
+	row("Status Kesehatan", dashIfEmpty(cert.Vitals.HealthStatus, ""))
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 10, decisionLabels[cert.Decision], "1", 1, "C", false, 0, "")
+	pdf.Ln(2)
+	if cert.Notes != "" {
+		row("Catatan", cert.Notes)
+	}
+	row("Diputuskan oleh", decidedBy(cert))
+	row("Diterbitkan", cert.IssuedAt.Format("02-01-2006 15:04"))
+	row("Berlaku sampai", cert.ValidUntil.Format("02-01-2006 15:04"))
+	pdf.Ln(4)
+
+	y := pdf.GetY()
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", 15, y, 40, 40, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetXY(60, y+12)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.MultiCell(0, 5, "Pindai QR code untuk memverifikasi keaslian surat keterangan ini.", "", "L", false)
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decidedBy(cert *certificate.Certificate) string {
+	role := "Paramedis"
+	if cert.DecidedByRole == "dokter" {
+		role = "Dokter"
+	}
+	if cert.DecidedByName == "" {
+		return role
+	}
+	return cert.DecidedByName + " (" + role + ")"
+}
+
+func dashIfEmpty(value, unit string) string {
+	if value == "" {
+		return "-"
+	}
+	return strings.TrimSpace(value + " " + unit)
+}
+
+func intValue(value *int, unit string) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d %s", *value, unit)
+}
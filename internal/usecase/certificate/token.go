@@ -0,0 +1,52 @@
+package certificate
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"v2/internal/domain/certificate"
+)
+
+// signToken membuat token HMAC-SHA256 yang disematkan di QR code. Token
+// mengikat nomor sertifikat dengan waktu terbit sehingga nomor yang ditebak
+// atau disalin ke dokumen palsu tidak lolos verifikasi.
+func signToken(cert *certificate.Certificate, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(cert.CertificateNumber + "|" + strconv.FormatInt(cert.IssuedAt.Unix(), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func verifyToken(cert *certificate.Certificate, key []byte, token string) bool {
+	if token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(signToken(cert, key)), []byte(token))
+}
+
+// certificateNumber menambahkan akhiran acak pada nomor urut agar nomor
+// sertifikat lain tidak bisa ditebak dari nomor yang dimiliki.
+func certificateNumber(now time.Time, seq int64) (string, error) {
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SKS-%s-%05d-%s", now.Format("200601"), seq, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(suffix)), nil
+}
+
+// initials mengubah "Budi Santoso" menjadi "B.S."
+func initials(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Fields(name) {
+		r := []rune(part)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(".")
+	}
+	return b.String()
+}
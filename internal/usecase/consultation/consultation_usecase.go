@@ -0,0 +1,354 @@
+package consultation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/certificate"
+	"v2/internal/domain/consultation"
+	staffrepo "v2/internal/repository"
+	repo "v2/internal/repository/consultation"
+	icd10repo "v2/internal/repository/icd10"
+	examrepo "v2/internal/repository/physicalexam"
+	auditusecase "v2/internal/usecase/audit"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrConsultationNotFound = errors.New("consultation not found")
+	ErrExaminationNotFound  = errors.New("physical examination not found")
+	ErrDoctorNotFound       = errors.New("doctor not found")
+	ErrNotAssigned          = errors.New("consultation is not assigned to this doctor")
+	ErrInvalidTransition    = errors.New("invalid consultation status transition")
+	ErrInvalidDecision      = errors.New("fitness_decision must be fit, fit_with_conditions or unfit")
+	ErrNotEditable          = errors.New("notes can only be edited while the consultation is accepted or in session")
+	ErrInvalidDiagnosis     = errors.New("invalid diagnosis codes")
+)
+
+// Actor adalah pengguna yang sedang login, diambil dari JWT.
+type Actor struct {
+	UserID string
+	Role   string
+}
+
+type StatusInput struct {
+	Status          string `json:"status"`
+	FitnessDecision string `json:"fitness_decision"`
+	Diagnosis       string `json:"diagnosis"`
+	ReferralNote    string `json:"referral_note"`
+}
+
+type ConsultationUsecase interface {
+	Request(ctx context.Context, examID, reason string, actor Actor) (*consultation.Consultation, error)
+	Assign(ctx context.Context, id, doctorID string) (*consultation.Consultation, error)
+	FindByID(ctx context.Context, id string, actor Actor) (*consultation.Consultation, error)
+	FindPaginated(ctx context.Context, status string, page, limit int) ([]consultation.Consultation, int64, error)
+	Worklist(ctx context.Context, actor Actor, status string, page, limit int) ([]consultation.Consultation, int64, error)
+	UpdateNotes(ctx context.Context, id string, notes consultation.SOAPNotes, diagnosis string, actor Actor) (*consultation.Consultation, error)
+	UpdateStatus(ctx context.Context, id string, input StatusInput, actor Actor) (*consultation.Consultation, error)
+	SetDiagnoses(ctx context.Context, id string, input DiagnosisInput, actor Actor) (*consultation.Consultation, error)
+}
+
+// DiagnosisInput berisi satu kode ICD-10 primer dan kode sekunder opsional.
+type DiagnosisInput struct {
+	Primary   string   `json:"primary"`
+	Secondary []string `json:"secondary"`
+}
+
+type consultationUsecase struct {
+	repo       repo.ConsultationRepository
+	examRepo   examrepo.PhysicalExaminationRepository
+	doctorRepo staffrepo.DoctorRepository
+	icd10Repo  icd10repo.ICD10Repository
+	audit      auditusecase.Recorder
+}
+
+func NewConsultationUsecase(r repo.ConsultationRepository, er examrepo.PhysicalExaminationRepository, dr staffrepo.DoctorRepository, ir icd10repo.ICD10Repository, audit auditusecase.Recorder) ConsultationUsecase {
+	return &consultationUsecase{repo: r, examRepo: er, doctorRepo: dr, icd10Repo: ir, audit: audit}
+}
+
+func (u *consultationUsecase) Request(ctx context.Context, examID, reason string, actor Actor) (*consultation.Consultation, error) {
+	eid, err := uuid.Parse(examID)
+	if err != nil {
+		return nil, err
+	}
+	exam, err := u.examRepo.FindByID(ctx, eid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExaminationNotFound
+		}
+		return nil, err
+	}
+	// Satu pemeriksaan hanya boleh punya satu konsultasi yang belum selesai;
+	// setelah konsultasi terakhir selesai atau dirujuk, konsultasi baru dibuat.
+	if existing, err := u.repo.FindByExaminationID(ctx, eid); err == nil {
+		if !consultation.IsClosed(existing.Status) {
+			return existing, nil
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	now := time.Now()
+	c := &consultation.Consultation{
+		PhysicalExaminationID: exam.ID,
+		PatientID:             exam.PatientID,
+		Status:                consultation.StatusRequested,
+		Reason:                reason,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if uid, err := uuid.Parse(actor.UserID); err == nil {
+		c.RequestedBy = &uid
+	}
+	if err := u.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityConsultation, c.ID.String(), nil, c)
+	if err := u.syncExamination(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (u *consultationUsecase) Assign(ctx context.Context, id, doctorID string) (*consultation.Consultation, error) {
+	c, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	did, err := uuid.Parse(doctorID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := u.doctorRepo.FindByID(ctx, did); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDoctorNotFound
+		}
+		return nil, err
+	}
+	// Penugasan ulang hanya selama konsultasi belum diterima dokter
+	if c.Status != consultation.StatusRequested {
+		return nil, ErrInvalidTransition
+	}
+	before := *c
+	c.DoctorID = &did
+	c.UpdatedAt = time.Now()
+	if err := u.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityConsultation, id, before, c)
+	return c, nil
+}
+
+func (u *consultationUsecase) FindByID(ctx context.Context, id string, actor Actor) (*consultation.Consultation, error) {
+	c, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Role == "dokter" {
+		if err := u.checkAssigned(ctx, c, actor); err != nil {
+			return nil, err
+		}
+	}
+	if c.DiagnosisCodes, err = u.repo.FindDiagnoses(ctx, c.ID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (u *consultationUsecase) FindPaginated(ctx context.Context, status string, page, limit int) ([]consultation.Consultation, int64, error) {
+	return u.repo.FindPaginated(ctx, nil, status, page, limit)
+}
+
+// Worklist hanya berisi konsultasi yang ditugaskan ke dokter yang login.
+func (u *consultationUsecase) Worklist(ctx context.Context, actor Actor, status string, page, limit int) ([]consultation.Consultation, int64, error) {
+	doctorID, err := u.doctorID(ctx, actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return u.repo.FindPaginated(ctx, &doctorID, status, page, limit)
+}
+
+func (u *consultationUsecase) UpdateNotes(ctx context.Context, id string, notes consultation.SOAPNotes, diagnosis string, actor Actor) (*consultation.Consultation, error) {
+	c, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkAssigned(ctx, c, actor); err != nil {
+		return nil, err
+	}
+	if c.Status != consultation.StatusAccepted && c.Status != consultation.StatusInSession {
+		return nil, ErrNotEditable
+	}
+	before := *c
+	c.Notes = notes
+	if diagnosis != "" {
+		c.Diagnosis = diagnosis
+	}
+	c.UpdatedAt = time.Now()
+	if err := u.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityConsultation, id, before, c)
+	return c, nil
+}
+
+func (u *consultationUsecase) UpdateStatus(ctx context.Context, id string, input StatusInput, actor Actor) (*consultation.Consultation, error) {
+	c, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkAssigned(ctx, c, actor); err != nil {
+		return nil, err
+	}
+	if !consultation.CanTransition(c.Status, input.Status) {
+		return nil, ErrInvalidTransition
+	}
+	before := *c
+	now := time.Now()
+	switch input.Status {
+	case consultation.StatusAccepted:
+		c.AcceptedAt = &now
+	case consultation.StatusInSession:
+		c.StartedAt = &now
+	case consultation.StatusCompleted:
+		switch input.FitnessDecision {
+		case certificate.DecisionFit, certificate.DecisionFitWithConditions, certificate.DecisionUnfit:
+		default:
+			return nil, ErrInvalidDecision
+		}
+		c.FitnessDecision = input.FitnessDecision
+		c.FinishedAt = &now
+	case consultation.StatusReferred:
+		c.ReferralNote = input.ReferralNote
+		c.FitnessDecision = certificate.DecisionUnfit
+		c.FinishedAt = &now
+	}
+	if input.Diagnosis != "" {
+		c.Diagnosis = input.Diagnosis
+	}
+	c.Status = input.Status
+	c.UpdatedAt = now
+	if err := u.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityConsultation, id, before, c)
+	if err := u.syncExamination(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetDiagnoses mengganti kode diagnosis konsultasi. Pengodean masih boleh
+// dilakukan setelah konsultasi selesai, tetapi tidak sebelum diterima dokter.
+func (u *consultationUsecase) SetDiagnoses(ctx context.Context, id string, input DiagnosisInput, actor Actor) (*consultation.Consultation, error) {
+	c, err := u.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkAssigned(ctx, c, actor); err != nil {
+		return nil, err
+	}
+	if c.Status == consultation.StatusRequested {
+		return nil, ErrNotEditable
+	}
+	primary := strings.ToUpper(strings.TrimSpace(input.Primary))
+	if primary == "" {
+		return nil, fmt.Errorf("%w: primary is required", ErrInvalidDiagnosis)
+	}
+	codes := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, code := range input.Secondary {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	found, err := u.icd10Repo.FindByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(found))
+	for _, f := range found {
+		known[f.Code] = true
+	}
+	diagnoses := make([]consultation.Diagnosis, 0, len(codes))
+	for i, code := range codes {
+		if !known[code] {
+			return nil, fmt.Errorf("%w: unknown code %s", ErrInvalidDiagnosis, code)
+		}
+		diagnoses = append(diagnoses, consultation.Diagnosis{Code: code, Primary: i == 0})
+	}
+	previous, err := u.repo.FindDiagnoses(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.ReplaceDiagnoses(ctx, c.ID, diagnoses); err != nil {
+		return nil, err
+	}
+	if c.DiagnosisCodes, err = u.repo.FindDiagnoses(ctx, c.ID); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityConsultation, id,
+		map[string]interface{}{"diagnosis_codes": previous}, map[string]interface{}{"diagnosis_codes": c.DiagnosisCodes})
+	return c, nil
+}
+
+// syncExamination menjaga field konsultasi lama di pemeriksaan fisik tetap
+// sesuai, karena sertifikat masih membaca dokter dari pemeriksaan.
+func (u *consultationUsecase) syncExamination(ctx context.Context, c *consultation.Consultation) error {
+	var doctorID *uuid.UUID
+	advice := ""
+	if c.Status == consultation.StatusCompleted || c.Status == consultation.StatusReferred {
+		doctorID = c.DoctorID
+		advice = c.Notes.Plan
+	}
+	return u.examRepo.SyncConsultation(ctx, c.PhysicalExaminationID, c.Status, doctorID, advice)
+}
+
+func (u *consultationUsecase) find(ctx context.Context, id string) (*consultation.Consultation, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	c, err := u.repo.FindByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrConsultationNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+func (u *consultationUsecase) doctorID(ctx context.Context, actor Actor) (uuid.UUID, error) {
+	uid, err := uuid.Parse(actor.UserID)
+	if err != nil {
+		return uuid.Nil, ErrDoctorNotFound
+	}
+	doctor, err := u.doctorRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, ErrDoctorNotFound
+		}
+		return uuid.Nil, err
+	}
+	return doctor.ID, nil
+}
+
+func (u *consultationUsecase) checkAssigned(ctx context.Context, c *consultation.Consultation, actor Actor) error {
+	doctorID, err := u.doctorID(ctx, actor)
+	if err != nil {
+		return err
+	}
+	if c.DoctorID == nil || *c.DoctorID != doctorID {
+		return ErrNotAssigned
+	}
+	return nil
+}
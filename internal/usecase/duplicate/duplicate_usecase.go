@@ -0,0 +1,146 @@
+package duplicate
+
+import (
+	"context"
+	"errors"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/duplicate"
+	repo "v2/internal/repository/duplicate"
+	patientrepo "v2/internal/repository/roles"
+	auditusecase "v2/internal/usecase/audit"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MinScore adalah skor minimal agar pasangan pasien masuk antrean review.
+// Nama yang sama persis saja (0.5) belum cukup; perlu tanggal lahir, telepon
+// atau email yang sama.
+const MinScore = 0.55
+
+var (
+	ErrCandidateNotFound = errors.New("duplicate candidate not found")
+	ErrPatientNotFound   = errors.New("patient not found")
+	ErrNotPending        = errors.New("duplicate candidate has already been reviewed")
+	ErrInvalidSurvivor   = errors.New("survivor_id must be one of the candidate patients")
+	ErrAlreadyMerged     = errors.New("patient has already been merged")
+	ErrBothLinked        = errors.New("both patients have login accounts; unlink one before merging")
+)
+
+type DuplicateUsecase interface {
+	Scan(ctx context.Context) (int64, error)
+	FindPaginated(ctx context.Context, status string, page, limit int) ([]duplicate.Candidate, int64, error)
+	Dismiss(ctx context.Context, id, actorID string) (*duplicate.Candidate, error)
+	Merge(ctx context.Context, id, survivorID, actorID string) (*duplicate.Merge, error)
+	FindMerges(ctx context.Context, patientID string) ([]duplicate.Merge, error)
+}
+
+type duplicateUsecase struct {
+	repo        repo.DuplicateRepository
+	patientRepo patientrepo.PatientRepository
+	audit       auditusecase.Recorder
+}
+
+func NewDuplicateUsecase(r repo.DuplicateRepository, pr patientrepo.PatientRepository, audit auditusecase.Recorder) DuplicateUsecase {
+	return &duplicateUsecase{repo: r, patientRepo: pr, audit: audit}
+}
+
+// Scan mencari kandidat duplikat di seluruh pasien dan mengembalikan jumlah kandidat baru.
+func (u *duplicateUsecase) Scan(ctx context.Context) (int64, error) {
+	return u.repo.Detect(ctx, nil, MinScore)
+}
+
+func (u *duplicateUsecase) FindPaginated(ctx context.Context, status string, page, limit int) ([]duplicate.Candidate, int64, error) {
+	return u.repo.FindPaginated(ctx, status, page, limit)
+}
+
+func (u *duplicateUsecase) Dismiss(ctx context.Context, id, actorID string) (*duplicate.Candidate, error) {
+	c, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateStatus(ctx, c.ID, duplicate.StatusDismissed, actor(actorID)); err != nil {
+		return nil, err
+	}
+	return u.repo.FindByID(ctx, c.ID)
+}
+
+// Merge menggabungkan pasien lain pada kandidat ke survivorID.
+func (u *duplicateUsecase) Merge(ctx context.Context, id, survivorID, actorID string) (*duplicate.Merge, error) {
+	c, err := u.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sid, err := uuid.Parse(survivorID)
+	if err != nil {
+		return nil, ErrInvalidSurvivor
+	}
+	var mergedID uuid.UUID
+	switch sid {
+	case c.Patient.ID:
+		mergedID = c.Candidate.ID
+	case c.Candidate.ID:
+		mergedID = c.Patient.ID
+	default:
+		return nil, ErrInvalidSurvivor
+	}
+
+	survivor, err := u.patientRepo.FindByID(ctx, sid)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := u.patientRepo.FindByID(ctx, mergedID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor.MergedInto != nil || merged.MergedInto != nil {
+		return nil, ErrAlreadyMerged
+	}
+	if survivor.UserID != nil && merged.UserID != nil {
+		return nil, ErrBothLinked
+	}
+
+	m, err := u.repo.Merge(ctx, sid, mergedID, &c.ID, actor(actorID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlreadyMerged
+		}
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionMerge, audit.EntityPatient, mergedID.String(), merged, m)
+	return m, nil
+}
+
+func (u *duplicateUsecase) FindMerges(ctx context.Context, patientID string) ([]duplicate.Merge, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+	return u.repo.FindMerges(ctx, pid)
+}
+
+func (u *duplicateUsecase) pending(ctx context.Context, id string) (*duplicate.Candidate, error) {
+	cid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrCandidateNotFound
+	}
+	c, err := u.repo.FindByID(ctx, cid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCandidateNotFound
+		}
+		return nil, err
+	}
+	if c.Status != duplicate.StatusPending {
+		return nil, ErrNotPending
+	}
+	return c, nil
+}
+
+func actor(userID string) *uuid.UUID {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,39 @@
+package icd10
+
+import (
+	"context"
+	"errors"
+	"time"
+	"v2/internal/domain/icd10"
+	repo "v2/internal/repository/icd10"
+)
+
+var ErrInvalidRange = errors.New("from must be before to")
+
+type ICD10Usecase interface {
+	Search(ctx context.Context, query string, limit int) ([]icd10.Code, error)
+	DiagnosisReport(ctx context.Context, from, to time.Time) ([]icd10.DiagnosisCount, error)
+}
+
+type icd10Usecase struct {
+	repo repo.ICD10Repository
+}
+
+func NewICD10Usecase(r repo.ICD10Repository) ICD10Usecase {
+	return &icd10Usecase{repo: r}
+}
+
+func (u *icd10Usecase) Search(ctx context.Context, query string, limit int) ([]icd10.Code, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	return u.repo.Search(ctx, query, limit)
+}
+
+// DiagnosisReport menghitung diagnosis per kode; to bersifat eksklusif.
+func (u *icd10Usecase) DiagnosisReport(ctx context.Context, from, to time.Time) ([]icd10.DiagnosisCount, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	return u.repo.CountDiagnoses(ctx, from, to)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"time"
+	"v2/internal/domain/audit"
 	"v2/internal/domain/medicalrecord"
 	repo "v2/internal/repository/medicalrecord"
+	auditusecase "v2/internal/usecase/audit"
 
 	"github.com/google/uuid"
 )
@@ -17,12 +19,14 @@ type MedicalRecordUsecase interface {
 type medicalRecordUsecase struct {
 	recordRepo  repo.MedicalRecordRepository
 	counterRepo repo.CounterRepository
+	audit       auditusecase.Recorder
 }
 
-func NewMedicalRecordUsecase(rr repo.MedicalRecordRepository, cr repo.CounterRepository) MedicalRecordUsecase {
+func NewMedicalRecordUsecase(rr repo.MedicalRecordRepository, cr repo.CounterRepository, audit auditusecase.Recorder) MedicalRecordUsecase {
 	return &medicalRecordUsecase{
 		recordRepo:  rr,
 		counterRepo: cr,
+		audit:       audit,
 	}
 }
 
@@ -53,5 +57,6 @@ func (u *medicalRecordUsecase) CreateMedicalRecord(ctx context.Context, patientI
 	if err := u.recordRepo.Create(ctx, mr); err != nil {
 		return nil, err
 	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityMedicalRecord, mr.ID.String(), nil, mr)
 	return mr, nil
 }
@@ -2,15 +2,24 @@ package medicine
 
 import (
 	"context"
+	"errors"
 	"v2/internal/domain/medicine"
+	"v2/internal/metrics"
 	repo "v2/internal/repository/medicine"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+var ErrMedicineNotFound = errors.New("medicine not found")
+
 type MedicineUsecase interface {
 	Create(ctx context.Context, medicine *medicine.Medicine) error
 	Update(ctx context.Context, id string, update map[string]interface{}) error
 	FindAll(ctx context.Context) ([]medicine.Medicine, error)
 	FindAllPaginated(ctx context.Context, page, limit int) ([]medicine.Medicine, int64, error)
+	Delete(ctx context.Context, id, actorID string) error
+	Restore(ctx context.Context, id string) error
 }
 
 type medicineUsecase struct {
@@ -26,7 +35,20 @@ func (u *medicineUsecase) Create(ctx context.Context, medicine *medicine.Medicin
 }
 
 func (u *medicineUsecase) Update(ctx context.Context, id string, update map[string]interface{}) error {
-	return u.repo.Update(ctx, id, update)
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrMedicineNotFound
+	}
+	if err := u.repo.Update(ctx, uid, update); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	if q, ok := update["quantity"].(float64); ok && q <= 0 {
+		metrics.MedicineStockouts.Inc()
+	}
+	return nil
 }
 
 func (u *medicineUsecase) FindAll(ctx context.Context) ([]medicine.Medicine, error) {
@@ -36,3 +58,35 @@ func (u *medicineUsecase) FindAll(ctx context.Context) ([]medicine.Medicine, err
 func (u *medicineUsecase) FindAllPaginated(ctx context.Context, page, limit int) ([]medicine.Medicine, int64, error) {
 	return u.repo.FindAllPaginated(ctx, page, limit)
 }
+
+func (u *medicineUsecase) Delete(ctx context.Context, id, actorID string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrMedicineNotFound
+	}
+	var deletedBy *uuid.UUID
+	if by, err := uuid.Parse(actorID); err == nil {
+		deletedBy = &by
+	}
+	if err := u.repo.SoftDelete(ctx, uid, deletedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *medicineUsecase) Restore(ctx context.Context, id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrMedicineNotFound
+	}
+	if err := u.repo.Restore(ctx, uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrMedicineNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -2,42 +2,213 @@ package physicalexam
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/consultation"
 	"v2/internal/domain/physicalexam"
+	"v2/internal/metrics"
+	consultationrepo "v2/internal/repository/consultation"
 	repo "v2/internal/repository/physicalexam"
+	"v2/internal/tracing"
+	auditusecase "v2/internal/usecase/audit"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type PhysicalExaminationUsecase interface {
 	Create(ctx context.Context, exam *physicalexam.PhysicalExamination) error
 	FindByPatientID(ctx context.Context, patientID string) ([]physicalexam.PhysicalExamination, error)
-	FindDoctorConsultations(ctx context.Context) ([]physicalexam.PhysicalExamination, error)
-	UpdateConsultationStatus(ctx context.Context, id string, status string) error
 	Update(ctx context.Context, id string, update map[string]interface{}) error
+	Delete(ctx context.Context, id, actorID string) error
+	Restore(ctx context.Context, id string) (*physicalexam.PhysicalExamination, error)
 }
 
+var ErrExaminationNotFound = errors.New("physical examination not found")
+
 type physicalExaminationUsecase struct {
-	repo repo.PhysicalExaminationRepository
+	repo             repo.PhysicalExaminationRepository
+	consultationRepo consultationrepo.ConsultationRepository
+	ranges           VitalRanges
+	audit            auditusecase.Recorder
 }
 
-func NewPhysicalExaminationUsecase(r repo.PhysicalExaminationRepository) PhysicalExaminationUsecase {
-	return &physicalExaminationUsecase{repo: r}
+func NewPhysicalExaminationUsecase(r repo.PhysicalExaminationRepository, cr consultationrepo.ConsultationRepository, ranges VitalRanges, audit auditusecase.Recorder) PhysicalExaminationUsecase {
+	return &physicalExaminationUsecase{repo: r, consultationRepo: cr, ranges: ranges, audit: audit}
 }
 
 func (u *physicalExaminationUsecase) Create(ctx context.Context, exam *physicalexam.PhysicalExamination) error {
-	return u.repo.Create(ctx, exam)
+	ctx, span := tracing.Start(ctx, "PhysicalExaminationUsecase.Create")
+	defer span.End()
+	if err := u.applyVitalChecks(exam); err != nil {
+		return err
+	}
+	now := time.Now()
+	exam.CreatedAt = now
+	exam.UpdatedAt = now
+	if err := u.repo.Create(ctx, exam); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityPhysicalExamination, exam.ID.String(), nil, exam)
+	if exam.HealthStatus != "" {
+		metrics.ExaminationAssessed(exam.HealthStatus)
+	}
+	return u.ensureConsultation(ctx, exam)
 }
 
-func (u *physicalExaminationUsecase) FindByPatientID(ctx context.Context, patientID string) ([]physicalexam.PhysicalExamination, error) {
-	return u.repo.FindByPatientID(ctx, patientID)
+// ensureConsultation membuat permintaan konsultasi dokter untuk pemeriksaan
+// yang membutuhkan konsultasi dan belum memilikinya.
+func (u *physicalExaminationUsecase) ensureConsultation(ctx context.Context, exam *physicalexam.PhysicalExamination) error {
+	if !exam.KonsultasiDokter {
+		return nil
+	}
+	if _, err := u.consultationRepo.FindByExaminationID(ctx, exam.ID); err == nil {
+		return nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	reason := exam.Reason
+	if len(exam.AbnormalFlags) > 0 {
+		reason = strings.TrimSpace(reason + " [tanda vital abnormal: " + strings.Join(exam.AbnormalFlags, ", ") + "]")
+	}
+	now := time.Now()
+	c := &consultation.Consultation{
+		PhysicalExaminationID: exam.ID,
+		PatientID:             exam.PatientID,
+		Status:                consultation.StatusRequested,
+		Reason:                reason,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if err := u.consultationRepo.Create(ctx, c); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityConsultation, c.ID.String(), nil, c)
+	return nil
 }
 
-func (u *physicalExaminationUsecase) FindDoctorConsultations(ctx context.Context) ([]physicalexam.PhysicalExamination, error) {
-	return u.repo.FindDoctorConsultations(ctx)
+// applyVitalChecks memvalidasi tanda vital dan otomatis meminta konsultasi
+// dokter bila ada nilai di luar rentang klinis.
+func (u *physicalExaminationUsecase) applyVitalChecks(exam *physicalexam.PhysicalExamination) error {
+	flags, err := checkVitals(exam, u.ranges)
+	if err != nil {
+		return err
+	}
+	exam.AbnormalFlags = flags
+	if len(flags) > 0 && !exam.KonsultasiDokter {
+		exam.KonsultasiDokter = true
+		if exam.KonsultasiDokterStatus == "" {
+			exam.KonsultasiDokterStatus = "pending"
+		}
+	}
+	return nil
 }
 
-func (u *physicalExaminationUsecase) UpdateConsultationStatus(ctx context.Context, id string, status string) error {
-	return u.repo.UpdateConsultationStatus(ctx, id, status)
+func (u *physicalExaminationUsecase) FindByPatientID(ctx context.Context, patientID string) ([]physicalexam.PhysicalExamination, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.FindByPatientID(ctx, pid)
 }
 
 func (u *physicalExaminationUsecase) Update(ctx context.Context, id string, update map[string]interface{}) error {
-	return u.repo.Update(ctx, id, update)
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrExaminationNotFound
+	}
+	// Gabungkan perubahan ke data lama supaya validasi tanda vital memakai nilai lengkap
+	exam, err := u.repo.FindByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExaminationNotFound
+		}
+		return err
+	}
+	before := *exam
+	data, _ := json.Marshal(update)
+	if err := json.Unmarshal(data, exam); err != nil {
+		return err
+	}
+	if err := u.applyVitalChecks(exam); err != nil {
+		return err
+	}
+	err = u.repo.Update(ctx, uid, map[string]interface{}{
+		"blood_pressure":           exam.BloodPressure,
+		"systolic":                 exam.Systolic,
+		"diastolic":                exam.Diastolic,
+		"heart_rate":               exam.HeartRate,
+		"oxygen_saturation":        exam.OxygenSaturation,
+		"respiratory_rate":         exam.RespiratoryRate,
+		"body_temperature":         exam.BodyTemperature,
+		"physical_assessment":      exam.PhysicalAssessment,
+		"reason":                   exam.Reason,
+		"medical_advice":           exam.MedicalAdvice,
+		"health_status":            exam.HealthStatus,
+		"pendampingan":             exam.Pendampingan,
+		"konsultasi_dokter":        exam.KonsultasiDokter,
+		"konsultasi_dokter_status": exam.KonsultasiDokterStatus,
+		"doctor_advice":            exam.DoctorAdvice,
+		"abnormal_flags":           exam.AbnormalFlags,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExaminationNotFound
+		}
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityPhysicalExamination, id, before, exam)
+	if exam.HealthStatus != "" && exam.HealthStatus != before.HealthStatus {
+		metrics.ExaminationAssessed(exam.HealthStatus)
+	}
+	return u.ensureConsultation(ctx, exam)
+}
+
+// Delete melakukan soft delete pemeriksaan; konsultasi dan sertifikat terkait tidak diubah.
+func (u *physicalExaminationUsecase) Delete(ctx context.Context, id, actorID string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrExaminationNotFound
+	}
+	before, err := u.repo.FindByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExaminationNotFound
+		}
+		return err
+	}
+	var deletedBy *uuid.UUID
+	if by, err := uuid.Parse(actorID); err == nil {
+		deletedBy = &by
+	}
+	if err := u.repo.SoftDelete(ctx, uid, deletedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrExaminationNotFound
+		}
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionDelete, audit.EntityPhysicalExamination, id, before, nil)
+	return nil
+}
+
+func (u *physicalExaminationUsecase) Restore(ctx context.Context, id string) (*physicalexam.PhysicalExamination, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrExaminationNotFound
+	}
+	if err := u.repo.Restore(ctx, uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExaminationNotFound
+		}
+		return nil, err
+	}
+	exam, err := u.repo.FindByID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionRestore, audit.EntityPhysicalExamination, id, nil, exam)
+	return exam, nil
 }
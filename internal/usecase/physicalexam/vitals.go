@@ -0,0 +1,137 @@
+package physicalexam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"v2/internal/domain/physicalexam"
+)
+
+var ErrInvalidVitals = errors.New("invalid vital signs")
+
+// Range adalah rentang nilai normal (inklusif).
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// VitalRanges adalah rentang klinis normal. Nilai di luar rentang tidak
+// ditolak, tetapi ditandai dan memicu konsultasi dokter.
+type VitalRanges struct {
+	HeartRate        Range `json:"heart_rate"`
+	OxygenSaturation Range `json:"oxygen_saturation"`
+	RespiratoryRate  Range `json:"respiratory_rate"`
+	BodyTemperature  Range `json:"body_temperature"`
+	Systolic         Range `json:"systolic"`
+	Diastolic        Range `json:"diastolic"`
+}
+
+// DefaultVitalRanges memakai ambang untuk pendakian di ketinggian, misalnya
+// SpO2 di bawah 94% sudah dianggap abnormal.
+func DefaultVitalRanges() VitalRanges {
+	return VitalRanges{
+		HeartRate:        Range{Min: 60, Max: 100},
+		OxygenSaturation: Range{Min: 94, Max: 100},
+		RespiratoryRate:  Range{Min: 12, Max: 20},
+		BodyTemperature:  Range{Min: 36.0, Max: 37.5},
+		Systolic:         Range{Min: 90, Max: 140},
+		Diastolic:        Range{Min: 60, Max: 90},
+	}
+}
+
+// LoadVitalRanges membaca rentang klinis dari file JSON. Field yang tidak
+// diisi tetap memakai nilai default.
+func LoadVitalRanges(path string) (VitalRanges, error) {
+	ranges := DefaultVitalRanges()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ranges, err
+	}
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return ranges, err
+	}
+	return ranges, nil
+}
+
+// Batas fisiologis mutlak; nilai di luar ini pasti salah input.
+var hardLimits = VitalRanges{
+	HeartRate:        Range{Min: 20, Max: 300},
+	OxygenSaturation: Range{Min: 50, Max: 100},
+	RespiratoryRate:  Range{Min: 4, Max: 80},
+	BodyTemperature:  Range{Min: 25, Max: 45},
+	Systolic:         Range{Min: 50, Max: 300},
+	Diastolic:        Range{Min: 20, Max: 200},
+}
+
+// parseBloodPressure mengurai "120/80" menjadi sistolik dan diastolik.
+func parseBloodPressure(bp string) (int, int, error) {
+	parts := strings.Split(strings.TrimSpace(bp), "/")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("%w: blood_pressure must be formatted as systolic/diastolic", ErrInvalidVitals)
+	}
+	sys, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	dia, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("%w: blood_pressure must be numeric", ErrInvalidVitals)
+	}
+	return sys, dia, nil
+}
+
+type vitalValue struct {
+	name  string
+	value *float64
+	hard  Range
+	ref   Range
+}
+
+// checkVitals mengisi sistolik/diastolik dari BloodPressure, menolak nilai di
+// luar batas fisiologis dan mengembalikan daftar penanda nilai abnormal.
+func checkVitals(exam *physicalexam.PhysicalExamination, ranges VitalRanges) ([]string, error) {
+	exam.Systolic, exam.Diastolic = nil, nil
+	if exam.BloodPressure != "" {
+		sys, dia, err := parseBloodPressure(exam.BloodPressure)
+		if err != nil {
+			return nil, err
+		}
+		if sys <= dia {
+			return nil, fmt.Errorf("%w: systolic must be greater than diastolic", ErrInvalidVitals)
+		}
+		exam.Systolic, exam.Diastolic = &sys, &dia
+		exam.BloodPressure = fmt.Sprintf("%d/%d", sys, dia)
+	}
+
+	values := []vitalValue{
+		{"heart_rate", intToFloat(exam.HeartRate), hardLimits.HeartRate, ranges.HeartRate},
+		{"oxygen_saturation", intToFloat(exam.OxygenSaturation), hardLimits.OxygenSaturation, ranges.OxygenSaturation},
+		{"respiratory_rate", intToFloat(exam.RespiratoryRate), hardLimits.RespiratoryRate, ranges.RespiratoryRate},
+		{"body_temperature", exam.BodyTemperature, hardLimits.BodyTemperature, ranges.BodyTemperature},
+		{"systolic", intToFloat(exam.Systolic), hardLimits.Systolic, ranges.Systolic},
+		{"diastolic", intToFloat(exam.Diastolic), hardLimits.Diastolic, ranges.Diastolic},
+	}
+	flags := []string{}
+	for _, v := range values {
+		if v.value == nil {
+			continue
+		}
+		if *v.value < v.hard.Min || *v.value > v.hard.Max {
+			return nil, fmt.Errorf("%w: %s %v outside physiological limits %v-%v", ErrInvalidVitals, v.name, *v.value, v.hard.Min, v.hard.Max)
+		}
+		if *v.value < v.ref.Min {
+			flags = append(flags, v.name+"_low")
+		} else if *v.value > v.ref.Max {
+			flags = append(flags, v.name+"_high")
+		}
+	}
+	return flags, nil
+}
+
+func intToFloat(v *int) *float64 {
+	if v == nil {
+		return nil
+	}
+	f := float64(*v)
+	return &f
+}
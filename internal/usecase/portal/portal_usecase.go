@@ -0,0 +1,213 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/certificate"
+	"v2/internal/domain/consultation"
+	"v2/internal/domain/physicalexam"
+	"v2/internal/domain/roles"
+	"v2/internal/domain/screening"
+	"v2/internal/domain/timeline"
+	certrepo "v2/internal/repository/certificate"
+	consultationrepo "v2/internal/repository/consultation"
+	examrepo "v2/internal/repository/physicalexam"
+	patientrepo "v2/internal/repository/roles"
+	screeningrepo "v2/internal/repository/screening"
+	auditusecase "v2/internal/usecase/audit"
+	timelineusecase "v2/internal/usecase/timeline"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPatientNotLinked = errors.New("no patient profile linked to this account")
+	ErrNotInQueue       = errors.New("patient is not in the queue")
+)
+
+// ProfileInput berisi field profil yang boleh diubah pasien. Field nil tidak diubah.
+type ProfileInput struct {
+	Phone    *string `json:"phone"`
+	Address  *string `json:"address"`
+	RT       *string `json:"rt"`
+	RW       *string `json:"rw"`
+	Village  *string `json:"village"`
+	District *string `json:"district"`
+	Marital  *string `json:"marital"`
+	Job      *string `json:"job"`
+	Height   *int    `json:"height"`
+	Weight   *int    `json:"weight"`
+}
+
+// PortalUsecase melayani pasien yang login. Semua method menerima user_id dari
+// JWT dan hanya membaca data pasien yang terhubung ke akun tersebut; tidak ada
+// method yang menerima ID pasien dari request.
+type PortalUsecase interface {
+	Profile(ctx context.Context, userID string) (*roles.Patient, error)
+	UpdateProfile(ctx context.Context, userID string, input ProfileInput) (*roles.Patient, error)
+	Screenings(ctx context.Context, userID string) ([]screening.ScreeningAnswer, error)
+	Examinations(ctx context.Context, userID string) ([]physicalexam.PhysicalExamination, error)
+	Consultations(ctx context.Context, userID string) ([]consultation.Consultation, error)
+	Certificates(ctx context.Context, userID string) ([]certificate.Certificate, error)
+	Timeline(ctx context.Context, userID string, types []string, page, limit int) ([]timeline.Event, int64, error)
+	QueuePosition(ctx context.Context, userID string) (*screening.QueuePosition, error)
+}
+
+type portalUsecase struct {
+	patientRepo      patientrepo.PatientRepository
+	answerRepo       screeningrepo.AnswerRepository
+	queueRepo        screeningrepo.QueueRepository
+	examRepo         examrepo.PhysicalExaminationRepository
+	consultationRepo consultationrepo.ConsultationRepository
+	certificateRepo  certrepo.CertificateRepository
+	timeline         timelineusecase.TimelineUsecase
+	audit            auditusecase.Recorder
+}
+
+func NewPortalUsecase(pr patientrepo.PatientRepository, ar screeningrepo.AnswerRepository, qr screeningrepo.QueueRepository, er examrepo.PhysicalExaminationRepository, csr consultationrepo.ConsultationRepository, cr certrepo.CertificateRepository, tl timelineusecase.TimelineUsecase, audit auditusecase.Recorder) PortalUsecase {
+	return &portalUsecase{patientRepo: pr, answerRepo: ar, queueRepo: qr, examRepo: er, consultationRepo: csr, certificateRepo: cr, timeline: tl, audit: audit}
+}
+
+func (u *portalUsecase) Profile(ctx context.Context, userID string) (*roles.Patient, error) {
+	return u.patient(ctx, userID)
+}
+
+func (u *portalUsecase) UpdateProfile(ctx context.Context, userID string, input ProfileInput) (*roles.Patient, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *p
+	setString(&p.Phone, input.Phone)
+	setString(&p.Address, input.Address)
+	setString(&p.RT, input.RT)
+	setString(&p.RW, input.RW)
+	setString(&p.Village, input.Village)
+	setString(&p.District, input.District)
+	setString(&p.Marital, input.Marital)
+	setString(&p.Job, input.Job)
+	if input.Height != nil {
+		p.Height = *input.Height
+	}
+	if input.Weight != nil {
+		p.Weight = *input.Weight
+	}
+	p.UpdatedAt = time.Now()
+	if err := u.patientRepo.UpdateProfile(ctx, p); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityPatient, p.ID.String(), before, p)
+	return p, nil
+}
+
+func (u *portalUsecase) Screenings(ctx context.Context, userID string) ([]screening.ScreeningAnswer, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p.NIK == "" {
+		return nil, nil
+	}
+	answers, err := u.answerRepo.FindByNIK(ctx, p.NIK)
+	if err != nil {
+		return nil, err
+	}
+	// Repository mencocokkan lewat blind index; NIK hasil dekripsi dicek lagi
+	// agar tabrakan index tidak pernah membuka data pasien lain.
+	result := make([]screening.ScreeningAnswer, 0, len(answers))
+	for _, a := range answers {
+		if a.PatientInfo.NIK == p.NIK {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (u *portalUsecase) Examinations(ctx context.Context, userID string) ([]physicalexam.PhysicalExamination, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.examRepo.FindByPatientID(ctx, p.ID)
+}
+
+func (u *portalUsecase) Consultations(ctx context.Context, userID string) ([]consultation.Consultation, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.consultationRepo.FindByPatientID(ctx, p.ID)
+}
+
+func (u *portalUsecase) Certificates(ctx context.Context, userID string) ([]certificate.Certificate, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.certificateRepo.FindByPatientID(ctx, p.ID)
+}
+
+// Timeline memakai validasi tipe yang sama dengan timeline untuk staf.
+func (u *portalUsecase) Timeline(ctx context.Context, userID string, types []string, page, limit int) ([]timeline.Event, int64, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return u.timeline.FindByPatientID(ctx, p.ID.String(), types, page, limit)
+}
+
+func (u *portalUsecase) QueuePosition(ctx context.Context, userID string) (*screening.QueuePosition, error) {
+	p, err := u.patient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p.NIK == "" {
+		return nil, ErrNotInQueue
+	}
+	q, err := u.queueRepo.FindActiveByNIK(ctx, p.NIK)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotInQueue
+		}
+		return nil, err
+	}
+	if q.PatientInfo.NIK != p.NIK {
+		return nil, ErrNotInQueue
+	}
+	result := &screening.QueuePosition{Queue: *q}
+	// Pasien yang sedang dilayani tidak lagi punya posisi antrean
+	if q.Status == screening.QueueWaiting {
+		ahead, err := u.queueRepo.CountAhead(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		result.Ahead = ahead
+		result.Position = ahead + 1
+	}
+	return result, nil
+}
+
+// patient adalah satu-satunya jalan untuk menentukan pasien yang diakses.
+func (u *portalUsecase) patient(ctx context.Context, userID string) (*roles.Patient, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrPatientNotLinked
+	}
+	p, err := u.patientRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPatientNotLinked
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+func setString(dst *string, v *string) {
+	if v != nil {
+		*dst = *v
+	}
+}
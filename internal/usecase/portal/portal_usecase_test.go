@@ -0,0 +1,344 @@
+package portal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"v2/internal/domain/certificate"
+	"v2/internal/domain/consultation"
+	"v2/internal/domain/physicalexam"
+	"v2/internal/domain/roles"
+	"v2/internal/domain/screening"
+	"v2/internal/domain/timeline"
+	certrepo "v2/internal/repository/certificate"
+	consultationrepo "v2/internal/repository/consultation"
+	examrepo "v2/internal/repository/physicalexam"
+	patientrepo "v2/internal/repository/roles"
+	screeningrepo "v2/internal/repository/screening"
+	timelineusecase "v2/internal/usecase/timeline"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fake repository menyimpan data beberapa pasien sekaligus dan menyaring
+// seperti query Postgres-nya. Method yang tidak dipakai portal dibiarkan
+// lewat interface yang di-embed (panic jika terpanggil).
+
+type fakePatients struct {
+	patientrepo.PatientRepository
+	rows    []roles.Patient
+	updated []uuid.UUID
+}
+
+func (f *fakePatients) FindByUserID(_ context.Context, userID uuid.UUID) (*roles.Patient, error) {
+	for _, p := range f.rows {
+		if p.UserID != nil && *p.UserID == userID {
+			p := p
+			return &p, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (f *fakePatients) UpdateProfile(_ context.Context, p *roles.Patient) error {
+	f.updated = append(f.updated, p.ID)
+	return nil
+}
+
+// nikIndex meniru blind index; test tabrakan menggantinya dengan fungsi
+// yang memberi index sama untuk NIK berbeda.
+type nikIndex func(nik string) string
+
+func exactIndex(nik string) string { return nik }
+
+type fakeAnswers struct {
+	screeningrepo.AnswerRepository
+	index nikIndex
+	rows  []screening.ScreeningAnswer
+}
+
+func (f *fakeAnswers) FindByNIK(_ context.Context, nik string) ([]screening.ScreeningAnswer, error) {
+	var result []screening.ScreeningAnswer
+	for _, a := range f.rows {
+		if f.index(a.PatientInfo.NIK) == f.index(nik) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+type fakeQueues struct {
+	screeningrepo.QueueRepository
+	index nikIndex
+	rows  []screening.ScreeningQueue
+}
+
+func (f *fakeQueues) FindActiveByNIK(_ context.Context, nik string) (*screening.ScreeningQueue, error) {
+	for _, q := range f.rows {
+		if f.index(q.PatientInfo.NIK) == f.index(nik) && q.Status != screening.QueueDone {
+			q := q
+			return &q, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (f *fakeQueues) CountAhead(context.Context, *screening.ScreeningQueue) (int64, error) {
+	return 0, nil
+}
+
+type fakeExams struct {
+	examrepo.PhysicalExaminationRepository
+	rows []physicalexam.PhysicalExamination
+}
+
+func (f *fakeExams) FindByPatientID(_ context.Context, patientID uuid.UUID) ([]physicalexam.PhysicalExamination, error) {
+	var result []physicalexam.PhysicalExamination
+	for _, e := range f.rows {
+		if e.PatientID == patientID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+type fakeConsultations struct {
+	consultationrepo.ConsultationRepository
+	rows []consultation.Consultation
+}
+
+func (f *fakeConsultations) FindByPatientID(_ context.Context, patientID uuid.UUID) ([]consultation.Consultation, error) {
+	var result []consultation.Consultation
+	for _, c := range f.rows {
+		if c.PatientID == patientID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+type fakeCertificates struct {
+	certrepo.CertificateRepository
+	rows []certificate.Certificate
+}
+
+func (f *fakeCertificates) FindByPatientID(_ context.Context, patientID uuid.UUID) ([]certificate.Certificate, error) {
+	var result []certificate.Certificate
+	for _, c := range f.rows {
+		if c.PatientID == patientID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// fakeTimeline mengembalikan event milik patientID yang diminta.
+type fakeTimeline struct {
+	timelineusecase.TimelineUsecase
+	events map[string][]timeline.Event
+}
+
+func (f *fakeTimeline) FindByPatientID(_ context.Context, patientID string, _ []string, _, _ int) ([]timeline.Event, int64, error) {
+	events := f.events[patientID]
+	return events, int64(len(events)), nil
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) Record(context.Context, string, string, string, interface{}, interface{}) {}
+
+// climber adalah satu pasien beserta seluruh datanya di fake repository.
+type climber struct {
+	userID  *uuid.UUID
+	patient roles.Patient
+}
+
+type fixture struct {
+	usecase  PortalUsecase
+	patients *fakePatients
+	answers  *fakeAnswers
+	queues   *fakeQueues
+	// owner memetakan ID setiap baris ke ID pasien pemiliknya
+	owner map[uuid.UUID]uuid.UUID
+}
+
+func newFixture(index nikIndex, climbers ...climber) *fixture {
+	f := &fixture{
+		patients: &fakePatients{},
+		answers:  &fakeAnswers{index: index},
+		queues:   &fakeQueues{index: index},
+		owner:    map[uuid.UUID]uuid.UUID{},
+	}
+	exams := &fakeExams{}
+	consultations := &fakeConsultations{}
+	certificates := &fakeCertificates{}
+	tl := &fakeTimeline{events: map[string][]timeline.Event{}}
+	for _, c := range climbers {
+		p := c.patient
+		p.UserID = c.userID
+		f.patients.rows = append(f.patients.rows, p)
+		info := screening.PatientInfo{NIK: p.NIK, FullName: p.FullName}
+		for i := 0; i < 2; i++ {
+			a := screening.ScreeningAnswer{ID: uuid.New(), PatientInfo: info}
+			f.answers.rows = append(f.answers.rows, a)
+			f.owner[a.ID] = p.ID
+			e := physicalexam.PhysicalExamination{ID: uuid.New(), PatientID: p.ID}
+			exams.rows = append(exams.rows, e)
+			f.owner[e.ID] = p.ID
+			cs := consultation.Consultation{ID: uuid.New(), PatientID: p.ID}
+			consultations.rows = append(consultations.rows, cs)
+			f.owner[cs.ID] = p.ID
+			ct := certificate.Certificate{ID: uuid.New(), PatientID: p.ID}
+			certificates.rows = append(certificates.rows, ct)
+			f.owner[ct.ID] = p.ID
+			ev := timeline.Event{ID: uuid.New(), Type: timeline.Types[0]}
+			tl.events[p.ID.String()] = append(tl.events[p.ID.String()], ev)
+			f.owner[ev.ID] = p.ID
+		}
+		q := screening.ScreeningQueue{ID: uuid.New(), PatientInfo: info, Status: screening.QueueWaiting}
+		f.queues.rows = append(f.queues.rows, q)
+		f.owner[q.ID] = p.ID
+	}
+	f.usecase = NewPortalUsecase(f.patients, f.answers, f.queues, exams, consultations, certificates, tl, nopRecorder{})
+	return f
+}
+
+func newClimber(nik, name string, linked bool) climber {
+	c := climber{patient: roles.Patient{ID: uuid.New(), NIK: nik, FullName: name}}
+	if linked {
+		id := uuid.New()
+		c.userID = &id
+	}
+	return c
+}
+
+// readAll memanggil setiap endpoint portal dan mengembalikan ID baris yang
+// dikembalikan. ErrPatientNotLinked dan ErrNotInQueue bukan kegagalan.
+func readAll(t *testing.T, u PortalUsecase, userID string) []uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	var ids []uuid.UUID
+	check := func(name string, err error) bool {
+		if err == nil {
+			return true
+		}
+		if errors.Is(err, ErrPatientNotLinked) || errors.Is(err, ErrNotInQueue) {
+			return false
+		}
+		t.Fatalf("%s: %v", name, err)
+		return false
+	}
+	if p, err := u.Profile(ctx, userID); check("Profile", err) {
+		ids = append(ids, p.ID)
+	}
+	phone := "081234567890"
+	if p, err := u.UpdateProfile(ctx, userID, ProfileInput{Phone: &phone}); check("UpdateProfile", err) {
+		ids = append(ids, p.ID)
+	}
+	if rows, err := u.Screenings(ctx, userID); check("Screenings", err) {
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	if rows, err := u.Examinations(ctx, userID); check("Examinations", err) {
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	if rows, err := u.Consultations(ctx, userID); check("Consultations", err) {
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	if rows, err := u.Certificates(ctx, userID); check("Certificates", err) {
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	if rows, _, err := u.Timeline(ctx, userID, nil, 1, 20); check("Timeline", err) {
+		for _, r := range rows {
+			ids = append(ids, r.ID)
+		}
+	}
+	if q, err := u.QueuePosition(ctx, userID); check("QueuePosition", err) {
+		ids = append(ids, q.Queue.ID)
+	}
+	return ids
+}
+
+// assertOwnedBy memastikan semua baris milik pasien want, dan tidak ada satu
+// pun milik pasien lain.
+func (f *fixture) assertOwnedBy(t *testing.T, ids []uuid.UUID, want uuid.UUID) {
+	t.Helper()
+	for _, id := range ids {
+		if id == want {
+			continue // profil pasien itu sendiri
+		}
+		owner, ok := f.owner[id]
+		if !ok {
+			t.Errorf("unknown row %s returned", id)
+			continue
+		}
+		if owner != want {
+			t.Errorf("row %s belongs to patient %s, returned to patient %s", id, owner, want)
+		}
+	}
+	for _, id := range f.patients.updated {
+		if id != want {
+			t.Errorf("profile of patient %s updated by patient %s", id, want)
+		}
+	}
+}
+
+func TestPortalReturnsOnlyOwnData(t *testing.T) {
+	a := newClimber("3201010101900001", "Ayu", true)
+	b := newClimber("3201010101900002", "Budi", true)
+	f := newFixture(exactIndex, a, b)
+
+	for _, c := range []climber{a, b} {
+		f.patients.updated = nil
+		ids := readAll(t, f.usecase, c.userID.String())
+		f.assertOwnedBy(t, ids, c.patient.ID)
+		// profil, update profil, 2 screening, 2 exam, 2 konsultasi, 2 sertifikat, 2 timeline, antrean
+		if len(ids) != 13 {
+			t.Errorf("patient %s: got %d rows, want 13", c.patient.FullName, len(ids))
+		}
+	}
+}
+
+func TestPortalIgnoresBlindIndexCollision(t *testing.T) {
+	a := newClimber("3201010101900001", "Ayu", true)
+	b := newClimber("3201010101900002", "Budi", true)
+	// Semua NIK mendapat blind index yang sama
+	f := newFixture(func(string) string { return "collision" }, b, a)
+
+	ids := readAll(t, f.usecase, a.userID.String())
+	f.assertOwnedBy(t, ids, a.patient.ID)
+
+	// Antrean aktif B ditemukan lebih dulu oleh repository, tapi bukan milik A
+	if _, err := f.usecase.QueuePosition(context.Background(), a.userID.String()); !errors.Is(err, ErrNotInQueue) {
+		t.Errorf("QueuePosition: got %v, want ErrNotInQueue", err)
+	}
+}
+
+func TestPortalWithoutLinkedPatient(t *testing.T) {
+	// B pasien walk-in tanpa akun. A mendaftar mandiri dengan NIK B; profilnya
+	// dibuat tanpa NIK sampai staf menggabungkan keduanya.
+	b := newClimber("3201010101900002", "Budi", false)
+	a := newClimber("", "Budi", true)
+	f := newFixture(exactIndex, a, b)
+
+	ids := readAll(t, f.usecase, a.userID.String())
+	f.assertOwnedBy(t, ids, a.patient.ID)
+
+	f.patients.updated = nil
+	for _, userID := range []string{"", "not-a-uuid", uuid.Nil.String(), uuid.NewString()} {
+		if ids := readAll(t, f.usecase, userID); len(ids) != 0 {
+			t.Errorf("user %q without patient profile got %d rows", userID, len(ids))
+		}
+	}
+	if len(f.patients.updated) != 0 {
+		t.Errorf("profile updated without linked patient: %v", f.patients.updated)
+	}
+}
@@ -0,0 +1,186 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/privacy"
+	"v2/internal/domain/roles"
+)
+
+// accessLogPageSize adalah jumlah audit log yang diambil per query saat ekspor.
+const accessLogPageSize = 500
+
+// export menulis ZIP ke exportDir/<request id>.zip. File ditulis ke .tmp dulu
+// agar ZIP yang setengah jadi tidak pernah bisa diunduh.
+func (u *privacyUsecase) export(ctx context.Context, req *privacy.Request, patient *roles.Patient) (*privacy.Manifest, string, error) {
+	if err := os.MkdirAll(u.exportDir, 0o700); err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(u.exportDir, req.ID.String()+".zip")
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.Remove(tmp)
+
+	zw := zip.NewWriter(f)
+	manifest, err := u.writeExport(ctx, zw, req, patient)
+	if err == nil {
+		err = writeJSON(zw, "manifest.json", manifest)
+	}
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, "", err
+	}
+	return manifest, path, nil
+}
+
+func (u *privacyUsecase) writeExport(ctx context.Context, zw *zip.Writer, req *privacy.Request, patient *roles.Patient) (*privacy.Manifest, error) {
+	m := &privacy.Manifest{RequestID: req.ID, PatientID: patient.ID, GeneratedAt: time.Now()}
+	add := func(name string, v interface{}) error {
+		if err := writeJSON(zw, name, v); err != nil {
+			return err
+		}
+		m.Files = append(m.Files, name)
+		return nil
+	}
+
+	if err := add("patient.json", patient); err != nil {
+		return nil, err
+	}
+	mr, err := u.mrRepo.FindByPatientID(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mr != nil {
+		if err := add("medical_record.json", mr); err != nil {
+			return nil, err
+		}
+	}
+	exams, err := u.examRepo.FindByPatientID(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := add("physical_examinations.json", exams); err != nil {
+		return nil, err
+	}
+	consultations, err := u.consultationRepo.FindByPatientID(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range consultations {
+		if consultations[i].DiagnosisCodes, err = u.consultationRepo.FindDiagnoses(ctx, consultations[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := add("consultations.json", consultations); err != nil {
+		return nil, err
+	}
+	if patient.NIK != "" {
+		answers, err := u.answerRepo.FindByNIK(ctx, patient.NIK)
+		if err != nil {
+			return nil, err
+		}
+		if err := add("screening_answers.json", answers); err != nil {
+			return nil, err
+		}
+	}
+	merges, err := u.duplicateRepo.FindMerges(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := add("merges.json", merges); err != nil {
+		return nil, err
+	}
+	accessLog, err := u.accessLog(ctx, patient.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := add("access_log.json", accessLog); err != nil {
+		return nil, err
+	}
+
+	certs, err := u.certRepo.FindByPatientID(ctx, patient.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := add("certificates.json", certs); err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		pdf, _, err := u.certUsecase.RenderPDF(ctx, cert.CertificateNumber, "", "admin")
+		if err != nil {
+			return nil, fmt.Errorf("certificate %s: %w", cert.CertificateNumber, err)
+		}
+		name := "certificates/" + cert.CertificateNumber + ".pdf"
+		if err := writeFile(zw, name, pdf); err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, name)
+	}
+
+	for i, p := range patient.KTPImages {
+		data, err := os.ReadFile(filepath.Clean(p))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				m.Missing = append(m.Missing, p)
+				continue
+			}
+			return nil, err
+		}
+		name := fmt.Sprintf("ktp/%d_%s", i+1, filepath.Base(p))
+		if err := writeFile(zw, name, data); err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, name)
+	}
+	return m, nil
+}
+
+// accessLog mengambil seluruh catatan akses dan perubahan data pasien.
+func (u *privacyUsecase) accessLog(ctx context.Context, patientID string) ([]audit.Entry, error) {
+	var result []audit.Entry
+	for page := 1; ; page++ {
+		entries, total, err := u.audit.FindPaginated(ctx, audit.Filter{Entity: audit.EntityPatient, EntityID: patientID, Page: page, Limit: accessLogPageSize})
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries...)
+		if len(entries) == 0 || int64(len(result)) >= total {
+			return result, nil
+		}
+	}
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(zw, name, data)
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
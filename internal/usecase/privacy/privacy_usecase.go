@@ -0,0 +1,283 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"v2/internal/domain/audit"
+	"v2/internal/domain/privacy"
+	"v2/internal/domain/roles"
+	"v2/internal/logging"
+	certrepo "v2/internal/repository/certificate"
+	consultationrepo "v2/internal/repository/consultation"
+	duplicaterepo "v2/internal/repository/duplicate"
+	mrrepo "v2/internal/repository/medicalrecord"
+	examrepo "v2/internal/repository/physicalexam"
+	repo "v2/internal/repository/privacy"
+	rolesrepo "v2/internal/repository/roles"
+	screeningrepo "v2/internal/repository/screening"
+	"v2/internal/tracing"
+	auditusecase "v2/internal/usecase/audit"
+	certusecase "v2/internal/usecase/certificate"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrPatientNotFound = errors.New("patient not found")
+	ErrNotErasable     = errors.New("patient not found, merged into another patient, or already erased")
+	ErrRequestNotFound = errors.New("privacy request not found")
+	ErrReasonRequired  = errors.New("reason is required")
+	ErrExportNotReady  = errors.New("export is not completed")
+)
+
+// exportTimeout membatasi satu job ekspor yang berjalan di background.
+const exportTimeout = 10 * time.Minute
+
+type PrivacyUsecase interface {
+	// RequestExport membuat permintaan ekspor dan langsung mengembalikannya;
+	// ZIP dibuat di background dan statusnya dipantau lewat FindByID.
+	RequestExport(ctx context.Context, patientID, actorID, reason string) (*privacy.Request, error)
+	// Erase menganonimkan pasien. Tidak bisa dibatalkan.
+	Erase(ctx context.Context, patientID, actorID, reason string) (*privacy.Request, error)
+	FindByID(ctx context.Context, id string) (*privacy.Request, error)
+	FindPaginated(ctx context.Context, patientID, reqType, status string, page, limit int) ([]privacy.Request, int64, error)
+	// ExportFile mengembalikan path ZIP ekspor yang sudah selesai.
+	ExportFile(ctx context.Context, id string) (string, *privacy.Request, error)
+	// RecoverInterrupted dipanggil saat server start untuk menandai job yang
+	// terputus sebagai gagal.
+	RecoverInterrupted(ctx context.Context) error
+	// Wait menunggu job ekspor yang sedang berjalan selesai saat shutdown.
+	// Job yang belum selesai ketika ctx habis akan ditandai gagal oleh
+	// RecoverInterrupted saat server start berikutnya.
+	Wait(ctx context.Context) error
+}
+
+type privacyUsecase struct {
+	repo             repo.PrivacyRepository
+	patientRepo      rolesrepo.PatientRepository
+	mrRepo           mrrepo.MedicalRecordRepository
+	examRepo         examrepo.PhysicalExaminationRepository
+	consultationRepo consultationrepo.ConsultationRepository
+	answerRepo       screeningrepo.AnswerRepository
+	certRepo         certrepo.CertificateRepository
+	certUsecase      certusecase.CertificateUsecase
+	duplicateRepo    duplicaterepo.DuplicateRepository
+	audit            auditusecase.AuditUsecase
+	exportDir        string
+	running          sync.WaitGroup
+}
+
+func NewPrivacyUsecase(r repo.PrivacyRepository, pr rolesrepo.PatientRepository, mr mrrepo.MedicalRecordRepository, er examrepo.PhysicalExaminationRepository, cr consultationrepo.ConsultationRepository, ar screeningrepo.AnswerRepository, certRepo certrepo.CertificateRepository, certUsecase certusecase.CertificateUsecase, dr duplicaterepo.DuplicateRepository, audit auditusecase.AuditUsecase, exportDir string) PrivacyUsecase {
+	return &privacyUsecase{repo: r, patientRepo: pr, mrRepo: mr, examRepo: er, consultationRepo: cr, answerRepo: ar, certRepo: certRepo, certUsecase: certUsecase, duplicateRepo: dr, audit: audit, exportDir: exportDir}
+}
+
+func (u *privacyUsecase) RequestExport(ctx context.Context, patientID, actorID, reason string) (*privacy.Request, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrReasonRequired
+	}
+	patient, err := u.patientRepo.FindByID(ctx, pid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPatientNotFound
+		}
+		return nil, err
+	}
+	req := &privacy.Request{
+		ID:          uuid.New(),
+		PatientID:   pid,
+		Type:        privacy.TypeExport,
+		Status:      privacy.StatusProcessing,
+		Reason:      strings.TrimSpace(reason),
+		RequestedBy: actor(actorID),
+		CreatedAt:   time.Now(),
+	}
+	if err := u.repo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionExport, audit.EntityPatient, patientID, nil, req)
+
+	// Context request Fiber tidak boleh dipakai setelah handler selesai;
+	// request ID dan trace dibawa agar log dan span job bisa dikaitkan
+	// dengan request-nya
+	job := *req
+	jobCtx := logging.WithRequestID(context.Background(), logging.RequestID(ctx))
+	jobCtx = trace.ContextWithSpanContext(jobCtx, tracing.SpanFromContext(ctx).SpanContext())
+	u.running.Add(1)
+	go u.runExport(jobCtx, &job, patient)
+	return req, nil
+}
+
+func (u *privacyUsecase) runExport(ctx context.Context, req *privacy.Request, patient *roles.Patient) {
+	defer u.running.Done()
+	ctx, span := tracing.Start(ctx, "PrivacyUsecase.Export")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	manifest, path, err := u.export(ctx, req, patient)
+	now := time.Now()
+	req.CompletedAt = &now
+	if err != nil {
+		slog.ErrorContext(ctx, "privacy export failed", "privacy_request_id", req.ID, "err", err)
+		req.Status = privacy.StatusFailed
+		req.Error = err.Error()
+	} else {
+		req.Status = privacy.StatusCompleted
+		req.FilePath = path
+		req.Report, _ = json.Marshal(manifest)
+	}
+	if err := u.repo.Finish(ctx, req); err != nil {
+		slog.ErrorContext(ctx, "privacy export: save result failed", "privacy_request_id", req.ID, "err", err)
+	}
+}
+
+func (u *privacyUsecase) Erase(ctx context.Context, patientID, actorID, reason string) (*privacy.Request, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, ErrNotErasable
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, ErrReasonRequired
+	}
+	report, files, err := u.repo.Erase(ctx, pid, actor(actorID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotErasable
+		}
+		return nil, err
+	}
+	// File dihapus setelah commit; file yang gagal dihapus tetap dilaporkan
+	for _, f := range files {
+		if err := os.Remove(filepath.Clean(f)); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.ErrorContext(ctx, "privacy erase: remove file failed", "patient_id", patientID, "file", f, "err", err)
+			}
+			report.FilesMissing = append(report.FilesMissing, f)
+			continue
+		}
+		report.FilesDeleted = append(report.FilesDeleted, f)
+	}
+	u.removeExports(ctx, pid)
+
+	now := time.Now()
+	req := &privacy.Request{
+		ID:          uuid.New(),
+		PatientID:   pid,
+		Type:        privacy.TypeErasure,
+		Status:      privacy.StatusCompleted,
+		Reason:      strings.TrimSpace(reason),
+		RequestedBy: actor(actorID),
+		CreatedAt:   now,
+		CompletedAt: &now,
+	}
+	req.Report, _ = json.Marshal(report)
+	if err := u.repo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionErase, audit.EntityPatient, patientID, nil, report)
+	return req, nil
+}
+
+// removeExports menghapus ZIP ekspor lama karena berisi data yang baru dihapus.
+func (u *privacyUsecase) removeExports(ctx context.Context, patientID uuid.UUID) {
+	exports, _, err := u.repo.FindPaginated(ctx, &patientID, privacy.TypeExport, privacy.StatusCompleted, 1, 1000)
+	if err != nil {
+		slog.ErrorContext(ctx, "privacy erase: find exports failed", "patient_id", patientID, "err", err)
+		return
+	}
+	for _, e := range exports {
+		if e.FilePath == "" {
+			continue
+		}
+		if err := os.Remove(e.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.ErrorContext(ctx, "privacy erase: remove export failed", "patient_id", patientID, "file", e.FilePath, "err", err)
+		}
+	}
+}
+
+func (u *privacyUsecase) FindByID(ctx context.Context, id string) (*privacy.Request, error) {
+	rid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrRequestNotFound
+	}
+	req, err := u.repo.FindByID(ctx, rid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+	return req, nil
+}
+
+func (u *privacyUsecase) FindPaginated(ctx context.Context, patientID, reqType, status string, page, limit int) ([]privacy.Request, int64, error) {
+	var pid *uuid.UUID
+	if patientID != "" {
+		id, err := uuid.Parse(patientID)
+		if err != nil {
+			return nil, 0, ErrPatientNotFound
+		}
+		pid = &id
+	}
+	return u.repo.FindPaginated(ctx, pid, reqType, status, page, limit)
+}
+
+func (u *privacyUsecase) ExportFile(ctx context.Context, id string) (string, *privacy.Request, error) {
+	req, err := u.FindByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if req.Type != privacy.TypeExport || req.Status != privacy.StatusCompleted || req.FilePath == "" {
+		return "", nil, ErrExportNotReady
+	}
+	if _, err := os.Stat(req.FilePath); err != nil {
+		return "", nil, ErrExportNotReady
+	}
+	u.audit.Record(ctx, audit.ActionRead, audit.EntityPrivacyRequest, id, nil, nil)
+	return req.FilePath, req, nil
+}
+
+func (u *privacyUsecase) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		u.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (u *privacyUsecase) RecoverInterrupted(ctx context.Context) error {
+	n, err := u.repo.FailInterrupted(ctx, "interrupted by server restart")
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.WarnContext(ctx, "privacy: interrupted requests marked as failed", "count", n)
+	}
+	return nil
+}
+
+func actor(userID string) *uuid.UUID {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,76 @@
+package roles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"v2/internal/domain/roles"
+	"v2/internal/nik"
+)
+
+// ErrNIKMismatch dikembalikan jika tanggal lahir atau jenis kelamin yang diisi
+// tidak sesuai dengan NIK.
+var ErrNIKMismatch = errors.New("patient data does not match NIK")
+
+// NIKPolicy menentukan tindakan saat data pasien tidak sesuai dengan NIK.
+type NIKPolicy string
+
+const (
+	NIKReject NIKPolicy = "reject" // tolak data
+	NIKWarn   NIKPolicy = "warn"   // simpan, catat peringatan di log
+)
+
+// ApplyNIK memvalidasi NIK pasien, melengkapi tanggal lahir dan jenis kelamin
+// yang kosong dari NIK, dan menghitung Age dari tanggal lahir. Age dari klien
+// selalu diabaikan. NIK yang strukturnya tidak valid selalu ditolak.
+func ApplyNIK(ctx context.Context, p *roles.Patient, policy NIKPolicy, now time.Time) error {
+	p.Age = 0
+	var info *nik.Info
+	if p.NIK != "" {
+		var err error
+		if info, err = nik.Parse(p.NIK, now); err != nil {
+			return err
+		}
+	}
+
+	var mismatches []string
+	if gender := nik.NormalizeGender(p.Gender); gender != "" {
+		p.Gender = gender
+	}
+	if info != nil {
+		if p.Gender == "" {
+			p.Gender = info.Gender
+		} else if p.Gender != info.Gender {
+			mismatches = append(mismatches, fmt.Sprintf("gender %s, NIK says %s", p.Gender, info.Gender))
+		}
+	}
+
+	var birth time.Time
+	if p.BirthDate != "" {
+		var err error
+		if birth, err = nik.ParseDate(p.BirthDate); err != nil {
+			return err
+		}
+		// NIK hanya memuat dua digit tahun
+		if info != nil && (birth.Day() != info.BirthDate.Day() || birth.Month() != info.BirthDate.Month() || birth.Year()%100 != info.BirthDate.Year()%100) {
+			mismatches = append(mismatches, fmt.Sprintf("birth date %s, NIK says %s", birth.Format("2006-01-02"), info.BirthDate.Format("2006-01-02")))
+		}
+	} else if info != nil {
+		birth = info.BirthDate
+		p.BirthDate = birth.Format("2006-01-02")
+	}
+	if !birth.IsZero() {
+		p.Age = nik.Age(birth, now)
+	}
+
+	if len(mismatches) > 0 {
+		err := fmt.Errorf("%w: %v", ErrNIKMismatch, mismatches)
+		if policy != NIKWarn {
+			return err
+		}
+		slog.WarnContext(ctx, "patient data does not match NIK", "patient_id", p.ID, "err", err)
+	}
+	return nil
+}
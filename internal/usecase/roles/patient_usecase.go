@@ -2,8 +2,20 @@ package roles
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"v2/internal/domain/audit"
 	"v2/internal/domain/roles"
+	"v2/internal/nik"
+	duplicaterepo "v2/internal/repository/duplicate"
 	repo "v2/internal/repository/roles"
+	auditusecase "v2/internal/usecase/audit"
+	duplicateusecase "v2/internal/usecase/duplicate"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type PatientUsecase interface {
@@ -11,18 +23,55 @@ type PatientUsecase interface {
 	FindByNIK(ctx context.Context, nik string) (*roles.Patient, error)
 	FindAll(ctx context.Context) ([]roles.Patient, error)
 	FindAllPaginated(ctx context.Context, page, limit int) ([]roles.Patient, int64, error)
+	Search(ctx context.Context, search roles.PatientSearch) ([]roles.PatientSearchResult, int64, error)
+	Delete(ctx context.Context, id, actorID string) error
+	Restore(ctx context.Context, id string) (*roles.Patient, error)
 }
 
+var (
+	ErrInvalidSearch   = errors.New("invalid patient search")
+	ErrPatientNotFound = errors.New("patient not found")
+	ErrPatientDeleted  = errors.New("patient with this NIK has been deleted; restore it first")
+)
+
 type patientUsecase struct {
-	repo repo.PatientRepository
+	repo          repo.PatientRepository
+	duplicateRepo duplicaterepo.DuplicateRepository
+	nikPolicy     NIKPolicy
+	audit         auditusecase.Recorder
 }
 
-func NewPatientUsecase(r repo.PatientRepository) PatientUsecase {
-	return &patientUsecase{repo: r}
+func NewPatientUsecase(r repo.PatientRepository, dr duplicaterepo.DuplicateRepository, nikPolicy NIKPolicy, audit auditusecase.Recorder) PatientUsecase {
+	return &patientUsecase{repo: r, duplicateRepo: dr, nikPolicy: nikPolicy, audit: audit}
 }
 
 func (u *patientUsecase) CreateOrUpdatePatient(ctx context.Context, patient *roles.Patient) (*roles.Patient, error) {
-	return u.repo.CreateOrUpdateByNIK(ctx, patient)
+	// Akun login hanya dihubungkan lewat registrasi pasien
+	patient.UserID = nil
+	if err := ApplyNIK(ctx, patient, u.nikPolicy, time.Now()); err != nil {
+		return nil, err
+	}
+	before, err := u.repo.FindByNIK(ctx, patient.NIK)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	saved, err := u.repo.CreateOrUpdateByNIK(ctx, patient)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPatientDeleted
+		}
+		return nil, err
+	}
+	action := audit.ActionUpdate
+	if before == nil {
+		action = audit.ActionCreate
+	}
+	u.audit.Record(ctx, action, audit.EntityPatient, saved.ID.String(), before, saved)
+	// Deteksi duplikat tidak boleh menggagalkan pendaftaran pasien
+	if _, err := u.duplicateRepo.Detect(ctx, &saved.ID, duplicateusecase.MinScore); err != nil {
+		slog.ErrorContext(ctx, "duplicate detection failed", "patient_id", saved.ID, "err", err)
+	}
+	return saved, nil
 }
 
 func (u *patientUsecase) FindByNIK(ctx context.Context, nik string) (*roles.Patient, error) {
@@ -36,3 +85,74 @@ func (u *patientUsecase) FindAll(ctx context.Context) ([]roles.Patient, error) {
 func (u *patientUsecase) FindAllPaginated(ctx context.Context, page, limit int) ([]roles.Patient, int64, error) {
 	return u.repo.FindAllPaginated(ctx, page, limit)
 }
+
+// Search menormalkan kriteria pencarian sebelum diteruskan ke repository.
+func (u *patientUsecase) Search(ctx context.Context, search roles.PatientSearch) ([]roles.PatientSearchResult, int64, error) {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Gender != "" {
+		search.Gender = nik.NormalizeGender(search.Gender)
+		if search.Gender == "" {
+			return nil, 0, ErrInvalidSearch
+		}
+	}
+	if search.AgeMin != nil && search.AgeMax != nil && *search.AgeMin > *search.AgeMax {
+		return nil, 0, ErrInvalidSearch
+	}
+	if search.RegisteredFrom != nil && search.RegisteredTo != nil && !search.RegisteredFrom.Before(*search.RegisteredTo) {
+		return nil, 0, ErrInvalidSearch
+	}
+	if search.Page < 1 || search.Limit < 1 {
+		return nil, 0, ErrInvalidSearch
+	}
+	return u.repo.Search(ctx, search)
+}
+
+// Delete melakukan soft delete; riwayat klinis pasien tetap tersimpan.
+func (u *patientUsecase) Delete(ctx context.Context, id, actorID string) error {
+	pid, err := uuid.Parse(id)
+	if err != nil {
+		return ErrPatientNotFound
+	}
+	before, err := u.repo.FindByID(ctx, pid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPatientNotFound
+		}
+		return err
+	}
+	if err := u.repo.SoftDelete(ctx, pid, actor(actorID)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPatientNotFound
+		}
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionDelete, audit.EntityPatient, id, before, nil)
+	return nil
+}
+
+func (u *patientUsecase) Restore(ctx context.Context, id string) (*roles.Patient, error) {
+	pid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrPatientNotFound
+	}
+	if err := u.repo.Restore(ctx, pid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPatientNotFound
+		}
+		return nil, err
+	}
+	p, err := u.repo.FindByID(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	u.audit.Record(ctx, audit.ActionRestore, audit.EntityPatient, id, nil, p)
+	return p, nil
+}
+
+func actor(userID string) *uuid.UUID {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
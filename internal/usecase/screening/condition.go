@@ -0,0 +1,156 @@
+package screening
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"v2/internal/domain/screening"
+
+	"github.com/google/uuid"
+)
+
+// isVisible mengevaluasi kondisi tampil sebuah pertanyaan terhadap jawaban
+// pertanyaan sebelumnya yang terlihat. Pertanyaan tanpa kondisi selalu tampil.
+func isVisible(q screening.ScreeningQuestion, answers map[uuid.UUID]interface{}) bool {
+	for _, cond := range q.Conditions {
+		if !matchCondition(cond, answers[cond.QuestionID]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCondition mencocokkan satu kondisi dengan jawaban. Untuk lt/gt,
+// jawaban yang bukan angka dianggap tidak cocok; karena itu aturan lt/gt
+// sebaiknya hanya dipasang pada pertanyaan bertipe number.
+func matchCondition(cond screening.DisplayCondition, answer interface{}) bool {
+	values := answerValues(answer)
+	switch cond.Operator {
+	case screening.ConditionEquals:
+		return len(values) == 1 && strings.EqualFold(values[0], cond.Value)
+	case screening.ConditionIncludes:
+		for _, v := range values {
+			if strings.EqualFold(v, cond.Value) {
+				return true
+			}
+		}
+		return false
+	case screening.ConditionAnyOf:
+		for _, v := range values {
+			for _, want := range cond.Values {
+				if strings.EqualFold(v, want) {
+					return true
+				}
+			}
+		}
+		return false
+	case screening.ConditionLessThan, screening.ConditionMoreThan:
+		want, err := strconv.ParseFloat(cond.Value, 64)
+		if err != nil || len(values) != 1 {
+			return false
+		}
+		got, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+		if err != nil {
+			return false
+		}
+		if cond.Operator == screening.ConditionLessThan {
+			return got < want
+		}
+		return got > want
+	}
+	return false
+}
+
+// answerValues menyeragamkan jawaban (string, angka, atau array checkbox)
+// menjadi daftar string.
+func answerValues(answer interface{}) []string {
+	switch v := answer.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			result = append(result, answerValues(item)...)
+		}
+		return result
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func isEmptyAnswer(answer interface{}) bool {
+	return len(answerValues(answer)) == 0
+}
+
+// isNumericAnswer memastikan jawaban pertanyaan number berupa satu angka.
+func isNumericAnswer(answer interface{}) bool {
+	values := answerValues(answer)
+	if len(values) != 1 {
+		return false
+	}
+	_, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+	return err == nil
+}
+
+func validateCondition(cond screening.DisplayCondition) error {
+	switch cond.Operator {
+	case screening.ConditionEquals, screening.ConditionIncludes:
+		if cond.Value == "" {
+			return fmt.Errorf("%w: value required for operator %s", ErrInvalidCondition, cond.Operator)
+		}
+	case screening.ConditionAnyOf:
+		if len(cond.Values) == 0 {
+			return fmt.Errorf("%w: values required for operator any_of", ErrInvalidCondition)
+		}
+	case screening.ConditionLessThan, screening.ConditionMoreThan:
+		if _, err := strconv.ParseFloat(cond.Value, 64); err != nil {
+			return fmt.Errorf("%w: numeric value required for operator %s", ErrInvalidCondition, cond.Operator)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidCondition, cond.Operator)
+	}
+	return nil
+}
+
+// validateConditions memastikan setiap kondisi merujuk ke pertanyaan yang ada
+// dan urutannya berada sebelum pertanyaan target. questions harus sudah
+// terurut seperti hasil QuestionRepository.FindAll.
+func validateConditions(target uuid.UUID, conditions []screening.DisplayCondition, questions []screening.ScreeningQuestion) error {
+	order := make(map[uuid.UUID]int, len(questions))
+	for i, q := range questions {
+		order[q.ID] = i
+	}
+	for _, cond := range conditions {
+		if err := validateCondition(cond); err != nil {
+			return err
+		}
+		source, ok := order[cond.QuestionID]
+		if !ok {
+			return fmt.Errorf("%w: question %s not found", ErrInvalidCondition, cond.QuestionID)
+		}
+		if source >= order[target] {
+			return fmt.Errorf("%w: question %s must come before the conditional question", ErrInvalidCondition, cond.QuestionID)
+		}
+	}
+	return nil
+}
+
+// sortQuestions mengurutkan pertanyaan seperti QuestionRepository.FindAll
+// (position, lalu id).
+func sortQuestions(questions []screening.ScreeningQuestion) {
+	sort.SliceStable(questions, func(i, j int) bool {
+		if questions[i].Position != questions[j].Position {
+			return questions[i].Position < questions[j].Position
+		}
+		return bytes.Compare(questions[i].ID[:], questions[j].ID[:]) < 0
+	})
+}
@@ -0,0 +1,27 @@
+package screening
+
+import (
+	"v2/internal/domain/screening"
+
+	"github.com/google/uuid"
+)
+
+// assessRisk menjalankan seluruh aturan skoring terhadap jawaban screening.
+// Tingkat risiko adalah level tertinggi dari aturan yang terpicu.
+func assessRisk(rules []screening.RiskRule, items []screening.AnswerItem) screening.RiskAssessment {
+	answers := make(map[uuid.UUID]interface{}, len(items))
+	for _, item := range items {
+		answers[item.QuestionID] = item.Answer
+	}
+	result := screening.RiskAssessment{Level: screening.RiskLow, Flags: []string{}}
+	for _, rule := range rules {
+		if !matchCondition(rule.Condition, answers[rule.Condition.QuestionID]) {
+			continue
+		}
+		result.Flags = append(result.Flags, rule.Flag)
+		if screening.RiskRank(rule.Level) > screening.RiskRank(result.Level) {
+			result.Level = rule.Level
+		}
+	}
+	return result
+}
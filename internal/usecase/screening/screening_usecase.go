@@ -2,16 +2,36 @@ package screening
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
 	"time"
+	"v2/internal/domain/audit"
 	rolesdomain "v2/internal/domain/roles"
 	"v2/internal/domain/screening"
+	"v2/internal/mailer"
+	"v2/internal/metrics"
 	userrepo "v2/internal/repository"
 	rolesrepo "v2/internal/repository/roles"
 	repo "v2/internal/repository/screening"
+	"v2/internal/tracing"
+	auditusecase "v2/internal/usecase/audit"
 	"v2/internal/utils"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrRequiredAnswer   = errors.New("answer required")
+	ErrUnknownQuestion  = errors.New("unknown question")
+	ErrInvalidAnswer    = errors.New("invalid answer")
+	ErrInvalidCondition = errors.New("invalid display condition")
+	ErrInvalidRiskRule  = errors.New("invalid risk rule")
+	ErrRiskRuleNotFound = errors.New("risk rule not found")
+	ErrQuestionInUse    = errors.New("question is used by another question's display condition")
+	ErrInvalidPosition  = errors.New("position must be an integer")
 )
 
 type ScreeningUsecase interface {
@@ -21,24 +41,37 @@ type ScreeningUsecase interface {
 	UpdateScreeningAnswer(ctx context.Context, id string, update map[string]interface{}) error
 	CreateQuestion(ctx context.Context, question *screening.ScreeningQuestion) error
 	UpdateQuestion(ctx context.Context, id string, update map[string]interface{}) error
+	UpdateQuestionConditions(ctx context.Context, id string, conditions []screening.DisplayCondition) error
+	DeleteQuestion(ctx context.Context, id, actorID string) error
+	RestoreQuestion(ctx context.Context, id string) error
 	FindQueuePaginatedByStatus(ctx context.Context, status string, page, limit int) ([]screening.ScreeningQueue, int64, error)
+	ListRiskRules(ctx context.Context) ([]screening.RiskRule, error)
+	CreateRiskRule(ctx context.Context, rule *screening.RiskRule) error
+	UpdateRiskRule(ctx context.Context, id string, rule *screening.RiskRule) error
+	DeleteRiskRule(ctx context.Context, id string) error
 }
 
 type screeningUsecase struct {
 	questionRepo repo.QuestionRepository
 	answerRepo   repo.AnswerRepository
 	queueRepo    repo.QueueRepository
+	riskRuleRepo repo.RiskRuleRepository
 	patientRepo  rolesrepo.PatientRepository
 	userRepo     userrepo.UserRepository
+	audit        auditusecase.Recorder
+	mailer       mailer.Mailer
 }
 
-func NewScreeningUsecase(qr repo.QuestionRepository, ar repo.AnswerRepository, qrq repo.QueueRepository, pr rolesrepo.PatientRepository, ur userrepo.UserRepository) ScreeningUsecase {
+func NewScreeningUsecase(qr repo.QuestionRepository, ar repo.AnswerRepository, qrq repo.QueueRepository, rr repo.RiskRuleRepository, pr rolesrepo.PatientRepository, ur userrepo.UserRepository, audit auditusecase.Recorder, m mailer.Mailer) ScreeningUsecase {
 	return &screeningUsecase{
 		questionRepo: qr,
 		answerRepo:   ar,
 		queueRepo:    qrq,
+		riskRuleRepo: rr,
 		patientRepo:  pr,
 		userRepo:     ur,
+		audit:        audit,
+		mailer:       m,
 	}
 }
 
@@ -47,14 +80,82 @@ func (u *screeningUsecase) GetQuestions(ctx context.Context) ([]screening.Screen
 }
 
 func (u *screeningUsecase) SubmitAnswer(ctx context.Context, answer *screening.ScreeningAnswer) error {
+	ctx, span := tracing.Start(ctx, "ScreeningUsecase.SubmitAnswer")
+	defer span.End()
+	questions, err := u.questionRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	items, err := applyDisplayConditions(questions, answer.Answers)
+	if err != nil {
+		return err
+	}
+	answer.Answers = items
+	rules, err := u.riskRuleRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	risk := assessRisk(rules, answer.Answers)
+	answer.RiskLevel = risk.Level
+	answer.RiskFlags = risk.Flags
 	answer.CreatedAt = time.Now()
-	return u.answerRepo.Create(ctx, answer)
+	if err := u.answerRepo.Create(ctx, answer); err != nil {
+		return err
+	}
+	u.audit.Record(ctx, audit.ActionCreate, audit.EntityScreeningAnswer, answer.ID.String(), nil, answer)
+	metrics.ScreeningSubmitted(answer.RiskLevel)
+	return nil
+}
+
+// applyDisplayConditions menelusuri pertanyaan sesuai urutan, membuang jawaban
+// untuk pertanyaan yang tersembunyi dan memastikan pertanyaan wajib yang
+// tampil sudah dijawab.
+func applyDisplayConditions(questions []screening.ScreeningQuestion, items []screening.AnswerItem) ([]screening.AnswerItem, error) {
+	submitted := make(map[uuid.UUID]interface{}, len(items))
+	for _, item := range items {
+		submitted[item.QuestionID] = item.Answer
+	}
+	known := make(map[uuid.UUID]bool, len(questions))
+	for _, q := range questions {
+		known[q.ID] = true
+	}
+	for id := range submitted {
+		if !known[id] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownQuestion, id)
+		}
+	}
+
+	visible := make(map[uuid.UUID]interface{}, len(questions))
+	var result []screening.AnswerItem
+	for _, q := range questions {
+		if !isVisible(q, visible) {
+			continue
+		}
+		ans, ok := submitted[q.ID]
+		if q.Required && (!ok || isEmptyAnswer(ans)) {
+			return nil, fmt.Errorf("%w: %s", ErrRequiredAnswer, q.Label)
+		}
+		if !ok {
+			continue
+		}
+		if q.Type == screening.QuestionNumber && !isEmptyAnswer(ans) && !isNumericAnswer(ans) {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidAnswer, q.Label)
+		}
+		visible[q.ID] = ans
+		result = append(result, screening.AnswerItem{QuestionID: q.ID, Answer: ans})
+	}
+	return result, nil
 }
 
 func (u *screeningUsecase) EnqueueScreening(ctx context.Context, queue *screening.ScreeningQueue) error {
 	if queue.ScreeningAnswerID == uuid.Nil {
 		return errors.New("screening_answer_id required")
 	}
+	// Status dan waktu masuk tidak diambil dari client agar urutan antrean
+	// sama bagi staf dan pasien
+	now := time.Now()
+	queue.Status = screening.QueueWaiting
+	queue.CreatedAt, queue.UpdatedAt = now, now
 	return u.queueRepo.Create(ctx, queue)
 }
 
@@ -63,31 +164,249 @@ func (u *screeningUsecase) UpdateScreeningAnswer(ctx context.Context, id string,
 	if err != nil {
 		return err
 	}
-	return u.answerRepo.Update(ctx, uid, update)
+	// Skoring risiko dihitung ulang dari jawaban yang diperbarui
+	data, _ := json.Marshal(update["answers"])
+	var items []screening.AnswerItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	// Aturan yang sama dengan SubmitAnswer: jawaban pertanyaan tersembunyi
+	// dibuang dan pertanyaan wajib yang tampil harus dijawab
+	questions, err := u.questionRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	if items, err = applyDisplayConditions(questions, items); err != nil {
+		return err
+	}
+	rules, err := u.riskRuleRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	before, err := u.answerRepo.FindByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	risk := assessRisk(rules, items)
+	err = u.answerRepo.Update(ctx, uid, map[string]interface{}{
+		"answers":    items,
+		"risk_level": risk.Level,
+		"risk_flags": risk.Flags,
+	})
+	if err != nil {
+		return err
+	}
+	after := *before
+	after.Answers, after.RiskLevel, after.RiskFlags = items, risk.Level, risk.Flags
+	u.audit.Record(ctx, audit.ActionUpdate, audit.EntityScreeningAnswer, id, before, after)
+	return nil
 }
 
 func (u *screeningUsecase) CreateQuestion(ctx context.Context, question *screening.ScreeningQuestion) error {
+	if len(question.Conditions) > 0 {
+		questions, err := u.questionRepo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		if question.ID == uuid.Nil {
+			question.ID = uuid.New()
+		}
+		questions = append(questions, *question)
+		sortQuestions(questions)
+		if err := validateConditions(question.ID, question.Conditions, questions); err != nil {
+			return err
+		}
+	}
 	return u.questionRepo.Create(ctx, question)
 }
 
+// UpdateQuestion memperbarui label, type, options dan position. Jika position
+// berubah, urutan kondisi tampil semua pertanyaan divalidasi ulang karena
+// pertanyaan sumber harus tetap berada sebelum pertanyaan yang bergantung
+// padanya.
 func (u *screeningUsecase) UpdateQuestion(ctx context.Context, id string, update map[string]interface{}) error {
 	uid, err := uuid.Parse(id)
 	if err != nil {
 		return err
 	}
+	if raw, ok := update["position"]; ok && raw != nil {
+		position, ok := raw.(float64)
+		if !ok || position != float64(int(position)) {
+			return ErrInvalidPosition
+		}
+		update["position"] = int(position)
+		questions, err := u.questionRepo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		found := false
+		for i := range questions {
+			if questions[i].ID == uid {
+				questions[i].Position = int(position)
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrUnknownQuestion, uid)
+		}
+		sortQuestions(questions)
+		for _, q := range questions {
+			if err := validateConditions(q.ID, q.Conditions, questions); err != nil {
+				return err
+			}
+		}
+	}
 	return u.questionRepo.Update(ctx, uid, update)
 }
 
+func (u *screeningUsecase) UpdateQuestionConditions(ctx context.Context, id string, conditions []screening.DisplayCondition) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+	questions, err := u.questionRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, q := range questions {
+		if q.ID == uid {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUnknownQuestion, uid)
+	}
+	if err := validateConditions(uid, conditions, questions); err != nil {
+		return err
+	}
+	return u.questionRepo.UpdateConditions(ctx, uid, conditions)
+}
+
+// DeleteQuestion melakukan soft delete. Pertanyaan tidak lagi ditampilkan,
+// tetapi jawaban lama tetap menampilkan labelnya.
+func (u *screeningUsecase) DeleteQuestion(ctx context.Context, id, actorID string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownQuestion, id)
+	}
+	questions, err := u.questionRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, q := range questions {
+		for _, cond := range q.Conditions {
+			if cond.QuestionID == uid && q.ID != uid {
+				return fmt.Errorf("%w: %s", ErrQuestionInUse, q.Label)
+			}
+		}
+	}
+	var deletedBy *uuid.UUID
+	if by, err := uuid.Parse(actorID); err == nil {
+		deletedBy = &by
+	}
+	if err := u.questionRepo.SoftDelete(ctx, uid, deletedBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrUnknownQuestion, uid)
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *screeningUsecase) RestoreQuestion(ctx context.Context, id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownQuestion, id)
+	}
+	if err := u.questionRepo.Restore(ctx, uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrUnknownQuestion, uid)
+		}
+		return err
+	}
+	return nil
+}
+
 func (u *screeningUsecase) FindQueuePaginatedByStatus(ctx context.Context, status string, page, limit int) ([]screening.ScreeningQueue, int64, error) {
 	return u.queueRepo.FindPaginatedByStatus(ctx, status, page, limit)
 }
 
+func (u *screeningUsecase) ListRiskRules(ctx context.Context) ([]screening.RiskRule, error) {
+	return u.riskRuleRepo.FindAll(ctx)
+}
+
+func (u *screeningUsecase) CreateRiskRule(ctx context.Context, rule *screening.RiskRule) error {
+	if err := u.validateRiskRule(ctx, rule); err != nil {
+		return err
+	}
+	return u.riskRuleRepo.Create(ctx, rule)
+}
+
+func (u *screeningUsecase) UpdateRiskRule(ctx context.Context, id string, rule *screening.RiskRule) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+	if err := u.validateRiskRule(ctx, rule); err != nil {
+		return err
+	}
+	rule.ID = uid
+	if err := u.riskRuleRepo.Update(ctx, rule); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRiskRuleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *screeningUsecase) DeleteRiskRule(ctx context.Context, id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+	if err := u.riskRuleRepo.Delete(ctx, uid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRiskRuleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (u *screeningUsecase) validateRiskRule(ctx context.Context, rule *screening.RiskRule) error {
+	if rule.Flag == "" {
+		return fmt.Errorf("%w: flag required", ErrInvalidRiskRule)
+	}
+	if rule.Level != screening.RiskMedium && rule.Level != screening.RiskHigh {
+		return fmt.Errorf("%w: level must be medium or high", ErrInvalidRiskRule)
+	}
+	if err := validateCondition(rule.Condition); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRiskRule, err)
+	}
+	q, err := u.questionRepo.FindByID(ctx, rule.Condition.QuestionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: question %s not found", ErrInvalidRiskRule, rule.Condition.QuestionID)
+		}
+		return err
+	}
+	if q.DeletedAt != nil {
+		return fmt.Errorf("%w: question %s has been deleted", ErrInvalidRiskRule, rule.Condition.QuestionID)
+	}
+	return nil
+}
+
 type ScreeningWithPatientInput struct {
 	Patient   *rolesdomain.Patient
 	Screening screening.ScreeningAnswer
 }
 
 func (u *screeningUsecase) ScreeningWithPatient(ctx context.Context, input ScreeningWithPatientInput) error {
+	ctx, span := tracing.Start(ctx, "ScreeningUsecase.ScreeningWithPatient")
+	defer span.End()
 	// 1. Cek pasien by NIK/email
 	pasien, _ := u.patientRepo.FindByNIK(ctx, input.Patient.NIK)
 	if pasien == nil {
@@ -104,14 +423,16 @@ func (u *screeningUsecase) ScreeningWithPatient(ctx context.Context, input Scree
 		if ur, ok := u.userRepo.(userrepo.UserRepository); ok {
 			ur.Create(ctx, user)
 		}
-		// Kirim email kredensial (log)
+		// Kirim email kredensial
 		subject := "Akun Klinik Anda"
 		body := "Email: " + input.Patient.Email + "\nPassword: " + password
-		utils.SendEmail(input.Patient.Email, subject, body)
+		if err := u.mailer.Send(ctx, input.Patient.Email, subject, body); err != nil {
+			slog.ErrorContext(ctx, "screening: send account email failed", "err", err)
+		}
 		// Buat pasien
 		u.patientRepo.Create(ctx, input.Patient)
 	}
 	// 2. Simpan screening (panggil repo screening answer)
-	// 3. Masukkan ke antrian screening_pending (panggil repo queue)
+	// 3. Masukkan ke antrian waiting (panggil repo queue)
 	return nil
 }
@@ -0,0 +1,59 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"v2/internal/domain/timeline"
+	patientrepo "v2/internal/repository/roles"
+	repo "v2/internal/repository/timeline"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrPatientNotFound = errors.New("patient not found")
+	ErrUnknownType     = errors.New("unknown timeline event type")
+)
+
+type TimelineUsecase interface {
+	FindByPatientID(ctx context.Context, patientID string, types []string, page, limit int) ([]timeline.Event, int64, error)
+}
+
+type timelineUsecase struct {
+	repo        repo.TimelineRepository
+	patientRepo patientrepo.PatientRepository
+}
+
+func NewTimelineUsecase(r repo.TimelineRepository, pr patientrepo.PatientRepository) TimelineUsecase {
+	return &timelineUsecase{repo: r, patientRepo: pr}
+}
+
+func (u *timelineUsecase) FindByPatientID(ctx context.Context, patientID string, types []string, page, limit int) ([]timeline.Event, int64, error) {
+	pid, err := uuid.Parse(patientID)
+	if err != nil {
+		return nil, 0, ErrPatientNotFound
+	}
+	for _, t := range types {
+		if !knownType(t) {
+			return nil, 0, fmt.Errorf("%w: %s", ErrUnknownType, t)
+		}
+	}
+	if _, err := u.patientRepo.FindByID(ctx, pid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, ErrPatientNotFound
+		}
+		return nil, 0, err
+	}
+	return u.repo.FindByPatientID(ctx, pid, types, page, limit)
+}
+
+func knownType(t string) bool {
+	for _, known := range timeline.Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
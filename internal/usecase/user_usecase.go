@@ -3,11 +3,22 @@ package usecase
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
+	"v2/internal/domain/duplicate"
 	"v2/internal/domain/roles"
 	userrepo "v2/internal/repository"
+	duplicaterepo "v2/internal/repository/duplicate"
 	patientrepo "v2/internal/repository/roles"
+	patientusecase "v2/internal/usecase/roles"
 	"v2/internal/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrUserNotFound     = errors.New("user not found")
+	ErrNIKAlreadyLinked = errors.New("nik is already registered to another account")
 )
 
 type RegisterPatientInput struct {
@@ -29,7 +40,6 @@ type RegisterPatientInput struct {
 	BloodType   string
 	Height      int
 	Weight      int
-	Age         int
 	Email       string
 	Phone       string
 	Password    string
@@ -38,18 +48,30 @@ type RegisterPatientInput struct {
 
 type UserUsecase interface {
 	RegisterPatient(ctx context.Context, input RegisterPatientInput) error
-	Login(ctx context.Context, email, password string) (string, error)
+	Me(ctx context.Context, userID string) (*roles.UserProfile, error)
 }
 
 type userUsecase struct {
-	userRepo    userrepo.UserRepository
-	patientRepo patientrepo.PatientRepository
+	userRepo      userrepo.UserRepository
+	patientRepo   patientrepo.PatientRepository
+	doctorRepo    userrepo.DoctorRepository
+	paramedicRepo userrepo.ParamedicRepository
+	adminRepo     userrepo.AdminRepository
+	cashierRepo   userrepo.CashierRepository
+	duplicateRepo duplicaterepo.DuplicateRepository
+	nikPolicy     patientusecase.NIKPolicy
 }
 
-func NewUserUsecase(ur userrepo.UserRepository, pr patientrepo.PatientRepository) UserUsecase {
+func NewUserUsecase(ur userrepo.UserRepository, pr patientrepo.PatientRepository, dr userrepo.DoctorRepository, mr userrepo.ParamedicRepository, ar userrepo.AdminRepository, cr userrepo.CashierRepository, dupr duplicaterepo.DuplicateRepository, nikPolicy patientusecase.NIKPolicy) UserUsecase {
 	return &userUsecase{
-		userRepo:    ur,
-		patientRepo: pr,
+		userRepo:      ur,
+		patientRepo:   pr,
+		doctorRepo:    dr,
+		paramedicRepo: mr,
+		adminRepo:     ar,
+		cashierRepo:   cr,
+		duplicateRepo: dupr,
+		nikPolicy:     nikPolicy,
 	}
 }
 
@@ -62,26 +84,19 @@ func (uc *userUsecase) RegisterPatient(ctx context.Context, input RegisterPatien
 	if existingUser != nil {
 		return errors.New("email already exists")
 	}
-
-	// 2. Hash password
-	hashedPassword, err := utils.HashPassword(input.Password)
-	if err != nil {
-		return err
+	// Pasien walk-in dengan NIK yang sama tidak diklaim otomatis (lihat langkah 5)
+	var existing *roles.Patient
+	if input.NIK != "" {
+		existing, err = uc.patientRepo.FindByNIK(ctx, input.NIK)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if existing != nil && existing.UserID != nil {
+			return ErrNIKAlreadyLinked
+		}
 	}
 
-	// 3. Create User
-	user := &roles.User{
-		Email:    input.Email,
-		Password: hashedPassword,
-		Role:     "pasien",
-	}
-
-	_, err = uc.userRepo.Create(ctx, user)
-	if err != nil {
-		return err
-	}
-
-	// 4. Create or update Patient Profile
+	// 2. Validate patient profile against NIK
 	patient := &roles.Patient{
 		NIK:         input.NIK,
 		FullName:    input.FullName,
@@ -101,32 +116,103 @@ func (uc *userUsecase) RegisterPatient(ctx context.Context, input RegisterPatien
 		BloodType:   input.BloodType,
 		Height:      input.Height,
 		Weight:      input.Weight,
-		Age:         input.Age,
 		Email:       input.Email,
 		Phone:       input.Phone,
 		KTPImages:   input.KTPImages,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
-	_, err = uc.patientRepo.CreateOrUpdateByNIK(ctx, patient)
-	return err
-}
+	if err := patientusecase.ApplyNIK(ctx, patient, uc.nikPolicy, time.Now()); err != nil {
+		return err
+	}
 
-func (uc *userUsecase) Login(ctx context.Context, email, password string) (string, error) {
-	user, err := uc.userRepo.FindByEmail(ctx, email)
+	// 3. Hash password
+	hashedPassword, err := utils.HashPassword(input.Password)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if user == nil {
-		return "", errors.New("invalid email or password")
+
+	// 4. Create User
+	user := &roles.User{
+		Email:    input.Email,
+		Password: hashedPassword,
+		Role:     "pasien",
 	}
-	if !utils.CheckPasswordHash(password, user.Password) {
-		return "", errors.New("invalid email or password")
+
+	_, err = uc.userRepo.Create(ctx, user)
+	if err != nil {
+		return err
 	}
 
-	token, err := utils.GenerateJWT(user.ID.String(), user.Role, time.Hour*1) // 1 jam
+	// 5. Create Patient Profile linked to the new account. NIK tercetak di KTP
+	// dan sertifikat sehingga bukan bukti identitas: pasien lama dengan NIK
+	// yang sama tidak pernah dihubungkan atau ditimpa di sini. Profil baru
+	// dibuat tanpa NIK dan ditandai sebagai kandidat duplikat agar staf
+	// memverifikasi lalu menggabungkannya (pasien lama sebagai survivor).
+	patient.UserID = &user.ID
+	if existing != nil {
+		patient.NIK = ""
+	}
+	if err := uc.patientRepo.Create(ctx, patient); err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := uc.duplicateRepo.Flag(ctx, existing.ID, patient.ID, 1, []string{duplicate.ReasonNIK}); err != nil {
+			slog.ErrorContext(ctx, "failed to flag claimed nik for review", "patient_id", patient.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (uc *userUsecase) Me(ctx context.Context, userID string) (*roles.UserProfile, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
-		return "", err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	profile, err := uc.roleProfile(ctx, user)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return &roles.UserProfile{User: *user, Profile: profile}, nil
+}
+
+// roleProfile mengambil profil dari tabel sesuai role user. Profil yang tidak
+// ditemukan dikembalikan sebagai nil (bukan interface berisi pointer nil).
+func (uc *userUsecase) roleProfile(ctx context.Context, user *roles.User) (interface{}, error) {
+	switch user.Role {
+	case "pasien":
+		p, err := uc.patientRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "dokter":
+		d, err := uc.doctorRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return d, nil
+	case "paramedis":
+		p, err := uc.paramedicRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "admin":
+		a, err := uc.adminRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return a, nil
+	case "kasir":
+		c, err := uc.cashierRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
 	}
-	return token, nil
+	return nil, nil
 }
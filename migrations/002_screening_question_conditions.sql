@@ -0,0 +1,5 @@
+-- Kondisi tampil (skip-logic) untuk pertanyaan screening
+ALTER TABLE screening_questions
+    ADD COLUMN position INT NOT NULL DEFAULT 0,
+    ADD COLUMN required BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN display_conditions JSONB NOT NULL DEFAULT '[]';
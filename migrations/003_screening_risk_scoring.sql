@@ -0,0 +1,17 @@
+-- Aturan skoring risiko pendaki
+CREATE TABLE screening_risk_rules (
+    id UUID PRIMARY KEY,
+    question_id UUID NOT NULL REFERENCES screening_questions(id),
+    operator VARCHAR(16) NOT NULL,
+    value TEXT,
+    "values" TEXT[],
+    flag VARCHAR(64) NOT NULL,
+    level VARCHAR(16) NOT NULL
+);
+
+-- Hasil skoring disimpan bersama jawaban screening
+ALTER TABLE screening_answers
+    ADD COLUMN risk_level VARCHAR(16) NOT NULL DEFAULT 'low',
+    ADD COLUMN risk_flags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_screening_answers_risk_level ON screening_answers(risk_level);
@@ -0,0 +1,21 @@
+-- Tabel certificates (surat keterangan sehat pendakian)
+CREATE TABLE certificates (
+    id UUID PRIMARY KEY,
+    certificate_number VARCHAR(32) NOT NULL UNIQUE,
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    physical_examination_id UUID NOT NULL REFERENCES physical_examinations(id),
+    patient_name VARCHAR(255) NOT NULL,
+    patient_nik VARCHAR(32),
+    mr_number VARCHAR(32),
+    vitals JSONB NOT NULL,
+    decision VARCHAR(32) NOT NULL,
+    decided_by_role VARCHAR(32) NOT NULL,
+    decided_by_name VARCHAR(255),
+    notes TEXT,
+    climb_date VARCHAR(32),
+    issued_by UUID REFERENCES users(id),
+    issued_at TIMESTAMP NOT NULL,
+    valid_until TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_certificates_patient_id ON certificates(patient_id);
@@ -0,0 +1,5 @@
+-- Pencabutan sertifikat
+ALTER TABLE certificates
+    ADD COLUMN revoked_at TIMESTAMP,
+    ADD COLUMN revoked_by UUID REFERENCES users(id),
+    ADD COLUMN revoke_reason TEXT;
@@ -0,0 +1,5 @@
+-- Tekanan darah terurai dan penanda nilai tanda vital abnormal
+ALTER TABLE physical_examinations
+    ADD COLUMN systolic INT,
+    ADD COLUMN diastolic INT,
+    ADD COLUMN abnormal_flags TEXT[] NOT NULL DEFAULT '{}';
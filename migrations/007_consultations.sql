@@ -0,0 +1,25 @@
+-- Tabel consultations (konsultasi dokter)
+CREATE TABLE consultations (
+    id UUID PRIMARY KEY,
+    physical_examination_id UUID NOT NULL REFERENCES physical_examinations(id),
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    doctor_id UUID REFERENCES doctors(id),
+    status VARCHAR(32) NOT NULL,
+    reason TEXT,
+    subjective TEXT,
+    objective TEXT,
+    assessment TEXT,
+    plan TEXT,
+    diagnosis TEXT,
+    fitness_decision VARCHAR(32),
+    referral_note TEXT,
+    requested_by UUID REFERENCES users(id),
+    accepted_at TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_consultations_doctor_status ON consultations(doctor_id, status);
+CREATE INDEX idx_consultations_examination ON consultations(physical_examination_id);
@@ -0,0 +1,23 @@
+-- Referensi ICD-10
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE icd10_codes (
+    code VARCHAR(16) PRIMARY KEY,
+    name_en TEXT NOT NULL,
+    name_id TEXT NOT NULL
+);
+
+CREATE INDEX idx_icd10_name_en_trgm ON icd10_codes USING gin (name_en gin_trgm_ops);
+CREATE INDEX idx_icd10_name_id_trgm ON icd10_codes USING gin (name_id gin_trgm_ops);
+
+-- Diagnosis terkode pada konsultasi dokter (satu primer, beberapa sekunder)
+CREATE TABLE consultation_diagnoses (
+    consultation_id UUID NOT NULL REFERENCES consultations(id),
+    code VARCHAR(16) NOT NULL REFERENCES icd10_codes(code),
+    is_primary BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (consultation_id, code)
+);
+
+CREATE UNIQUE INDEX idx_consultation_diagnoses_primary ON consultation_diagnoses(consultation_id) WHERE is_primary;
+CREATE INDEX idx_consultation_diagnoses_code ON consultation_diagnoses(code);
@@ -0,0 +1,6 @@
+-- Index pendukung timeline pasien
+CREATE INDEX IF NOT EXISTS idx_screening_answers_nik ON screening_answers ((patient_info->>'nik'));
+CREATE INDEX IF NOT EXISTS idx_screening_queues_nik ON screening_queues ((patient_info->>'nik'));
+CREATE INDEX IF NOT EXISTS idx_physical_examinations_patient_id ON physical_examinations(patient_id);
+CREATE INDEX IF NOT EXISTS idx_medical_records_patient_id ON medical_records(patient_id);
+CREATE INDEX IF NOT EXISTS idx_consultations_patient_id ON consultations(patient_id);
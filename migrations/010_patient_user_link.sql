@@ -0,0 +1,14 @@
+-- Hubungkan akun pasien yang sudah ada ke profil pasien berdasarkan email.
+-- Hanya email yang cocok dengan tepat satu profil yang dihubungkan; sisanya
+-- perlu ditinjau manual.
+UPDATE patients p
+SET user_id = u.id
+FROM users u
+WHERE p.user_id IS NULL
+  AND u.role = 'pasien'
+  AND lower(p.email) = lower(u.email)
+  AND NOT EXISTS (SELECT 1 FROM patients x WHERE x.user_id = u.id)
+  AND (SELECT COUNT(*) FROM patients y WHERE lower(y.email) = lower(u.email)) = 1;
+
+-- Satu akun hanya untuk satu profil pasien
+CREATE UNIQUE INDEX IF NOT EXISTS idx_patients_user_id ON patients(user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,37 @@
+-- Deteksi duplikat & merge pasien
+ALTER TABLE patients ADD COLUMN merged_into UUID REFERENCES patients(id);
+
+CREATE INDEX IF NOT EXISTS idx_patients_full_name_trgm ON patients USING gin (full_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_phone ON patients(phone);
+CREATE INDEX IF NOT EXISTS idx_patients_email ON patients(lower(email));
+
+-- Pasangan kandidat duplikat untuk ditinjau admin. patient_id selalu < candidate_id.
+CREATE TABLE patient_duplicate_candidates (
+    id UUID PRIMARY KEY,
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    candidate_id UUID NOT NULL REFERENCES patients(id),
+    score REAL NOT NULL,
+    reasons TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, merged, dismissed
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (patient_id, candidate_id),
+    CHECK (patient_id < candidate_id)
+);
+
+CREATE INDEX idx_patient_duplicate_candidates_status ON patient_duplicate_candidates(status, score DESC);
+
+-- Jejak audit merge: snapshot pasien yang digabung dan jumlah data yang dipindahkan
+CREATE TABLE patient_merges (
+    id UUID PRIMARY KEY,
+    survivor_id UUID NOT NULL REFERENCES patients(id),
+    merged_id UUID NOT NULL REFERENCES patients(id),
+    candidate_id UUID REFERENCES patient_duplicate_candidates(id),
+    merged_snapshot JSONB NOT NULL,
+    moved JSONB NOT NULL,
+    merged_by UUID REFERENCES users(id),
+    merged_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_patient_merges_survivor ON patient_merges(survivor_id);
@@ -0,0 +1,9 @@
+-- Index pencarian pasien (pg_trgm sudah diaktifkan di 008)
+CREATE INDEX IF NOT EXISTS idx_patients_nik_trgm ON patients USING gin (nik gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_email_trgm ON patients USING gin (lower(email) gin_trgm_ops);
+-- Index trigram NIK/email/telepon di bawah dihapus lagi di 015 (kolomnya terenkripsi, pencarian memakai blind index)
+CREATE INDEX IF NOT EXISTS idx_patients_phone_digits_trgm ON patients USING gin (regexp_replace(COALESCE(phone, ''), '\D', '', 'g') gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_patients_created_at ON patients(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_patients_district_village ON patients(district, village);
+-- Diganti di 023 dengan index upper(mr_number) agar bisa dipakai pencarian prefix tanpa beda huruf besar/kecil
+CREATE INDEX IF NOT EXISTS idx_medical_records_mr_number ON medical_records(mr_number text_pattern_ops);
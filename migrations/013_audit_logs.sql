@@ -0,0 +1,34 @@
+-- Audit akses dan perubahan data pasien. Tabel hanya boleh ditambah (append-only).
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY,
+    actor_id UUID, -- tanpa FK agar log tetap utuh walau akun dihapus
+    actor_role VARCHAR(32) NOT NULL DEFAULT '',
+    action VARCHAR(32) NOT NULL, -- read, create, update, delete, merge
+    entity VARCHAR(64) NOT NULL,
+    entity_id TEXT NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    method VARCHAR(16) NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at DESC);
+CREATE INDEX idx_audit_logs_entity ON audit_logs(entity, entity_id, created_at DESC);
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_no_update
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TRIGGER trg_audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
@@ -0,0 +1,9 @@
+-- Soft delete: baris tidak pernah dihapus fisik agar riwayat klinis tetap utuh
+ALTER TABLE patients ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by UUID REFERENCES users(id);
+ALTER TABLE screening_questions ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by UUID REFERENCES users(id);
+ALTER TABLE medicines ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by UUID REFERENCES users(id);
+ALTER TABLE physical_examinations ADD COLUMN deleted_at TIMESTAMP, ADD COLUMN deleted_by UUID REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_patients_active ON patients(created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_physical_examinations_patient_active ON physical_examinations(patient_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_medicines_active ON medicines(created_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,27 @@
+-- Enkripsi NIK, telepon, email dan alamat pasien serta patient_info screening.
+-- Ciphertext lebih panjang dari batas kolom lama. Setelah migrasi jalankan
+-- cmd/reencrypt untuk mengenkripsi data lama dan mengisi blind index.
+ALTER TABLE patients
+    ALTER COLUMN nik TYPE TEXT,
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ADD COLUMN IF NOT EXISTS nik_bidx VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS phone_bidx VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS email_bidx VARCHAR(64);
+
+-- Upsert pasien memakai ON CONFLICT (nik_bidx)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_patients_nik_bidx ON patients(nik_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_phone_bidx ON patients(phone_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_email_bidx ON patients(email_bidx);
+
+ALTER TABLE screening_answers ADD COLUMN IF NOT EXISTS nik_bidx VARCHAR(64);
+ALTER TABLE screening_queues ADD COLUMN IF NOT EXISTS nik_bidx VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_screening_answers_nik_bidx ON screening_answers(nik_bidx);
+CREATE INDEX IF NOT EXISTS idx_screening_queues_nik_bidx ON screening_queues(nik_bidx);
+
+-- Index atas nilai plaintext tidak berguna untuk ciphertext
+DROP INDEX IF EXISTS idx_patients_nik_trgm;
+DROP INDEX IF EXISTS idx_patients_email_trgm;
+DROP INDEX IF EXISTS idx_patients_phone_digits_trgm;
+DROP INDEX IF EXISTS idx_screening_answers_nik;
+DROP INDEX IF EXISTS idx_screening_queues_nik;
@@ -0,0 +1,21 @@
+-- Permintaan ekspor dan penghapusan data pasien (UU PDP)
+CREATE TABLE IF NOT EXISTS privacy_requests (
+    id UUID PRIMARY KEY,
+    patient_id UUID NOT NULL REFERENCES patients(id),
+    type VARCHAR(16) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    reason TEXT,
+    requested_by UUID REFERENCES users(id),
+    file_path TEXT,
+    report JSONB,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_privacy_requests_patient ON privacy_requests(patient_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_privacy_requests_status ON privacy_requests(status);
+
+-- Pasien yang sudah dianonimkan tidak bisa dipulihkan
+ALTER TABLE patients
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS erased_by UUID REFERENCES users(id);
@@ -0,0 +1,28 @@
+-- Riwayat percobaan login, termasuk yang gagal dan yang ditolak limiter
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id), -- NULL jika email tidak terdaftar
+    email TEXT NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_events_user ON login_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_events_ip ON login_events(ip, created_at DESC);
+
+-- Penguncian akun bertahap setelah login gagal berturut-turut
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS failed_logins INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+-- Hitungan limiter bersama untuk semua instance (LOGIN_LIMITER=postgres)
+CREATE TABLE IF NOT EXISTS rate_limits (
+    scope VARCHAR(32) NOT NULL,
+    key TEXT NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    hits INT NOT NULL,
+    PRIMARY KEY (scope, key)
+);
@@ -0,0 +1,13 @@
+-- Satu sertifikat aktif (belum dicabut) per pemeriksaan fisik. Sertifikat
+-- ganda yang sudah terlanjur terbit dicabut, kecuali yang terbaru.
+UPDATE certificates c
+SET revoked_at = NOW(), revoke_reason = 'sertifikat ganda untuk pemeriksaan yang sama'
+WHERE c.revoked_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM certificates n
+      WHERE n.physical_examination_id = c.physical_examination_id
+        AND n.revoked_at IS NULL
+        AND (n.issued_at, n.id) > (c.issued_at, c.id)
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_certificates_active_exam ON certificates(physical_examination_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,16 @@
+-- Nama dan NIK yang disalin ke sertifikat ikut dienkripsi; ciphertext lebih
+-- panjang dari batas kolom lama.
+ALTER TABLE certificates
+    ALTER COLUMN patient_name TYPE TEXT,
+    ALTER COLUMN patient_nik TYPE TEXT;
+
+-- Blind index 4 digit terakhir NIK dan telepon untuk pencarian sebagian
+ALTER TABLE patients
+    ADD COLUMN IF NOT EXISTS nik_last4_bidx VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS phone_last4_bidx VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_patients_nik_last4_bidx ON patients(nik_last4_bidx);
+CREATE INDEX IF NOT EXISTS idx_patients_phone_last4_bidx ON patients(phone_last4_bidx);
+
+-- Enkripsi data lama dan blind index butuh kunci aplikasi sehingga tidak bisa
+-- dilakukan di SQL. Jalankan cmd/reencrypt setelah migrasi ini; server
+-- menolak start dan /readyz gagal selama masih ada baris yang belum diproses.
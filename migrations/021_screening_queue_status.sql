@@ -0,0 +1,8 @@
+-- Status antrean screening diseragamkan menjadi waiting, in_progress, done
+-- (lihat screening.QueueWaiting dkk.). Status lain yang pernah dikirim client,
+-- misalnya screening_pending, dianggap masih menunggu.
+UPDATE screening_queues SET status = 'waiting' WHERE status NOT IN ('waiting', 'in_progress', 'done');
+UPDATE screening_queues SET created_at = COALESCE(updated_at, NOW()) WHERE created_at IS NULL;
+
+ALTER TABLE screening_queues
+    ADD CONSTRAINT screening_queues_status_check CHECK (status IN ('waiting', 'in_progress', 'done'));
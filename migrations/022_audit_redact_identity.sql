@@ -0,0 +1,33 @@
+-- Audit log sebelumnya hanya menyamarkan NIK, telepon, email, alamat dan
+-- patient_info. Field identitas lain (nama, tempat/tanggal lahir, dst.) pada
+-- before/after disamarkan dengan daftar yang sama seperti sensitiveKeys di
+-- usecase audit. Ini satu-satunya UPDATE yang diizinkan pada audit_logs.
+CREATE OR REPLACE FUNCTION audit_redact_identity(j jsonb, keys text[]) RETURNS jsonb AS $$
+BEGIN
+    IF jsonb_typeof(j) = 'object' THEN
+        RETURN (SELECT COALESCE(jsonb_object_agg(k,
+                CASE WHEN k = ANY(keys) AND v NOT IN ('null'::jsonb, '""'::jsonb) THEN '"[redacted]"'::jsonb
+                     ELSE audit_redact_identity(v, keys) END), '{}'::jsonb)
+            FROM jsonb_each(j) AS e(k, v));
+    ELSIF jsonb_typeof(j) = 'array' THEN
+        RETURN (SELECT COALESCE(jsonb_agg(audit_redact_identity(v, keys) ORDER BY i), '[]'::jsonb)
+            FROM jsonb_array_elements(j) WITH ORDINALITY AS a(v, i));
+    END IF;
+    RETURN j;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+ALTER TABLE audit_logs DISABLE TRIGGER trg_audit_logs_no_update;
+
+UPDATE audit_logs SET
+    before = audit_redact_identity(before, ARRAY['nik', 'patient_nik', 'phone', 'email', 'address', 'patient_info',
+        'full_name', 'patient_name', 'birth_place', 'birth_date', 'rt', 'rw', 'village', 'religion', 'marital', 'job',
+        'nationality', 'valid_until', 'ktp_images']),
+    after = audit_redact_identity(after, ARRAY['nik', 'patient_nik', 'phone', 'email', 'address', 'patient_info',
+        'full_name', 'patient_name', 'birth_place', 'birth_date', 'rt', 'rw', 'village', 'religion', 'marital', 'job',
+        'nationality', 'valid_until', 'ktp_images'])
+WHERE before IS NOT NULL OR after IS NOT NULL;
+
+ALTER TABLE audit_logs ENABLE TRIGGER trg_audit_logs_no_update;
+
+DROP FUNCTION audit_redact_identity(jsonb, text[]);
@@ -0,0 +1,5 @@
+-- Index text_pattern_ops pada mr_number tidak bisa dipakai ILIKE, sehingga
+-- pencarian prefix nomor RM selalu scan seluruh tabel. Pencarian pasien kini
+-- memakai upper(mr_number) LIKE upper($2) || '%'.
+DROP INDEX IF EXISTS idx_medical_records_mr_number;
+CREATE INDEX IF NOT EXISTS idx_medical_records_mr_number_upper ON medical_records(upper(mr_number) text_pattern_ops);
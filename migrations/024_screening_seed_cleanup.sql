@@ -0,0 +1,15 @@
+-- Seeder lama memakai ID acak sehingga setiap kali dijalankan aturan risiko
+-- bawaan tercatat ganda. Sisakan satu aturan untuk setiap kombinasi yang sama.
+DELETE FROM screening_risk_rules r
+USING screening_risk_rules d
+WHERE r.question_id = d.question_id
+  AND r.operator = d.operator
+  AND r.value IS NOT DISTINCT FROM d.value
+  AND r."values" IS NOT DISTINCT FROM d."values"
+  AND r.flag = d.flag
+  AND r.level = d.level
+  AND r.id > d.id;
+
+-- Aturan pendaki_pemula memakai lt, jadi jumlah pendakian harus berupa angka
+UPDATE screening_questions SET type = 'number'
+WHERE label = 'Jumlah Pendakian Sebelumnya (di atas 2.000 mdpl)' AND type = 'text';
@@ -0,0 +1,30 @@
+-- Pemeriksaan lama dengan konsultasi_dokter=true dibuat sebelum tabel
+-- consultations ada, sehingga tidak muncul di antrean maupun riwayat
+-- konsultasi dokter. Buat baris consultations dari data pemeriksaan:
+-- status dipetakan dari konsultasi_dokter_status (nilai tak dikenal dianggap
+-- masih requested), dokter dari doctor_id dan saran dokter menjadi plan.
+-- ID diturunkan dari ID pemeriksaan agar migrasi aman dijalankan ulang.
+INSERT INTO consultations (id, physical_examination_id, patient_id, doctor_id, status, reason, plan, finished_at, created_at, updated_at)
+SELECT md5('consultation:' || e.id::text)::uuid, e.id, e.patient_id, e.doctor_id, s.status, e.reason, e.doctor_advice,
+    CASE WHEN s.status IN ('completed', 'referred') THEN COALESCE(e.updated_at, e.created_at, NOW()) END,
+    COALESCE(e.created_at, NOW()), COALESCE(e.updated_at, e.created_at, NOW())
+FROM physical_examinations e
+CROSS JOIN LATERAL (SELECT CASE lower(trim(COALESCE(e.konsultasi_dokter_status, '')))
+    WHEN 'accepted' THEN 'accepted'
+    WHEN 'in_session' THEN 'in_session'
+    WHEN 'in_progress' THEN 'in_session'
+    WHEN 'completed' THEN 'completed'
+    WHEN 'done' THEN 'completed'
+    WHEN 'selesai' THEN 'completed'
+    WHEN 'referred' THEN 'referred'
+    WHEN 'dirujuk' THEN 'referred'
+    ELSE 'requested' END AS status) s
+WHERE e.konsultasi_dokter = true
+  AND e.patient_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM consultations c WHERE c.physical_examination_id = e.id)
+ON CONFLICT (id) DO NOTHING;
+
+-- Samakan status di pemeriksaan dengan status konsultasi yang baru dibuat
+UPDATE physical_examinations e SET konsultasi_dokter_status = c.status
+FROM consultations c
+WHERE c.id = md5('consultation:' || e.id::text)::uuid AND e.konsultasi_dokter_status IS DISTINCT FROM c.status;
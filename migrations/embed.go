@@ -0,0 +1,8 @@
+// Package migrations menyertakan file SQL ke dalam binary agar server bisa
+// mengecek migrasi yang belum diterapkan tanpa membaca folder ini saat runtime.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS